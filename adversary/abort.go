@@ -0,0 +1,35 @@
+package adversary
+
+import (
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+)
+
+// region Abortable ////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// Abortable is implemented by every adversary node type that can call its attack off mid-run and
+// fall back to honest behavior, modeling an opportunistic attacker that stops once its goal is
+// reached or the attack turns pointless. The per-group conditions triggering the abort are
+// configured with AdversaryAbortConditions.
+type Abortable interface {
+	AbortAttack()
+}
+
+// abortOpinionManager reverts an adversary opinion manager to the honest majority-following vote;
+// honest opinion managers are left untouched.
+func abortOpinionManager(node *multiverse.Node) {
+	if manager, isAbortable := node.Tangle().OpinionManager.(interface{ Abort() }); isAbortable {
+		manager.Abort()
+	}
+}
+
+// honestTipSelection restores the configured tip selection algorithm and removes all tip set
+// manipulations of the aborting node.
+func honestTipSelection(node *multiverse.Node) {
+	tipManager := node.Tangle().TipManager
+	tipManager.SetTipSelector(multiverse.TipSelectorFromString(config.TSA))
+	tipManager.SetTipFilter(nil)
+	tipManager.SetTipColorChooser(nil)
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,46 @@
+package adversary
+
+import (
+	"time"
+
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region AdaptiveNode /////////////////////////////////////////////////////////////////////////////////////////////////
+
+// TangleObservation is the network state an adaptive adversary node gets to see on every adaptation tick:
+// the approval weights accumulated in its own tangle, its current opinion and the simulated time
+// elapsed since the run started. The attacker only ever sees its own tangle, not a global oracle,
+// unless AdversaryOmniscient swaps the weights for the global ones.
+type TangleObservation struct {
+	ApprovalWeights map[multiverse.Color]uint64
+	Opinion         multiverse.Color
+	Elapsed         time.Duration
+}
+
+// AdaptiveNode is implemented by adversary nodes that close the loop on the observable network
+// state: Adapt is called periodically with a fresh TangleObservation and returns the issuance speedup the
+// node wants to use until the next tick, relative to its configured one. Returning 0 keeps the
+// current speedup.
+type AdaptiveNode interface {
+	Adapt(observation TangleObservation) (speedupFactor float64)
+}
+
+// OmniscientApprovalWeights merges the freshest approval weight any node in the network has seen into
+// one oracle view, per color the largest weight accumulated in any tangle. No real attacker has this
+// view; with AdversaryOmniscient set it replaces the per-node weights in the observations handed to
+// the decision logic, as the upper baseline to compare realistic partial-view attackers against.
+func OmniscientApprovalWeights(net *network.Network) map[multiverse.Color]uint64 {
+	weights := make(map[multiverse.Color]uint64)
+	for _, peer := range net.Peers {
+		for color, approvalWeight := range peer.Node.(multiverse.NodeInterface).Tangle().OpinionManager.ApprovalWeights() {
+			if approvalWeight > weights[color] {
+				weights[color] = approvalWeight
+			}
+		}
+	}
+	return weights
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
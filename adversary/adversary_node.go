@@ -22,6 +22,26 @@ func CastAdversary(node network.Node) NodeInterface {
 		return node.(*SameOpinionNode)
 	case *NoGossipNode:
 		return node.(*NoGossipNode)
+	case *BalancingNode:
+		return node.(*BalancingNode)
+	case *CensorshipNode:
+		return node.(*CensorshipNode)
+	case *WithholdingNode:
+		return node.(*WithholdingNode)
+	case *OrphanageNode:
+		return node.(*OrphanageNode)
+	case *NothingAtStakeNode:
+		return node.(*NothingAtStakeNode)
+	case *TargetingNode:
+		return node.(*TargetingNode)
+	case *ScriptedNode:
+		return node.(*ScriptedNode)
+	case *TimestampForgerNode:
+		return node.(*TimestampForgerNode)
+	case *ParasiteNode:
+		return node.(*ParasiteNode)
+	case *CompositeNode:
+		return node.(*CompositeNode)
 	}
 	return nil
 }
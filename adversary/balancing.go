@@ -0,0 +1,157 @@
+package adversary
+
+import (
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region BalancingNode ///////////////////////////////////////////////////////////////////////////////////////////////////
+
+func init() {
+	RegisterStrategy(network.Balancing, "Balancing", network.NodeClosure(NewBalancingNode))
+}
+
+// BalancingNode keeps two conflicting colors in near-equal balance: it watches the approval weights in
+// its local tangle and moves its vote to the trailing branch whenever its own weight fits into the
+// gap, the classic metastability attack.
+type BalancingNode struct {
+	*multiverse.Node
+}
+
+func NewBalancingNode() interface{} {
+	node := multiverse.NewNode().(*multiverse.Node)
+	balancingNode := &BalancingNode{
+		node,
+	}
+	balancingNode.setupOpinionManager()
+	return balancingNode
+}
+
+func (b *BalancingNode) setupOpinionManager() {
+	om := b.Tangle().OpinionManager
+	b.Tangle().OpinionManager = NewBalancingOpinionManager(om)
+	b.Tangle().OpinionManager.Setup()
+}
+
+func (b *BalancingNode) AssignColor(color multiverse.Color) {
+	b.Tangle().OpinionManager.SetOpinion(color)
+}
+
+// AbortAttack implements Abortable: the node stops dragging the trailing branch and votes for the
+// majority like an honest node from now on.
+func (b *BalancingNode) AbortAttack() {
+	abortOpinionManager(b.Node)
+}
+
+// Adapt implements AdaptiveNode: the further the two strongest branches drift apart, the faster the
+// node issues to drag the trailing one back, up to ten times its configured rate.
+func (b *BalancingNode) Adapt(observation TangleObservation) (speedupFactor float64) {
+	if manager, isBalancing := b.Tangle().OpinionManager.(*BalancingOpinionManager); isBalancing && manager.aborted {
+		return 1
+	}
+	return balancingSpeedup(observation)
+}
+
+// balancingSpeedup maps the gap between the two strongest branches to an issuance speedup factor.
+func balancingSpeedup(observation TangleObservation) (speedupFactor float64) {
+	var leader, trailer uint64
+	for _, approvalWeight := range observation.ApprovalWeights {
+		if approvalWeight > leader {
+			trailer = leader
+			leader = approvalWeight
+		} else if approvalWeight > trailer {
+			trailer = approvalWeight
+		}
+	}
+	if leader == 0 {
+		return 0
+	}
+
+	gap := float64(leader-trailer) / float64(leader)
+	return 1 + 9*gap
+}
+
+type BalancingOpinionManager struct {
+	*multiverse.OpinionManager
+
+	aborted bool
+}
+
+func NewBalancingOpinionManager(om multiverse.OpinionManagerInterface) *BalancingOpinionManager {
+	return &BalancingOpinionManager{
+		OpinionManager: om.(*multiverse.OpinionManager),
+	}
+}
+
+func (bm *BalancingOpinionManager) FormOpinion(messageID multiverse.MessageID) {
+	defer bm.Events().OpinionFormed.Trigger(messageID)
+
+	if updated := bm.UpdateWeights(messageID); !updated {
+		return
+	}
+
+	bm.weightsUpdated()
+}
+
+// Abort falls back to the honest majority-following vote and immediately re-forms the opinion, so
+// the node joins the leading branch without waiting for the next weight update.
+func (bm *BalancingOpinionManager) Abort() {
+	bm.aborted = true
+	bm.OpinionManager.WeightsUpdated()
+}
+
+func (bm *BalancingOpinionManager) weightsUpdated() {
+	if bm.aborted {
+		bm.OpinionManager.WeightsUpdated()
+		return
+	}
+
+	aw := make(map[multiverse.Color]uint64)
+	for key, value := range bm.ApprovalWeights() {
+		aw[key] = value
+	}
+
+	leader := bm.getMaxOpinion(aw)
+	delete(aw, leader)
+	trailer := bm.getMaxOpinion(aw)
+
+	oldOpinion := bm.Opinion()
+	newOpinion := oldOpinion
+	switch {
+	case trailer == multiverse.UndefinedColor:
+		// only one branch is known yet, follow it until there is something to balance
+		newOpinion = leader
+	case oldOpinion != trailer:
+		// moving the own weight to the trailing branch only narrows the gap while the weight fits into
+		// it, otherwise the move would just overshoot in the other direction
+		ownWeight := bm.Tangle().WeightDistribution.Weight(bm.Tangle().Peer.ID)
+		if ownWeight < bm.ApprovalWeights()[leader]-bm.ApprovalWeights()[trailer] {
+			newOpinion = trailer
+		}
+	}
+
+	if newOpinion != oldOpinion && newOpinion != multiverse.UndefinedColor {
+		bm.SetOpinion(newOpinion)
+	}
+	bm.UpdateConfirmation(oldOpinion, newOpinion)
+}
+
+func (bm *BalancingOpinionManager) getMaxOpinion(aw map[multiverse.Color]uint64) multiverse.Color {
+	maxApprovalWeight := uint64(0)
+	maxOpinion := multiverse.UndefinedColor
+	for color, approvalWeight := range aw {
+		if approvalWeight > maxApprovalWeight || approvalWeight == maxApprovalWeight && color < maxOpinion || maxOpinion == multiverse.UndefinedColor {
+			maxApprovalWeight = approvalWeight
+			maxOpinion = color
+		}
+	}
+	return maxOpinion
+}
+
+func (bm *BalancingOpinionManager) Setup() {
+	bm.Tangle().Booker.Events.MessageBooked.Detach(events.NewClosure(bm.OpinionManager.FormOpinion))
+	bm.Tangle().Booker.Events.MessageBooked.Attach(events.NewClosure(bm.FormOpinion))
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,72 @@
+package adversary
+
+import (
+	"sync"
+
+	"github.com/iotaledger/multivers-simulation/config"
+)
+
+// region Action budget ////////////////////////////////////////////////////////////////////////////////////////////////
+
+// The action budget is a cost model for comparing the cost-effectiveness of attacks: every adversary
+// action beyond honest behavior - an extra message, a delayed release of withheld messages, an extra
+// connection on top of the topology - consumes budget at its configured cost. The accounting does not
+// throttle the attacks, it only measures them; the usage over time is dumped to ab-*.csv.
+var (
+	budgetMutex      sync.Mutex
+	spentMessages    float64
+	spentReleases    float64
+	spentConnections float64
+	budgetExhausted  bool
+)
+
+// ChargeMessages charges the budget for count extra messages the adversary issued beyond its regular
+// rate, such as spam bursts and replayed stale messages.
+func ChargeMessages(count int) {
+	charge(&spentMessages, count, config.AdversaryCostMessage)
+}
+
+// ChargeReleases charges the budget for count withheld messages released late, such as a released
+// side tangle or an attached parasitic chain segment.
+func ChargeReleases(count int) {
+	charge(&spentReleases, count, config.AdversaryCostRelease)
+}
+
+// ChargeConnections charges the budget for count connections the adversary peering options created on
+// top of the links the topology gave the adversary nodes.
+func ChargeConnections(count int) {
+	charge(&spentConnections, count, config.AdversaryCostConnection)
+}
+
+func charge(spent *float64, count int, cost float64) {
+	if config.AdversaryBudget <= 0 || count <= 0 {
+		return
+	}
+
+	budgetMutex.Lock()
+	defer budgetMutex.Unlock()
+	*spent += float64(count) * cost
+	if !budgetExhausted && spentMessages+spentReleases+spentConnections > config.AdversaryBudget {
+		budgetExhausted = true
+		log.Warnf("Adversary action budget of %.1f exhausted, further actions run at a negative balance", config.AdversaryBudget)
+	}
+}
+
+// BudgetSpent returns the budget consumed per action kind and in total.
+func BudgetSpent() (messages, releases, connections, total float64) {
+	budgetMutex.Lock()
+	defer budgetMutex.Unlock()
+	return spentMessages, spentReleases, spentConnections, spentMessages + spentReleases + spentConnections
+}
+
+// ResetBudget clears the consumed budget between the runs.
+func ResetBudget() {
+	budgetMutex.Lock()
+	defer budgetMutex.Unlock()
+	spentMessages = 0
+	spentReleases = 0
+	spentConnections = 0
+	budgetExhausted = false
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,56 @@
+package adversary
+
+import (
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region CensorshipNode ///////////////////////////////////////////////////////////////////////////////////////////////////
+
+func init() {
+	RegisterStrategy(network.Censorship, "Censorship", network.NodeClosure(NewCensorshipNode))
+}
+
+// CensorshipNode refuses to select messages of the configured victim issuers as parents and never
+// forwards or serves them, trying to orphan everything the victims issue. The success of the attack
+// is measured by the per-issuer orphanage dumped to cs-*.csv.
+type CensorshipNode struct {
+	*multiverse.Node
+}
+
+func NewCensorshipNode() interface{} {
+	node := multiverse.NewNode().(*multiverse.Node)
+	censorshipNode := &CensorshipNode{
+		node,
+	}
+
+	notCensored := censoredIssuersFilter()
+	node.SetGossipFilter(notCensored)
+	node.Tangle().TipManager.SetTipFilter(notCensored)
+
+	return censorshipNode
+}
+
+// censoredIssuersFilter builds the filter rejecting every message of the configured victim issuers.
+func censoredIssuersFilter() func(message *multiverse.Message) bool {
+	censored := make(map[network.PeerID]bool)
+	for _, nodeID := range config.AdversaryCensoredIssuers {
+		censored[network.PeerID(nodeID)] = true
+	}
+	return func(message *multiverse.Message) bool {
+		return !censored[message.Issuer]
+	}
+}
+
+func (c *CensorshipNode) AssignColor(color multiverse.Color) {
+	c.Tangle().OpinionManager.SetOpinion(color)
+}
+
+// AbortAttack implements Abortable: the node forwards and approves the victims' messages again.
+func (c *CensorshipNode) AbortAttack() {
+	c.SetGossipFilter(nil)
+	c.Tangle().TipManager.SetTipFilter(nil)
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,214 @@
+package adversary
+
+import (
+	"sync"
+
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region CompositeNode ///////////////////////////////////////////////////////////////////////////////////////////////////
+
+// CompositeNode layers several adversary behaviors over a single node instead of being exactly one
+// of the dedicated node types. Opinion strategies (shifting, same, balancing) replace the opinion
+// manager and therefore cannot be stacked - the first one wins; the gossip-level behaviors
+// (no-gossip, censorship, withholding) chain their filters, so a message is only gossiped when every
+// layer lets it through.
+type CompositeNode struct {
+	*multiverse.Node
+
+	adapt           func(observation TangleObservation) (speedupFactor float64)
+	gossipFilters   []func(message *multiverse.Message) bool
+	opinionLayered  bool
+	suppressColor   bool
+	suppressPayload bool
+
+	withholding      bool
+	withheldMessages []*multiverse.Message
+	withholdingMutex sync.Mutex
+	released         bool
+
+	forgeTimestamps bool
+}
+
+// NewCompositeNode returns the factory building nodes that combine all the given strategies, applied
+// in the given order.
+func NewCompositeNode(composition []network.AdversaryType) network.NodeFactory {
+	return func() network.Node {
+		node := multiverse.NewNode().(*multiverse.Node)
+		compositeNode := &CompositeNode{
+			Node: node,
+		}
+		for _, adversaryType := range composition {
+			compositeNode.layer(adversaryType)
+		}
+		if len(compositeNode.gossipFilters) > 0 {
+			node.SetGossipFilter(compositeNode.filterGossip)
+		}
+		return compositeNode
+	}
+}
+
+// layer installs one behavior on top of the layers applied before it.
+func (c *CompositeNode) layer(adversaryType network.AdversaryType) {
+	switch adversaryType {
+	case network.ShiftOpinion:
+		if c.claimOpinionManager(adversaryType) {
+			c.Tangle().OpinionManager = NewShiftingOpinionManager(c.Tangle().OpinionManager)
+			c.Tangle().OpinionManager.Setup()
+		}
+	case network.TheSameOpinion:
+		if c.claimOpinionManager(adversaryType) {
+			c.Tangle().OpinionManager = NewSameOpinionManager(c.Tangle().OpinionManager)
+			c.Tangle().OpinionManager.Setup()
+		}
+	case network.Balancing:
+		if c.claimOpinionManager(adversaryType) {
+			c.Tangle().OpinionManager = NewBalancingOpinionManager(c.Tangle().OpinionManager)
+			c.Tangle().OpinionManager.Setup()
+			c.adapt = balancingSpeedup
+		}
+	case network.NoGossip:
+		if selectiveGossipConfigured() {
+			c.gossipFilters = append(c.gossipFilters, newSelectiveGossipFilter(c.Node))
+			break
+		}
+		c.gossipFilters = append(c.gossipFilters, func(message *multiverse.Message) bool {
+			return false
+		})
+		c.suppressColor = true
+		c.suppressPayload = true
+	case network.Censorship:
+		notCensored := censoredIssuersFilter()
+		c.gossipFilters = append(c.gossipFilters, notCensored)
+		c.Tangle().TipManager.SetTipFilter(notCensored)
+	case network.Withholding:
+		c.withholding = true
+		c.gossipFilters = append(c.gossipFilters, c.interceptOwnMessages)
+	case network.Orphanage:
+		c.Tangle().TipManager.SetTipSelector(OldestTipSelector{})
+	case network.NothingAtStake:
+		c.Tangle().TipManager.SetTipColorChooser(newBranchCycler(c.Tangle()))
+	case network.Targeting:
+		c.SetGossipTargets(newVictimTargeter(c.Node))
+	case network.Scripted:
+		log.Warnf("The scripted strategy cannot be layered in a composition, use a dedicated group")
+	case network.TimestampForger:
+		c.forgeTimestamps = true
+	case network.Parasite:
+		log.Warnf("The parasitic chain strategy cannot be layered in a composition, use a dedicated group")
+	case network.Replay:
+		log.Warnf("The replay strategy cannot be layered in a composition, use a dedicated group")
+	case network.Stealth:
+		log.Warnf("The stealth strategy cannot be layered in a composition, it is the absence of every other behavior")
+	}
+}
+
+// claimOpinionManager reserves the opinion manager for the first opinion strategy of the
+// composition, as two opinion strategies cannot decide the same vote.
+func (c *CompositeNode) claimOpinionManager(adversaryType network.AdversaryType) (claimed bool) {
+	if c.opinionLayered {
+		log.Warnf("Composition layers a second opinion strategy %s, skipped", network.AdversaryTypeToString(adversaryType))
+		return false
+	}
+	c.opinionLayered = true
+	return true
+}
+
+// filterGossip passes a message on only when every layered filter lets it through.
+func (c *CompositeNode) filterGossip(message *multiverse.Message) bool {
+	for _, filter := range c.gossipFilters {
+		if !filter(message) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *CompositeNode) AssignColor(color multiverse.Color) {
+	if c.suppressColor {
+		return
+	}
+	c.Tangle().OpinionManager.SetOpinion(color)
+}
+
+func (c *CompositeNode) IssuePayload(payload multiverse.Color) {
+	if c.suppressPayload {
+		return
+	}
+	c.Node.IssuePayload(payload)
+}
+
+// AbortAttack implements Abortable: it removes all layered behaviors at once, releasing a pending
+// side tangle and reverting the gossip, tip selection and opinion manipulations.
+func (c *CompositeNode) AbortAttack() {
+	c.Release()
+
+	c.withholdingMutex.Lock()
+	c.gossipFilters = nil
+	c.suppressColor = false
+	c.suppressPayload = false
+	c.forgeTimestamps = false
+	c.adapt = nil
+	c.withholdingMutex.Unlock()
+
+	c.SetGossipTargets(nil)
+	honestTipSelection(c.Node)
+	abortOpinionManager(c.Node)
+}
+
+// HandleNetworkMessage skews the timestamps of the node's own issuances when the timestamp-forging
+// layer is active, mirroring TimestampForgerNode.
+func (c *CompositeNode) HandleNetworkMessage(networkMessage interface{}) {
+	if payload, isPayload := networkMessage.(multiverse.Color); isPayload && c.forgeTimestamps {
+		issueForgedMessage(c.Tangle(), payload)
+		return
+	}
+
+	c.Node.HandleNetworkMessage(networkMessage)
+}
+
+// Adapt implements AdaptiveNode when an adaptive strategy is layered, otherwise it leaves the
+// issuance rate untouched.
+func (c *CompositeNode) Adapt(observation TangleObservation) (speedupFactor float64) {
+	if c.adapt == nil {
+		return 0
+	}
+	return c.adapt(observation)
+}
+
+// interceptOwnMessages mirrors WithholdingNode.interceptOwnMessages for the withholding layer.
+func (c *CompositeNode) interceptOwnMessages(message *multiverse.Message) bool {
+	if message.Issuer != c.Peer().ID {
+		return true
+	}
+
+	c.withholdingMutex.Lock()
+	defer c.withholdingMutex.Unlock()
+	if c.released {
+		return true
+	}
+
+	c.withheldMessages = append(c.withheldMessages, message)
+	return false
+}
+
+// Release implements Withholder; without a withholding layer there is nothing to release.
+func (c *CompositeNode) Release() (released int) {
+	if !c.withholding {
+		return 0
+	}
+
+	c.withholdingMutex.Lock()
+	messages := c.withheldMessages
+	c.withheldMessages = nil
+	c.released = true
+	c.withholdingMutex.Unlock()
+
+	for _, message := range messages {
+		c.Peer().GossipNetworkMessage(message)
+	}
+	return len(messages)
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
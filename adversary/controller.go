@@ -0,0 +1,158 @@
+package adversary
+
+import (
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"time"
+
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region Controller ///////////////////////////////////////////////////////////////////////////////////////////////////
+
+// Controller exposes the adversary nodes to an external process (e.g. a reinforcement learning agent) over
+// JSON-RPC on TCP. The wire protocol is kept to plain JSON structures instead of generated protobuf code, so
+// controllers written in any language can drive the attack loop with a gym-style Step() call:
+// the external process submits the actions for the current tick and blocks until the next Observation is ready.
+type Controller struct {
+	network *network.Network
+	ticker  *time.Ticker
+}
+
+// Observation is the permitted state an external controller can see each tick: the local perception of every
+// adversary node, not the global network state - unless AdversaryOmniscient replaces the per-node approval
+// weights with the oracle view of OmniscientApprovalWeights.
+type Observation struct {
+	Tick            int64
+	NodeStates      []NodeState
+	AdversaryGroups int
+}
+
+// NodeState is the view of a single adversary node on its own tangle.
+type NodeState struct {
+	NodeID          int64
+	GroupID         int
+	Opinion         int64
+	ApprovalWeights map[int64]uint64
+}
+
+// Action is a single instruction for an adversary node issued by the external controller.
+type Action struct {
+	NodeID int64
+	// Type is one of: "assign" (set the node's opinion to Color), "issue" (issue a message with Color as
+	// payload) or "silence" (do nothing this tick).
+	Type  string
+	Color int64
+}
+
+// Actions is the set of instructions applied before the next Observation is taken.
+type Actions struct {
+	Actions []Action
+}
+
+func NewController(net *network.Network) *Controller {
+	return &Controller{
+		network: net,
+	}
+}
+
+// Listen starts serving the control interface on the configured address. It returns immediately; each
+// connected controller drives its own Step() loop.
+func (c *Controller) Listen(address string) {
+	c.ticker = time.NewTicker(time.Duration(config.SlowdownFactor*config.ConsensusMonitorTick) * time.Millisecond)
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("AdversaryControl", c); err != nil {
+		log.Fatal(err)
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Infof("Adversary controller listening on %s", address)
+
+	go func() {
+		for {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.ServeCodec(jsonrpc.NewServerCodec(connection))
+		}
+	}()
+}
+
+// Step applies the submitted actions, waits for the next tick and replies with the fresh Observation.
+// Calling it with an empty action set just observes.
+func (c *Controller) Step(actions *Actions, observation *Observation) error {
+	for _, action := range actions.Actions {
+		c.apply(action)
+	}
+
+	<-c.ticker.C
+	*observation = c.observe()
+
+	return nil
+}
+
+func (c *Controller) apply(action Action) {
+	if _, ok := network.AdversaryNodeIDToGroupIDMap[int(action.NodeID)]; !ok {
+		log.Warnf("Controller action for non-adversary node %d ignored", action.NodeID)
+		return
+	}
+	peer := c.network.Peer(int(action.NodeID))
+
+	switch action.Type {
+	case "assign":
+		if node := CastAdversary(peer.Node); node != nil {
+			node.AssignColor(multiverse.ColorFromInt(int(action.Color)))
+		}
+	case "issue":
+		peer.Node.(multiverse.NodeInterface).IssuePayload(multiverse.ColorFromInt(int(action.Color)))
+	case "silence":
+		// do nothing this tick
+	default:
+		log.Warnf("Unknown controller action type: %s", action.Type)
+	}
+}
+
+func (c *Controller) observe() (observation Observation) {
+	observation.Tick = time.Now().UnixNano()
+	observation.AdversaryGroups = len(c.network.AdversaryGroups)
+
+	var omniscientWeights map[int64]uint64
+	if config.AdversaryOmniscient {
+		omniscientWeights = make(map[int64]uint64)
+		for color, weight := range OmniscientApprovalWeights(c.network) {
+			omniscientWeights[int64(color)] = weight
+		}
+	}
+
+	for nodeID, groupID := range network.AdversaryNodeIDToGroupIDMap {
+		peer := c.network.Peer(nodeID)
+		opinionManager := peer.Node.(multiverse.NodeInterface).Tangle().OpinionManager
+
+		approvalWeights := omniscientWeights
+		if approvalWeights == nil {
+			approvalWeights = make(map[int64]uint64)
+			for color, weight := range opinionManager.ApprovalWeights() {
+				approvalWeights[int64(color)] = weight
+			}
+		}
+
+		observation.NodeStates = append(observation.NodeStates, NodeState{
+			NodeID:          int64(peer.ID),
+			GroupID:         groupID,
+			Opinion:         int64(opinionManager.Opinion()),
+			ApprovalWeights: approvalWeights,
+		})
+	}
+
+	return
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
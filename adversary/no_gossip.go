@@ -1,22 +1,41 @@
 package adversary
 
 import (
+	"github.com/iotaledger/hive.go/crypto"
 	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/multivers-simulation/config"
 	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
 )
 
 // region NoGossipNode ///////////////////////////////////////////////////////////////////////////////////////////////////
 
+func init() {
+	RegisterStrategy(network.NoGossip, "NoGossip", network.NodeClosure(NewNoGossipNode))
+}
+
+// NoGossipNode drops gossip traffic instead of relaying it. By default it drops everything and stays
+// out of the conflict entirely; with any of the AdversaryDrop* options set it turns into a selective
+// gossip node that behaves honestly except for dropping the selected messages, so partial censorship
+// scenarios can be explored.
 type NoGossipNode struct {
 	*multiverse.Node
+
+	selective bool
+	aborted   bool
 }
 
 func NewNoGossipNode() interface{} {
 	node := multiverse.NewNode().(*multiverse.Node)
 	noGossipNode := &NoGossipNode{
-		node,
+		Node:      node,
+		selective: selectiveGossipConfigured(),
+	}
+	if noGossipNode.selective {
+		node.SetGossipFilter(newSelectiveGossipFilter(node))
+	} else {
+		noGossipNode.UpdateGossipBehavior()
 	}
-	noGossipNode.UpdateGossipBehavior()
 	return noGossipNode
 }
 
@@ -29,13 +48,62 @@ func (n *NoGossipNode) UpdateGossipBehavior() {
 	}))
 }
 
+// AbortAttack implements Abortable: it lifts the selective drop filter and resumes the honest
+// handling of opinions and payloads.
+func (n *NoGossipNode) AbortAttack() {
+	n.aborted = true
+	n.SetGossipFilter(nil)
+}
+
 func (n *NoGossipNode) AssignColor(color multiverse.Color) {
+	if n.selective || n.aborted {
+		n.Tangle().OpinionManager.SetOpinion(color)
+		return
+	}
 	// do nothing - leave undefined color
 }
 
 func (n *NoGossipNode) IssuePayload(payload multiverse.Color) {
+	if n.selective || n.aborted {
+		n.Node.IssuePayload(payload)
+		return
+	}
 	// do nothing - this node will not issue DS message, to not allow other nodes count his opinion for any of colors
 	// user needs to define other adv group that will issue DS
 }
 
-// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////
+// selectiveGossipConfigured reports whether any of the AdversaryDrop* options narrows the no-gossip
+// strategy down to a subset of the traffic.
+func selectiveGossipConfigured() bool {
+	return config.AdversaryDropColor != "" || len(config.AdversaryDropIssuers) > 0 || config.AdversaryDropRate < 1
+}
+
+// newSelectiveGossipFilter builds the predicate of the selective no-gossip strategy: messages of the
+// configured color or issuers are selected, with neither configured the whole traffic is; a selected
+// message is dropped with probability AdversaryDropRate and everything else is forwarded normally.
+func newSelectiveGossipFilter(node *multiverse.Node) func(message *multiverse.Message) bool {
+	dropColor := multiverse.ColorFromStr(config.AdversaryDropColor)
+	droppedIssuers := make(map[network.PeerID]bool)
+	for _, issuerID := range config.AdversaryDropIssuers {
+		droppedIssuers[network.PeerID(issuerID)] = true
+	}
+
+	return func(message *multiverse.Message) bool {
+		selected := dropColor == multiverse.UndefinedColor && len(droppedIssuers) == 0
+		if droppedIssuers[message.Issuer] {
+			selected = true
+		}
+		if dropColor != multiverse.UndefinedColor {
+			if metadata := node.Tangle().Storage.MessageMetadata(message.ID); metadata != nil && metadata.InheritedColor() == dropColor {
+				selected = true
+			}
+		}
+		if !selected {
+			return true
+		}
+
+		return crypto.Randomness.Float64() >= config.AdversaryDropRate
+	}
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,69 @@
+package adversary
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region NothingAtStakeNode ///////////////////////////////////////////////////////////////////////////////////////////////////
+
+func init() {
+	RegisterStrategy(network.NothingAtStake, "NothingAtStake", network.NodeClosure(NewNothingAtStakeNode))
+}
+
+// NothingAtStakeNode votes for every conflicting branch at once: its messages cycle through the tip
+// sets of all known colors instead of staying in the branch of its own opinion, so each message
+// equivocates against the previous one. It exercises the opinion manager's last-write-wins handling
+// of equivocating issuers.
+type NothingAtStakeNode struct {
+	*multiverse.Node
+}
+
+func NewNothingAtStakeNode() interface{} {
+	node := multiverse.NewNode().(*multiverse.Node)
+	nothingAtStakeNode := &NothingAtStakeNode{
+		node,
+	}
+	node.Tangle().TipManager.SetTipColorChooser(newBranchCycler(node.Tangle()))
+	return nothingAtStakeNode
+}
+
+func (n *NothingAtStakeNode) AssignColor(color multiverse.Color) {
+	n.Tangle().OpinionManager.SetOpinion(color)
+}
+
+// AbortAttack implements Abortable: the node stops equivocating and attaches to the branch of its
+// own opinion again.
+func (n *NothingAtStakeNode) AbortAttack() {
+	n.Tangle().TipManager.SetTipColorChooser(nil)
+}
+
+// newBranchCycler returns the chooser cycling round-robin through all branch colors the node knows
+// of; while no conflict exists it keeps attaching to the undefined tip set like an honest node.
+func newBranchCycler(tangle *multiverse.Tangle) func() multiverse.Color {
+	var cursor int
+	var cursorMutex sync.Mutex
+
+	return func() multiverse.Color {
+		colors := make([]multiverse.Color, 0)
+		for color := range tangle.OpinionManager.ApprovalWeights() {
+			if color != multiverse.UndefinedColor {
+				colors = append(colors, color)
+			}
+		}
+		if len(colors) == 0 {
+			return multiverse.UndefinedColor
+		}
+		sort.Slice(colors, func(i, j int) bool { return colors[i] < colors[j] })
+
+		cursorMutex.Lock()
+		defer cursorMutex.Unlock()
+		cursor++
+		return colors[cursor%len(colors)]
+	}
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
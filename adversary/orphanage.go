@@ -0,0 +1,68 @@
+package adversary
+
+import (
+	"github.com/iotaledger/hive.go/datastructure/randommap"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region OrphanageNode ///////////////////////////////////////////////////////////////////////////////////////////////////
+
+func init() {
+	RegisterStrategy(network.Orphanage, "Orphanage", network.NodeClosure(NewOrphanageNode))
+}
+
+// OrphanageNode deliberately attaches its messages to the oldest tips it knows, keeping near-orphaned
+// tips alive and steering the honest nodes' new messages away from the recent ones, to maximize the
+// honest orphanage rate. The resulting orphan rate is dumped to or-*.csv.
+type OrphanageNode struct {
+	*multiverse.Node
+}
+
+func NewOrphanageNode() interface{} {
+	node := multiverse.NewNode().(*multiverse.Node)
+	orphanageNode := &OrphanageNode{
+		node,
+	}
+	node.Tangle().TipManager.SetTipSelector(OldestTipSelector{})
+	return orphanageNode
+}
+
+func (o *OrphanageNode) AssignColor(color multiverse.Color) {
+	o.Tangle().OpinionManager.SetOpinion(color)
+}
+
+// AbortAttack implements Abortable: the node selects its parents with the configured tip selection
+// algorithm again.
+func (o *OrphanageNode) AbortAttack() {
+	honestTipSelection(o.Node)
+}
+
+// OldestTipSelector returns the maxAmount oldest tips instead of uniformly random ones.
+type OldestTipSelector struct{}
+
+func (OldestTipSelector) TipSelect(tips *randommap.RandomMap, maxAmount int) []interface{} {
+	var oldest []interface{}
+	tips.ForEach(func(key interface{}, value interface{}) {
+		message := value.(*multiverse.Message)
+
+		inserted := false
+		for i, tip := range oldest {
+			if message.IssuanceTime.Before(tip.(*multiverse.Message).IssuanceTime) {
+				oldest = append(oldest[:i], append([]interface{}{message}, oldest[i:]...)...)
+				inserted = true
+				break
+			}
+		}
+		if !inserted && len(oldest) < maxAmount {
+			oldest = append(oldest, message)
+		}
+		if len(oldest) > maxAmount {
+			oldest = oldest[:maxAmount]
+		}
+	})
+
+	return oldest
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,127 @@
+package adversary
+
+import (
+	"sync"
+
+	"github.com/iotaledger/hive.go/datastructure/randommap"
+	"github.com/iotaledger/hive.go/types"
+
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region ParasiteNode /////////////////////////////////////////////////////////////////////////////////////////////////
+
+func init() {
+	RegisterStrategy(network.Parasite, "Parasite", network.NodeClosure(NewParasiteNode))
+}
+
+// Parasite is implemented by every node type that grows a private parasitic chain and periodically
+// attaches it to the main tangle.
+type Parasite interface {
+	Attach() (attached int)
+}
+
+// ParasiteNode grows a parasitic chain: its first message anchors at the oldest tip it knows and
+// every further message references only the node's own previous one. The chain stays private until
+// Attach floods the segment grown since the last attachment, luring honest tip selection onto the
+// chain tip. The honest approval weight the chain captures is dumped to pc-*.csv.
+type ParasiteNode struct {
+	*multiverse.Node
+
+	chainTip    *multiverse.Message
+	pending     []*multiverse.Message
+	pendingIDs  map[multiverse.MessageID]types.Empty
+	attachedIDs map[multiverse.MessageID]types.Empty
+	aborted     bool
+	mutex       sync.Mutex
+}
+
+func NewParasiteNode() interface{} {
+	node := multiverse.NewNode().(*multiverse.Node)
+	parasiteNode := &ParasiteNode{
+		Node:        node,
+		pendingIDs:  make(map[multiverse.MessageID]types.Empty),
+		attachedIDs: make(map[multiverse.MessageID]types.Empty),
+	}
+	node.Tangle().TipManager.SetTipSelector(&chainTipSelector{parasiteNode})
+	node.SetGossipFilter(parasiteNode.interceptOwnMessages)
+	return parasiteNode
+}
+
+func (p *ParasiteNode) AssignColor(color multiverse.Color) {
+	p.Tangle().OpinionManager.SetOpinion(color)
+}
+
+// AbortAttack implements Abortable: it attaches the remaining private chain segment, stops
+// withholding new messages and selects parents with the configured tip selection algorithm again.
+func (p *ParasiteNode) AbortAttack() {
+	p.mutex.Lock()
+	p.aborted = true
+	p.mutex.Unlock()
+
+	p.Attach()
+	honestTipSelection(p.Node)
+}
+
+// interceptOwnMessages keeps the chain private between the attachments and remembers the newest own
+// message as the tip the chain grows from; it doubles as the MessageRequest filter, so only the
+// already attached part of the chain can be served to requesting neighbors.
+func (p *ParasiteNode) interceptOwnMessages(message *multiverse.Message) bool {
+	if message.Issuer != p.Peer().ID {
+		return true
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.aborted {
+		return true
+	}
+	if _, isAttached := p.attachedIDs[message.ID]; isAttached {
+		return true
+	}
+	if _, isPending := p.pendingIDs[message.ID]; !isPending {
+		p.chainTip = message
+		p.pending = append(p.pending, message)
+		p.pendingIDs[message.ID] = types.Void
+	}
+	return false
+}
+
+// Attach implements Parasite: it floods the chain segment grown since the last attachment to the
+// neighbors, splicing the parasitic chain into the main tangle while the chain keeps growing
+// privately from its tip.
+func (p *ParasiteNode) Attach() (attached int) {
+	p.mutex.Lock()
+	messages := p.pending
+	p.pending = nil
+	for _, message := range messages {
+		delete(p.pendingIDs, message.ID)
+		p.attachedIDs[message.ID] = types.Void
+	}
+	p.mutex.Unlock()
+
+	for _, message := range messages {
+		p.Peer().GossipNetworkMessage(message)
+	}
+	return len(messages)
+}
+
+// chainTipSelector anchors the first message of the chain at the oldest known tip and attaches every
+// further message to the node's own previous message, ignoring the honest tip pool from then on.
+type chainTipSelector struct {
+	node *ParasiteNode
+}
+
+func (c *chainTipSelector) TipSelect(tips *randommap.RandomMap, maxAmount int) []interface{} {
+	c.node.mutex.Lock()
+	chainTip := c.node.chainTip
+	c.node.mutex.Unlock()
+
+	if chainTip == nil {
+		return OldestTipSelector{}.TipSelect(tips, maxAmount)
+	}
+	return []interface{}{chainTip}
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
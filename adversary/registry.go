@@ -0,0 +1,61 @@
+package adversary
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region Strategy registry ////////////////////////////////////////////////////////////////////////////////////////////
+
+// registeredStrategies holds the node factory of every adversary strategy under its type code; the
+// strategy files register themselves from their init functions, so an external attack implementation
+// only has to call RegisterStrategy to become selectable through AdversaryTypes.
+var registeredStrategies = make(map[network.AdversaryType]registeredStrategy)
+
+type registeredStrategy struct {
+	name    string
+	factory network.NodeFactory
+}
+
+func init() {
+	RegisterStrategy(network.HonestNode, "Honest", network.NodeClosure(multiverse.NewNode))
+}
+
+// RegisterStrategy makes the strategy with the given type code and name selectable through
+// AdversaryTypes. Type codes are unique, registering a taken one is a programming error.
+func RegisterStrategy(adversaryType network.AdversaryType, name string, factory network.NodeFactory) {
+	if taken, isTaken := registeredStrategies[adversaryType]; isTaken {
+		log.Fatalf("Adversary strategy type code %d is already registered as %s", int(adversaryType), taken.name)
+	}
+	registeredStrategies[adversaryType] = registeredStrategy{name: name, factory: factory}
+}
+
+// NodeFactories returns the node factories of all registered strategies keyed by their type code,
+// ready to be handed to network.Nodes.
+func NodeFactories() map[network.AdversaryType]network.NodeFactory {
+	factories := make(map[network.AdversaryType]network.NodeFactory, len(registeredStrategies))
+	for adversaryType, strategy := range registeredStrategies {
+		factories[adversaryType] = strategy.factory
+	}
+	return factories
+}
+
+// Strategies returns one 'code - name' line per registered strategy sorted by type code, the values
+// AdversaryTypes selects from.
+func Strategies() (strategies []string) {
+	codes := make([]int, 0, len(registeredStrategies))
+	for adversaryType := range registeredStrategies {
+		codes = append(codes, int(adversaryType))
+	}
+	sort.Ints(codes)
+
+	for _, code := range codes {
+		strategies = append(strategies, fmt.Sprintf("%2d - %s", code, registeredStrategies[network.AdversaryType(code)].name))
+	}
+	return
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,91 @@
+package adversary
+
+import (
+	"sync"
+
+	"github.com/iotaledger/hive.go/events"
+
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region ReplayNode ///////////////////////////////////////////////////////////////////////////////////////////////////
+
+func init() {
+	RegisterStrategy(network.Replay, "Replay", network.NodeClosure(NewReplayNode))
+}
+
+// Replayer is implemented by every node type that periodically re-injects stale traffic into the
+// network.
+type Replayer interface {
+	Replay() (replayed int)
+}
+
+// ReplayNode re-gossips stale messages and re-requests them from its neighbors although it already
+// holds them, inflating the duplicate and requester traffic so fresh tips propagate slower. Every
+// AdversaryReplayInterval it replays the AdversaryReplayBatch oldest not yet replayed messages,
+// cycling from the start once it catches up with the live traffic. Its requests show up as the
+// replayed cause in the request statistics of gs-*.csv.
+type ReplayNode struct {
+	*multiverse.Node
+
+	storedIDs []multiverse.MessageID
+	cursor    int
+	aborted   bool
+	mutex     sync.Mutex
+}
+
+func NewReplayNode() interface{} {
+	node := multiverse.NewNode().(*multiverse.Node)
+	replayNode := &ReplayNode{
+		Node: node,
+	}
+	node.Tangle().Storage.Events.MessageStored.Attach(events.NewClosure(func(messageID multiverse.MessageID) {
+		replayNode.mutex.Lock()
+		replayNode.storedIDs = append(replayNode.storedIDs, messageID)
+		replayNode.mutex.Unlock()
+	}))
+	return replayNode
+}
+
+func (r *ReplayNode) AssignColor(color multiverse.Color) {
+	r.Tangle().OpinionManager.SetOpinion(color)
+}
+
+// AbortAttack implements Abortable: the node stops replaying stale traffic.
+func (r *ReplayNode) AbortAttack() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.aborted = true
+}
+
+// Replay implements Replayer: it gossips the next batch of stale messages to the neighbors again and
+// requests every one of them back although it already holds them, so the neighbors burn bandwidth on
+// duplicates and served requests.
+func (r *ReplayNode) Replay() (replayed int) {
+	r.mutex.Lock()
+	batch := make([]multiverse.MessageID, 0, config.AdversaryReplayBatch)
+	for i := 0; i < config.AdversaryReplayBatch && len(r.storedIDs) > 0 && !r.aborted; i++ {
+		if r.cursor >= len(r.storedIDs) {
+			r.cursor = 0
+		}
+		batch = append(batch, r.storedIDs[r.cursor])
+		r.cursor++
+	}
+	r.mutex.Unlock()
+
+	for _, messageID := range batch {
+		message := r.Tangle().Storage.Message(messageID)
+		if message == nil {
+			continue
+		}
+		r.Peer().GossipNetworkMessage(message)
+		r.Peer().GossipNetworkMessage(&multiverse.MessageRequest{MessageID: messageID, Issuer: r.Peer().ID})
+		multiverse.CountReplayedRequest()
+		replayed++
+	}
+	return
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -3,10 +3,15 @@ package adversary
 import (
 	"github.com/iotaledger/hive.go/events"
 	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
 )
 
 // region ShiftingOpinionNode ///////////////////////////////////////////////////////////////////////////////////////////////////
 
+func init() {
+	RegisterStrategy(network.TheSameOpinion, "TheSameOpinion", network.NodeClosure(NewSameOpinionNode))
+}
+
 type SameOpinionNode struct {
 	*multiverse.Node
 }
@@ -31,13 +36,21 @@ func (s *SameOpinionNode) AssignColor(color multiverse.Color) {
 	s.Tangle().OpinionManager.SetOpinion(color)
 }
 
+// AbortAttack implements Abortable: the node votes for the majority branch like an honest node from
+// now on.
+func (s *SameOpinionNode) AbortAttack() {
+	abortOpinionManager(s.Node)
+}
+
 type SameOpinionManager struct {
 	*multiverse.OpinionManager
+
+	aborted bool
 }
 
 func NewSameOpinionManager(om multiverse.OpinionManagerInterface) *SameOpinionManager {
 	return &SameOpinionManager{
-		om.(*multiverse.OpinionManager),
+		OpinionManager: om.(*multiverse.OpinionManager),
 	}
 }
 
@@ -51,8 +64,19 @@ func (sm *SameOpinionManager) FormOpinion(messageID multiverse.MessageID) {
 	sm.weightsUpdated()
 }
 
+// Abort falls back to the honest majority-following vote and immediately re-forms the opinion, so
+// the node joins the leading branch without waiting for the next weight update.
+func (sm *SameOpinionManager) Abort() {
+	sm.aborted = true
+	sm.OpinionManager.WeightsUpdated()
+}
+
 func (sm *SameOpinionManager) weightsUpdated() {
-	// do nothing
+	if sm.aborted {
+		sm.OpinionManager.WeightsUpdated()
+		return
+	}
+	// do nothing - keep the assigned opinion
 }
 
 func (sm *SameOpinionManager) Setup() {
@@ -0,0 +1,125 @@
+package adversary
+
+import (
+	"sync"
+
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region ScriptedNode /////////////////////////////////////////////////////////////////////////////////////////////////
+
+func init() {
+	RegisterStrategy(network.Scripted, "Scripted", network.NodeClosure(NewScriptedNode))
+}
+
+// ScriptedNode runs the YAML strategy configured with AdversaryStrategyFile instead of a behavior
+// compiled into a dedicated node type. It implements AdaptiveNode, so the adaptation worker hands it
+// a fresh observation of its own tangle every tick and the rules of the strategy decide what to do
+// with it.
+type ScriptedNode struct {
+	*multiverse.Node
+
+	strategy *Strategy
+	fired    []bool
+	silenced bool
+	aborted  bool
+	mutex    sync.Mutex
+}
+
+var (
+	configuredStrategy     *Strategy
+	configuredStrategyOnce sync.Once
+)
+
+func NewScriptedNode() interface{} {
+	node := multiverse.NewNode().(*multiverse.Node)
+	strategy := loadConfiguredStrategy()
+	return &ScriptedNode{
+		Node:     node,
+		strategy: strategy,
+		fired:    make([]bool, len(strategy.Rules)),
+	}
+}
+
+// loadConfiguredStrategy loads the strategy file once and shares the read-only result between all
+// scripted nodes; only the firing state is per node.
+func loadConfiguredStrategy() *Strategy {
+	configuredStrategyOnce.Do(func() {
+		if config.AdversaryStrategyFile == "" {
+			log.Warn("Scripted adversary nodes configured without a strategy file, they will behave honestly")
+			configuredStrategy = &Strategy{}
+			return
+		}
+
+		strategy, err := LoadStrategy(config.AdversaryStrategyFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		configuredStrategy = strategy
+		log.Infof("Loaded adversary strategy with %d rules from %s", len(strategy.Rules), config.AdversaryStrategyFile)
+	})
+
+	return configuredStrategy
+}
+
+func (s *ScriptedNode) AssignColor(color multiverse.Color) {
+	s.Tangle().OpinionManager.SetOpinion(color)
+}
+
+// AbortAttack implements Abortable: the strategy rules stop firing and a pending silence is lifted.
+func (s *ScriptedNode) AbortAttack() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.aborted = true
+	s.silenced = false
+}
+
+// Adapt implements AdaptiveNode: instead of tuning the issuance rate it evaluates the strategy rules
+// against the observation and applies the actions of the matching ones. The speedup is left untouched.
+func (s *ScriptedNode) Adapt(observation TangleObservation) (speedupFactor float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.aborted {
+		return 0
+	}
+
+	for i, rule := range s.strategy.Rules {
+		if rule.Once && s.fired[i] {
+			continue
+		}
+		if !rule.When.matches(observation) {
+			continue
+		}
+		s.fired[i] = true
+
+		switch rule.Do {
+		case "issue":
+			s.Node.IssuePayload(rule.color)
+		case "assign":
+			s.Tangle().OpinionManager.SetOpinion(rule.color)
+		case "silence":
+			s.silenced = true
+		case "resume":
+			s.silenced = false
+		}
+	}
+
+	return 0
+}
+
+// IssuePayload drops the node's regular issuance while a silence rule is in effect; messages issued
+// by an issue rule bypass the silence on purpose.
+func (s *ScriptedNode) IssuePayload(payload multiverse.Color) {
+	s.mutex.Lock()
+	silenced := s.silenced
+	s.mutex.Unlock()
+
+	if silenced {
+		return
+	}
+	s.Node.IssuePayload(payload)
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -3,10 +3,15 @@ package adversary
 import (
 	"github.com/iotaledger/hive.go/events"
 	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
 )
 
 // region ShiftingOpinionNode ///////////////////////////////////////////////////////////////////////////////////////////////////
 
+func init() {
+	RegisterStrategy(network.ShiftOpinion, "ShiftingOpinion", network.NodeClosure(NewShiftingOpinionNode))
+}
+
 type ShiftingOpinionNode struct {
 	*multiverse.Node
 }
@@ -31,13 +36,21 @@ func (s *ShiftingOpinionNode) AssignColor(color multiverse.Color) {
 	s.Tangle().OpinionManager.SetOpinion(color)
 }
 
+// AbortAttack implements Abortable: the node votes for the majority branch like an honest node from
+// now on.
+func (s *ShiftingOpinionNode) AbortAttack() {
+	abortOpinionManager(s.Node)
+}
+
 type ShiftingOpinionManager struct {
 	*multiverse.OpinionManager
+
+	aborted bool
 }
 
 func NewShiftingOpinionManager(om multiverse.OpinionManagerInterface) *ShiftingOpinionManager {
 	return &ShiftingOpinionManager{
-		om.(*multiverse.OpinionManager),
+		OpinionManager: om.(*multiverse.OpinionManager),
 	}
 }
 
@@ -51,7 +64,19 @@ func (sm *ShiftingOpinionManager) FormOpinion(messageID multiverse.MessageID) {
 	sm.weightsUpdated()
 }
 
+// Abort falls back to the honest majority-following vote and immediately re-forms the opinion, so
+// the node joins the leading branch without waiting for the next weight update.
+func (sm *ShiftingOpinionManager) Abort() {
+	sm.aborted = true
+	sm.OpinionManager.WeightsUpdated()
+}
+
 func (sm *ShiftingOpinionManager) weightsUpdated() {
+	if sm.aborted {
+		sm.OpinionManager.WeightsUpdated()
+		return
+	}
+
 	aw := make(map[multiverse.Color]uint64)
 	for key, value := range sm.ApprovalWeights() {
 		aw[key] = value
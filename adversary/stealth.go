@@ -0,0 +1,56 @@
+package adversary
+
+import (
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region StealthNode //////////////////////////////////////////////////////////////////////////////////////////////////
+
+func init() {
+	RegisterStrategy(network.Stealth, "Stealth", network.NodeClosure(NewStealthNode))
+}
+
+// StealthNode is indistinguishable from an honest node by its traffic: it issues at exactly the rate
+// its mana allows, selects tips with the configured algorithm and relays gossip unchanged. Only its
+// votes deviate - it keeps the color assigned to its group no matter what the approval weights say.
+// Runs against it evaluate whether detection metrics based on traffic patterns can see an attacker
+// that only manipulates its opinion expression.
+type StealthNode struct {
+	*multiverse.Node
+}
+
+func NewStealthNode() interface{} {
+	node := multiverse.NewNode().(*multiverse.Node)
+	stealthNode := &StealthNode{
+		node,
+	}
+	om := node.Tangle().OpinionManager
+	node.Tangle().OpinionManager = NewSameOpinionManager(om)
+	node.Tangle().OpinionManager.Setup()
+	return stealthNode
+}
+
+func (s *StealthNode) AssignColor(color multiverse.Color) {
+	s.Tangle().OpinionManager.SetOpinion(color)
+}
+
+// AbortAttack implements Abortable: the node votes for the majority branch like an honest node from
+// now on.
+func (s *StealthNode) AbortAttack() {
+	abortOpinionManager(s.Node)
+}
+
+// EnforceStealthTraffic resets the issuance speedup of every stealth node to the honest rate: a
+// configured speedup would give the nodes away through their inter-message timing, defeating the
+// purpose of the strategy.
+func EnforceStealthTraffic(testNetwork *network.Network) {
+	for _, peer := range testNetwork.Peers {
+		if _, isStealth := peer.Node.(*StealthNode); isStealth && peer.AdversarySpeedup() != 1 {
+			log.Warnf("Stealth node %d ignores its configured speedup of %.2f to keep its issuance timing honest", peer.ID, peer.AdversarySpeedup())
+			peer.SetAdversarySpeedup(1)
+		}
+	}
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
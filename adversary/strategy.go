@@ -0,0 +1,117 @@
+package adversary
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/iotaledger/multivers-simulation/multiverse"
+)
+
+// region Strategy /////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// A Strategy is a declarative attack definition for scripted adversary nodes, so new attacks can be
+// explored from a YAML file without writing a dedicated node type. Each rule pairs a condition on
+// the node's own observation of the tangle with an action; on every adaptation tick all rules are
+// evaluated in order and the matching ones fire:
+//
+//	rules:
+//	  - when:
+//	      color: R
+//	      weightBelow: 30000
+//	      after: 30s
+//	    do: issue
+//	    color: R
+//	  - when:
+//	      color: B
+//	      weightAbove: 60000
+//	    do: silence
+//
+// Supported actions are issue (issue a message carrying the given color), assign (shift the node's
+// opinion to the given color), silence (stop the node's regular issuance) and resume (restart it).
+type Strategy struct {
+	Rules []*StrategyRule `yaml:"rules"`
+}
+
+// StrategyRule is one condition-action pair of the strategy. A rule with once set fires a single
+// time, otherwise it fires on every tick its condition holds.
+type StrategyRule struct {
+	When  StrategyCondition `yaml:"when"`
+	Do    string            `yaml:"do"`
+	Color string            `yaml:"color"`
+	Once  bool              `yaml:"once"`
+
+	color multiverse.Color
+}
+
+// StrategyCondition guards a rule: the approval weight the node sees for the given color has to be
+// within the configured bounds and the simulated time has to have passed the offset. Omitted fields
+// always hold, an empty condition matches every tick.
+type StrategyCondition struct {
+	Color       string  `yaml:"color"`
+	WeightAbove *uint64 `yaml:"weightAbove"`
+	WeightBelow *uint64 `yaml:"weightBelow"`
+	After       string  `yaml:"after"`
+
+	color multiverse.Color
+	after time.Duration
+}
+
+// LoadStrategy parses and validates the strategy file.
+func LoadStrategy(fileName string) (strategy *Strategy, err error) {
+	bytes, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read strategy file: %w", err)
+	}
+
+	strategy = &Strategy{}
+	if err := yaml.Unmarshal(bytes, strategy); err != nil {
+		return nil, fmt.Errorf("failed to parse strategy file: %w", err)
+	}
+
+	for i, rule := range strategy.Rules {
+		switch rule.Do {
+		case "issue", "assign":
+			if rule.color = multiverse.ColorFromStr(rule.Color); rule.color == multiverse.UndefinedColor {
+				return nil, fmt.Errorf("strategy rule %d needs a color for the %s action, got %q", i, rule.Do, rule.Color)
+			}
+		case "silence", "resume":
+		default:
+			return nil, fmt.Errorf("strategy rule %d has an unknown action %q", i, rule.Do)
+		}
+
+		condition := &rule.When
+		if condition.After != "" {
+			if condition.after, err = time.ParseDuration(condition.After); err != nil {
+				return nil, fmt.Errorf("strategy rule %d has an invalid offset %q: %w", i, condition.After, err)
+			}
+		}
+		condition.color = multiverse.ColorFromStr(condition.Color)
+		if (condition.WeightAbove != nil || condition.WeightBelow != nil) && condition.color == multiverse.UndefinedColor {
+			return nil, fmt.Errorf("strategy rule %d bounds the approval weight but names no color to watch", i)
+		}
+	}
+
+	return strategy, nil
+}
+
+// matches reports whether the condition holds for the given observation.
+func (c *StrategyCondition) matches(observation TangleObservation) bool {
+	if observation.Elapsed < c.after {
+		return false
+	}
+
+	approvalWeight := observation.ApprovalWeights[c.color]
+	if c.WeightAbove != nil && approvalWeight <= *c.WeightAbove {
+		return false
+	}
+	if c.WeightBelow != nil && approvalWeight >= *c.WeightBelow {
+		return false
+	}
+
+	return true
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
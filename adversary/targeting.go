@@ -0,0 +1,67 @@
+package adversary
+
+import (
+	"github.com/iotaledger/hive.go/crypto"
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region TargetingNode ///////////////////////////////////////////////////////////////////////////////////////////////////
+
+func init() {
+	RegisterStrategy(network.Targeting, "Targeting", network.NodeClosure(NewTargetingNode))
+}
+
+// TargetingNode directs its own messages at the configured victim peers instead of flooding the
+// whole neighborhood, modeling targeted opinion manipulation: with AdversaryVictimBias 1 the
+// messages reach only the victims, lower values let them leak to the remaining neighbors with the
+// complementary probability. Foreign messages are relayed normally so the node stays inconspicuous.
+// Combine with -adversaryPeeringNodes to make sure the victims are actually neighbors.
+type TargetingNode struct {
+	*multiverse.Node
+}
+
+func NewTargetingNode() interface{} {
+	node := multiverse.NewNode().(*multiverse.Node)
+	targetingNode := &TargetingNode{
+		node,
+	}
+	node.SetGossipTargets(newVictimTargeter(node))
+	return targetingNode
+}
+
+func (t *TargetingNode) AssignColor(color multiverse.Color) {
+	t.Tangle().OpinionManager.SetOpinion(color)
+}
+
+// AbortAttack implements Abortable: the node gossips its own messages to all neighbors again.
+func (t *TargetingNode) AbortAttack() {
+	t.SetGossipTargets(nil)
+}
+
+// newVictimTargeter returns the selector restricting the destinations of the node's own messages to
+// the configured victims, leaking them to each remaining neighbor with 1-AdversaryVictimBias.
+func newVictimTargeter(node *multiverse.Node) func(message *multiverse.Message) []network.PeerID {
+	victims := make(map[network.PeerID]bool)
+	for _, nodeID := range config.AdversaryVictimNodes {
+		victims[network.PeerID(nodeID)] = true
+	}
+
+	return func(message *multiverse.Message) []network.PeerID {
+		// foreign messages are relayed through the configured gossip mode
+		if message.Issuer != node.Peer().ID {
+			return nil
+		}
+
+		targets := make([]network.PeerID, 0, len(node.Peer().Neighbors))
+		for neighborID := range node.Peer().Neighbors {
+			if victims[neighborID] || crypto.Randomness.Float64() >= config.AdversaryVictimBias {
+				targets = append(targets, neighborID)
+			}
+		}
+		return targets
+	}
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,63 @@
+package adversary
+
+import (
+	"time"
+
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region TimestampForgerNode //////////////////////////////////////////////////////////////////////////////////////////
+
+func init() {
+	RegisterStrategy(network.TimestampForger, "TimestampForger", network.NodeClosure(NewTimestampForgerNode))
+}
+
+// TimestampForgerNode issues its own messages with an issuance time shifted by
+// AdversaryTimestampSkew, backdating them into the past or dating them into the future, while
+// foreign messages take the regular path. Together with TimestampWindow on the honest side it
+// evaluates how much skew a timestamp-based defense tolerates.
+type TimestampForgerNode struct {
+	*multiverse.Node
+
+	aborted bool
+}
+
+func NewTimestampForgerNode() interface{} {
+	node := multiverse.NewNode().(*multiverse.Node)
+	return &TimestampForgerNode{
+		Node: node,
+	}
+}
+
+func (t *TimestampForgerNode) AssignColor(color multiverse.Color) {
+	t.Tangle().OpinionManager.SetOpinion(color)
+}
+
+// AbortAttack implements Abortable: the node issues with truthful timestamps again.
+func (t *TimestampForgerNode) AbortAttack() {
+	t.aborted = true
+}
+
+// HandleNetworkMessage intercepts the node's own issuance requests to skew the timestamps of the
+// created messages; everything else is handled as usual.
+func (t *TimestampForgerNode) HandleNetworkMessage(networkMessage interface{}) {
+	if payload, isPayload := networkMessage.(multiverse.Color); isPayload && !t.aborted {
+		issueForgedMessage(t.Tangle(), payload)
+		return
+	}
+
+	t.Node.HandleNetworkMessage(networkMessage)
+}
+
+// issueForgedMessage creates a message carrying the payload, shifts its issuance time by the
+// configured skew and hands it to the tangle, from where it is booked and gossiped like any other
+// own message. The forger's own tangle accepts it unconditionally, only the receivers validate.
+func issueForgedMessage(tangle *multiverse.Tangle, payload multiverse.Color) {
+	message := tangle.MessageFactory.CreateMessage(payload)
+	message.IssuanceTime = message.IssuanceTime.Add(time.Duration(config.AdversaryTimestampSkew) * time.Millisecond)
+	tangle.ProcessMessage(message)
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
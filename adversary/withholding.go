@@ -0,0 +1,92 @@
+package adversary
+
+import (
+	"sync"
+
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region WithholdingNode ///////////////////////////////////////////////////////////////////////////////////////////////////
+
+func init() {
+	RegisterStrategy(network.Withholding, "Withholding", network.NodeClosure(NewWithholdingNode))
+}
+
+// Withholder is implemented by every node type that keeps its own messages in a private side tangle
+// until they are released at once.
+type Withholder interface {
+	Release() (released int)
+}
+
+// WithholdingNode builds a private side tangle: its own messages keep supporting the assigned color
+// but are not gossiped until Release floods them all at once, testing whether already confirmed
+// opinions can be reorganized by a delayed reveal. Foreign messages keep flowing normally.
+type WithholdingNode struct {
+	*multiverse.Node
+
+	withheldMessages []*multiverse.Message
+	withholdingMutex sync.Mutex
+	released         bool
+}
+
+func NewWithholdingNode() interface{} {
+	node := multiverse.NewNode().(*multiverse.Node)
+	withholdingNode := &WithholdingNode{
+		Node: node,
+	}
+	withholdingNode.setupOpinionManager()
+	node.SetGossipFilter(withholdingNode.interceptOwnMessages)
+	return withholdingNode
+}
+
+func (w *WithholdingNode) setupOpinionManager() {
+	om := w.Tangle().OpinionManager
+	w.Tangle().OpinionManager = NewSameOpinionManager(om)
+	w.Tangle().OpinionManager.Setup()
+}
+
+func (w *WithholdingNode) AssignColor(color multiverse.Color) {
+	w.Tangle().OpinionManager.SetOpinion(color)
+}
+
+// AbortAttack implements Abortable: it releases the private side tangle and lets the node vote for
+// the majority branch like an honest node from now on.
+func (w *WithholdingNode) AbortAttack() {
+	w.Release()
+	abortOpinionManager(w.Node)
+}
+
+// interceptOwnMessages keeps the node's own messages in the private side tangle until the release;
+// it doubles as the MessageRequest filter so the side tangle cannot leak through solidification.
+func (w *WithholdingNode) interceptOwnMessages(message *multiverse.Message) bool {
+	if message.Issuer != w.Peer().ID {
+		return true
+	}
+
+	w.withholdingMutex.Lock()
+	defer w.withholdingMutex.Unlock()
+	if w.released {
+		return true
+	}
+
+	w.withheldMessages = append(w.withheldMessages, message)
+	return false
+}
+
+// Release floods the entire withheld side tangle to the neighbors at once and lets all future own
+// messages through again.
+func (w *WithholdingNode) Release() (released int) {
+	w.withholdingMutex.Lock()
+	messages := w.withheldMessages
+	w.withheldMessages = nil
+	w.released = true
+	w.withholdingMutex.Unlock()
+
+	for _, message := range messages {
+		w.Peer().GossipNetworkMessage(message)
+	}
+	return len(messages)
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,147 @@
+package clock
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// region Clock ////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// Clock abstracts the source of the current time, so components can run on the wall clock or on the
+// virtual clock of the discrete-event scheduler without calling time.Now directly.
+type Clock interface {
+	Now() time.Time
+}
+
+// WallClock is the Clock of realtime runs, backed by time.Now.
+type WallClock struct{}
+
+func (WallClock) Now() time.Time {
+	return time.Now()
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// region Scheduler ////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// Scheduler is a discrete-event scheduler with a virtual clock. Instead of waiting on wall-clock tickers,
+// callbacks are queued with a virtual due time and executed back-to-back in timestamp order, so a run is
+// deterministic (given fixed randomness) and finishes as fast as the host can process events. With the
+// scheduler active there is no need for a SlowdownFactor - virtual time is decoupled from real time.
+type Scheduler struct {
+	events          eventQueue
+	now             time.Time
+	sequenceCounter uint64
+	stopped         bool
+	mutex           sync.Mutex
+}
+
+// Virtual is the scheduler instance driving the simulation when config.DiscreteEvent is enabled.
+var Virtual = NewScheduler()
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		events: make(eventQueue, 0),
+		now:    time.Now(),
+	}
+}
+
+// Now returns the current virtual time.
+func (s *Scheduler) Now() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.now
+}
+
+// ExecuteAfter queues the callback to run once the virtual clock advanced by the given delay.
+func (s *Scheduler) ExecuteAfter(delay time.Duration, callback func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.sequenceCounter++
+	heap.Push(&s.events, &event{
+		dueTime:  s.now.Add(delay),
+		sequence: s.sequenceCounter,
+		callback: callback,
+	})
+}
+
+// Run processes queued events in due-time order, advancing the virtual clock, until the queue drains, the
+// given virtual duration elapsed or Stop was called. Events are executed on the calling goroutine.
+func (s *Scheduler) Run(duration time.Duration) {
+	s.mutex.Lock()
+	deadline := s.now.Add(duration)
+	s.mutex.Unlock()
+
+	for {
+		s.mutex.Lock()
+		if s.stopped || len(s.events) == 0 {
+			s.mutex.Unlock()
+			return
+		}
+
+		nextEvent := heap.Pop(&s.events).(*event)
+		if nextEvent.dueTime.After(deadline) {
+			s.now = deadline
+			s.mutex.Unlock()
+			return
+		}
+		s.now = nextEvent.dueTime
+		s.mutex.Unlock()
+
+		nextEvent.callback()
+	}
+}
+
+// Stop makes Run return after the currently executing event.
+func (s *Scheduler) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.stopped = true
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// region eventQueue ///////////////////////////////////////////////////////////////////////////////////////////////////
+
+type event struct {
+	dueTime  time.Time
+	sequence uint64
+	callback func()
+}
+
+// eventQueue implements heap.Interface ordered by due time, with the insertion sequence as tie breaker to
+// keep simultaneous events in a deterministic order.
+type eventQueue []*event
+
+func (q eventQueue) Len() int {
+	return len(q)
+}
+
+func (q eventQueue) Less(i, j int) bool {
+	if q[i].dueTime.Equal(q[j].dueTime) {
+		return q[i].sequence < q[j].sequence
+	}
+	return q[i].dueTime.Before(q[j].dueTime)
+}
+
+func (q eventQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *eventQueue) Push(x interface{}) {
+	*q = append(*q, x.(*event))
+}
+
+func (q *eventQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	poppedEvent := old[n-1]
+	*q = old[:n-1]
+	return poppedEvent
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -3,6 +3,26 @@ package config
 // simulator settings
 
 var (
+	Runs                            = 1           // Number of independent repetitions of the configured simulation, executed in parallel child processes with distinct seeds. Results are aggregated into runs-aggregate.csv.
+	Seed                            = int64(0)    // Base seed for the pseudo random sources, 0 keeps the time-based seeding.
+	Sweep                           = ""          // Parameter sweep specification, e.g. "zipfParameter=0.5,0.9;packetLoss=0:0.2:0.05". Runs every combination in a subdirectory of ResultDir.
+	DiscreteEvent                   = false       // Run on the discrete-event scheduler with a virtual clock instead of wall-clock tickers. Runs become deterministic, finish as fast as the host allows and ignore SlowdownFactor.
+	RecordTrace                     = ""          // Path of a trace file to record every message issuance and delivery event to, for later replay.
+	ReplayTrace                     = ""          // Path of a recorded trace file to replay through the tangle logic instead of running the network layer.
+	SnapshotExportFile              = ""          // Path to write the confirmed state of the observed peer to at the end of the run, for later warm starts.
+	SnapshotImportFile              = ""          // Path of a previously exported snapshot to warm-start every node's tangle from.
+	GenesisMessages                 = []string{}  // Preassigned genesis messages as "color:weight" entries, e.g. "R:40 B:30": every node starts with the same colored attachment points and prior branch weights, so a run begins mid-conflict instead of from the single uncolored genesis.
+	MetricsWarmup                   = 0           // Warm-up period in simulated seconds during which no metrics are recorded, so startup transients do not pollute the results.
+	StopConditions                  = "consensus" // Comma-separated stop conditions ending the run when any of them is met: consensus, adversarySuccess, wallClock, metastability, maxMessages.
+	WallClockLimit                  = 0           // Real-time limit in seconds for the wallClock stop condition, 0 disables it.
+	MetastabilityFlips              = 10          // Number of honest winning-color flips after which the metastability stop condition considers the run metastable.
+	MaxIssuedMessages               = 0           // Number of issued messages after which the maxMessages stop condition ends the run, 0 disables it.
+	Scenario                        = ""          // Path of a YAML scenario file scripting a timeline of events (double spends, silenced groups etc.) against the running network.
+	CalibrateSlowdown               = false       // Probe the host's message processing rate before a realtime run and raise SlowdownFactor automatically when it cannot sustain the configured TPS.
+	ProgressJSON                    = false       // Emit one machine-readable progress JSON object per monitor tick on stderr, for orchestration tools.
+	Validate                        = false       // Check the configuration invariants, build the topology once and print the effective configuration without running the simulation.
+	ListStrategies                  = false       // Print the registered adversary strategies with their type codes and exit; the values AdversaryTypes selects from.
+	MaxMemoryMB                     = 0           // Memory ceiling in MB for the resource watchdog; exceeding it ends the run gracefully with final dumps. 0 disables the ceiling.
 	ResultDir                       = "results"   // Path where all the result files will be saved
 	SimulationTarget                = "CT"        // The simulation target, CT: Confirmation Time, DS: Double Spending
 	SimulationStopThreshold         = 1.0         // Stop the simulation when > SimulationStopThreshold * NodesCount have reached the same opinion.
@@ -15,15 +35,102 @@ var (
 // Network setup
 
 var (
-	NodesCount       = 10        // NodesCount is the total number of nodes simulated in the network.
-	TPS              = 50        // TPS defines the total network throughput.
-	ParentsCount     = 1         // ParentsCount that a new message is selecting from the tip pool.
-	NeighbourCountWS = 8         // Number of neighbors node is connected to in WattsStrogatz network topology.
-	RandomnessWS     = 1.0       // WattsStrogatz randomness parameter, gamma parameter described in https://blog.iota.org/the-fast-probabilistic-consensus-simulator-d5963c558b6e/
-	IMIF             = "poisson" // IMIF Inter Message Issuing Function for time delay between activity messages: poisson or uniform.
-	PacketLoss       = 0.0       // The packet loss in the network.
-	MinDelay         = 100       // The minimum network delay in ms.
-	MaxDelay         = 100       // The maximum network delay in ms.
+	NodesCount   = 10 // NodesCount is the total number of nodes simulated in the network.
+	TPS          = 50 // TPS defines the total network throughput.
+	ParentsCount = 1  // ParentsCount that a new message is selecting from the tip pool.
+
+	ParentsCountPolicy           = "upto"  // How ParentsCount is applied: upto (at most), exact (the full count whenever enough distinct tips exist) or adaptive (scales with the tip pool size up to ParentsCount).
+	AdaptiveParentsTipsPerParent = 4       // Adaptive parents policy: one strong parent per this many tips in the pool.
+	Topology                     = "WS"    // Network topology: WS (WattsStrogatz), ER (ErdosRenyi), BA (BarabasiAlbert), CM (configuration model from DegreeSequence) or MR (multi-region clusters).
+	DegreeSequence               = []int{} // Target degree of every node for the CM topology, e.g. '8 8 4 4 ...'. Needs one entry per node and an even sum.
+
+	ClusterCount           = 4         // Number of equally sized regions of the MR topology.
+	ClusterSizes           = []int{}   // Explicit region sizes for the MR topology, e.g. '40 30 30'. Must sum to NodesCount; empty splits NodesCount evenly into ClusterCount regions.
+	ClusterIntraNeighbors  = 4         // Ring neighbors of every node inside its region.
+	ClusterInterLinks      = 2         // Random links between every pair of regions.
+	ClusterIntraDelayMs    = 10        // Fixed delay in ms of the links inside a region.
+	ClusterInterDelayMs    = 150       // Fixed delay in ms of the links between regions.
+	ClusterInterPacketLoss = 0.0       // Packet loss of the links between regions.
+	NeighbourCountWS       = 8         // Number of neighbors node is connected to in WattsStrogatz network topology.
+	RandomnessWS           = 1.0       // WattsStrogatz randomness parameter, gamma parameter described in https://blog.iota.org/the-fast-probabilistic-consensus-simulator-d5963c558b6e/
+	ErdosRenyiP            = 0.1       // Edge probability of the ErdosRenyi topology.
+	BarabasiAlbertM        = 4         // Number of edges each new node attaches with in the BarabasiAlbert topology.
+	TopologyFile           = ""        // Path of an explicit topology file (CSV 'source,target[,delayMs[,packetLoss[,reverseDelayMs]]]' or GraphML). Overrides Topology when set.
+	IMIF                   = "poisson" // IMIF Inter Message Issuing Function for time delay between activity messages: poisson or uniform.
+	PacketLoss             = 0.0       // The packet loss in the network.
+	MinDelay               = 100       // The minimum network delay in ms.
+	MaxDelay               = 100       // The maximum network delay in ms.
+	AsymmetricDelays       = false     // Give each direction of a link its own fixed delay drawn from the Min/MaxDelay range, instead of a shared per-link delay.
+	JitterMs               = 0.0       // Standard deviation in ms of the zero-mean normal jitter added to every delivery, resampled per message. 0 disables jitter.
+
+	DelayDistribution = "uniform" // Distribution the link delays are drawn from: uniform (Min/MaxDelay range), lognormal:mu:sigma (log-ms), pareto:scaleMs:alpha or bimodal:p:lowMs:highMs.
+	LossDistribution  = "uniform" // Distribution the per-link loss rates are drawn from: uniform (the PacketLoss rate), exponential:mean or bimodal:p:low:high.
+
+	BurstLoss           = false // Correlate the losses of every link with the Gilbert-Elliott two-state chain instead of dropping messages independently.
+	BurstLossPGoodToBad = 0.001 // Per-message probability of a link entering the lossy burst state.
+	BurstLossPBadToGood = 0.05  // Per-message probability of a link leaving the lossy burst state.
+	BurstLossBad        = 0.8   // Loss rate of a link in the burst state; outside of it the per-link loss rate applies.
+
+	NatFraction = 0.0 // Fraction of the nodes behind a NAT blocking inbound dial-in: links towards a NATed node only deliver in its outbound direction, links between two NATed nodes are removed. Connectivity is dumped to nt-*.csv.
+
+	BandwidthMsgPerSec = 0 // Outbound capacity of every connection in messages per second; messages exceeding it queue up and the queue lengths are dumped to ql-*.csv. 0 models unlimited bandwidth.
+
+	GossipMode         = "flood" // Gossip protocol: flood (push every booked message to all neighbors), fanout (push to GossipFanout random neighbors) or pull (limited push plus periodic IHAVE announcements the neighbors pull missing messages from). Duplicate statistics are dumped to gs-*.csv.
+	GossipFanout       = 4       // Number of random neighbors a booked message is pushed to in the fanout and pull gossip modes.
+	GossipPullInterval = 1000    // Interval in ms between the IHAVE announcements of the pull gossip mode.
+
+	RequesterBaseInterval = 5  // Wait before the first retry of a missing-message request; every unanswered retry doubles it. In seconds.
+	RequesterTimeout      = 60 // Time after which a missing-message request is given up. In seconds.
+
+	PeerScoringInterval        = 0    // Interval in simulated seconds between neighbor score evaluations dropping misbehaving neighbors, 0 disables peer scoring.
+	PeerScoringMaxInvalidRatio = 0.95 // Share of duplicate or conflicting deliveries above which a neighbor is dropped.
+	PeerScoringMinDeliveries   = 100  // Number of deliveries after which a neighbor's score is judged.
+
+	DetectionMetrics           = false // Track per-node detection counters (equivocations seen, over-rate issuers, conflicting timestamps) and dump them per node to dt-*.csv, for research into attacker-detection heuristics.
+	DetectionOverRateTolerance = 2.0   // Factor above an issuer's mana-allowed message rate from which its messages count as over-rate.
+
+	EpochCommitments = false // Nodes group their confirmed messages into epochs and compute one commitment per epoch; the divergence of the commitments across nodes is dumped to ep-*.csv.
+	EpochDuration    = 10    // Duration of one epoch. In seconds.
+
+	DynamicWeightsEnabled = false // Node weights evolve during the run: every epoch the weights decay by ManaDecayPerEpoch and the decayed mana is re-earned by the issuers of the confirmed messages; consensus reads the weights sealed at the last epoch boundary instead of the live values.
+	ManaDecayPerEpoch     = 0.1   // Fraction of every node's weight that decays per epoch and is redistributed along the confirmed messages; 0 keeps the weights constant but still seals them per epoch.
+
+	RateSetterEnabled             = false // Honest nodes adapt their issuance rate to the observed congestion with an AIMD rule instead of always issuing at their full mana-proportional band; the per-node rates are dumped to rt-*.csv.
+	RateSetterInterval            = 1     // Interval between two AIMD adjustments of the rate setter. In seconds.
+	RateSetterIncrease            = 0.05  // Additive increase of the rate factor per interval without congestion.
+	RateSetterDecrease            = 0.7   // Multiplicative decrease of the rate factor on congestion.
+	RateSetterCongestionThreshold = 20    // Tip pool size above which a node considers the network congested.
+	RateSetterMin                 = 0.1   // Lower bound of the rate factor, keeping every node issuing.
+
+	AdaptivePowEnabled  = false // Per-node issuance cost scales with the recent issuance rate relative to the mana-fair rate, an adaptive proof-of-work alternative to the rate setter that also binds adversary nodes.
+	AdaptivePowInterval = 5     // Length of the window over which the recent issuance rate is measured and the difficulty retargeted. In seconds.
+	AdaptivePowGamma    = 1.0   // Difficulty units added per multiple of the mana-fair rate a node issues above it; every unit halves the effective issuance rate.
+	AdaptivePowMin      = 0.05  // Lower bound of the adaptive PoW rate factor, keeping every node issuing.
+
+	SchedulerEnabled  = false // A deficit-round-robin scheduler with mana-proportional quanta sits between booking and gossip, throttling spam by low-mana issuers; queue lengths and queueing delays are dumped to sc-*.csv.
+	SchedulerRate     = 200   // Number of messages every node's scheduler releases per second.
+	SchedulerInterval = 50    // Interval between two drain rounds of the scheduler. In milliseconds.
+
+	PruningEnabled  = false // Evict messages confirmed more than PruningAge ago from the in-memory tangles, keeping only a pruned marker, so long or high-TPS simulations don't grow memory without bound.
+	PruningAge      = 60    // Age since confirmation above which a message is evicted. In seconds.
+	PruningInterval = 10    // Interval between two pruning passes. In seconds.
+
+	GeoLatency       = false // Derive link delays from great-circle distance between regional peer positions instead of the Min/MaxDelay range.
+	GeoBaseLatencyMs = 5     // Regional base latency in ms added on top of the propagation time in the geographic latency model.
+
+	LatencyMatrixFile       = ""        // Path of a CSV inter-city RTT matrix (ms) driving the link delays, e.g. WonderNetwork data. Overrides GeoLatency and the Min/MaxDelay range.
+	LatencyMatrixAssignment = "uniform" // How nodes are assigned to the matrix locations: uniform (round-robin) or random.
+
+	RewireInterval = 0   // Interval in simulated seconds between topology rewiring rounds, 0 disables neighbor churn.
+	RewireFraction = 0.1 // Fraction of the connections dropped and re-established per rewiring round.
+
+	PartitionStart    = 0  // Simulated second at which the network is split into PartitionCount components, 0 disables partitioning. Membership is dumped to pt-*.csv.
+	PartitionDuration = 30 // Duration of the partition in simulated seconds, after which the components are reconnected.
+	PartitionCount    = 2  // Number of components the network is split into.
+
+	ChurnInterval = 0   // Interval in simulated seconds between node churn rounds taking honest nodes offline, 0 disables node churn.
+	ChurnFraction = 0.1 // Fraction of the nodes taken offline per churn round.
+	ChurnDowntime = 10  // Downtime in simulated seconds after which a churned node comes back online and re-solidifies what it missed.
 
 	SlowdownFactor = 1 // The factor to control the speed in the simulation.
 )
@@ -36,14 +143,51 @@ var (
 	ConfirmationThreshold         = 0.66    // Threshold for AW collection above which messages are considered confirmed.
 	ConfirmationThresholdAbsolute = true    // If true the threshold is alway counted from zero if false the weight collected is counted from the next peer weight.
 	RelevantValidatorWeight       = 0       // The node whose weight * RelevantValidatorWeight <= largestWeight will not issue messages (disabled now)
+
+	ConfirmationGrades = []float64{0.5, 0.66, 0.9} // Witness weight fractions at which a message reaches confirmation grade 1, 2, ... The per-grade latencies are dumped to the aw results.
+
+	ConfirmationRule           = "WW" // The message confirmation rule: WW (witness weight fraction), AW (absolute witness weight), Milestone (only coordinator milestones confirm) or Hybrid (witness weight fraction plus a minimum age).
+	ConfirmationWWFraction     = 0.5  // Fraction of the witness weight scale at which the WW and Hybrid rules confirm a message; 0.5 reproduces the weight >= 50 rule the confirmation grades are also relative to.
+	ConfirmationAbsoluteWeight = 50   // Witness weight at which the AW rule confirms a message.
+	ConfirmationMinAge         = 5    // Minimum age a message additionally needs under the Hybrid rule. In seconds.
+
+	MarkerBasedAW = false // Approval weight hops only between marker messages instead of walking the full past cone of every solid message, trading exactness for CPU; non-markers confirm through the past cone of their nearest confirmed marker.
+	MarkerSpacing = 10    // Every message whose height is a multiple of MarkerSpacing is a marker; larger values mean fewer markers and a coarser approximation.
+
+	CoordinatorEnabled = false // The largest-weight node acts as coordinator and confirmation is milestone based: a milestone confirms its entire past cone while approval weight is still tracked for comparison.
+	MilestoneInterval  = 10    // Interval between two milestones of the coordinator. In seconds.
+
+	FPCVotingEnabled      = false // Conflicting colors are resolved by FPC-style query rounds instead of approval weight: nodes periodically ask random nodes for their opinion and switch by threshold; the approval weights keep feeding the reporting.
+	FPCRoundInterval      = 1     // Interval between two FPC voting rounds. In seconds.
+	FPCQuerySampleSize    = 10    // Number of random nodes every node queries per round.
+	FPCThreshold          = 0.5   // Lower bound of the per-round adoption threshold.
+	FPCThresholdUpper     = 0.67  // Upper bound of the per-round adoption threshold; the round's threshold is drawn uniformly in between, like the shared random threshold of the protocol.
+	FPCFinalizationRounds = 10    // Number of consecutive unchanged rounds after which a node finalizes its opinion.
 )
 
 // Tip Selection Algorithm setup
 
 var (
-	TSA           = "POW" // Currently only one supported TSA is URTS
-	DeltaURTS     = 5.0   // in seconds, reference: https://iota.cafe/t/orphanage-with-restricted-urts/1199
-	WeakTipsRatio = 0.0   // The ratio of weak tips
+	TSA = "POW" // The tip selection algorithm: POW, URTS, RURTS (age-restricted) or MRTS (mana-weighted).
+
+	PastConeCheckEnabled = false // Refuse strong parents whose past cone approves a branch the node dislikes, instead of approving conflicting branches silently.
+
+	OrphanageReporting = false // Dump the per-issuer and aggregate orphan rates to or-*.csv even without an orphanage attack or RURTS configured.
+	OrphanageHorizon   = 10    // Age in simulated seconds after which a still-unreferenced message counts as orphaned in the or output.
+
+	ReattachmentEnabled  = false // Honest nodes reissue the payloads of own messages that stayed unreferenced past the deadline, the wallet behavior of real nodes.
+	ReattachmentDeadline = 30    // Age in seconds after which an own unreferenced message is reattached.
+	ReattachmentInterval = 10    // Interval between two reattachment checks. In seconds.
+	DeltaURTS            = 5.0   // in seconds, reference: https://iota.cafe/t/orphanage-with-restricted-urts/1199
+	WeakTipsRatio        = 0.0   // The ratio of weak tips
+
+	LikeReferencesEnabled = false // Messages carry shallow like references expressing the issuer's opinion on every live conflict explicitly, independent of the branches of their parents.
+)
+
+// Timestamp validation
+
+var (
+	TimestampWindow = 0.0 // Window in seconds around the local clock within which nodes accept the issuance time of a received message, 0 disables the validation. Rejected messages never enter the tangle and their count is dumped to gs-*.csv.
 )
 
 // Adversary setup - enabled by setting SimulationTarget="DS"
@@ -51,16 +195,77 @@ var (
 	// SimulationMode for the DS simulations one of:
 	// 'Accidental' - accidental double spends sent by max, min or random weight node from Zipf distrib,
 	// 'Adversary' - need to use adversary groups (parameters starting with 'Adversary...')
-	SimulationMode   = "Accidental"
-	DoubleSpendDelay = 20 // Delay after which double spending transactions will be issued. In seconds.
+	SimulationMode     = "Accidental"
+	DoubleSpendDelay   = 20 // Delay after which double spending transactions will be issued. In seconds.
+	ConflictColorCount = 3  // Number of conflicting colors a double spend can split the network into. The first three are named Blue, Red and Green, further colors are numbered C4, C5, ...
+	ConflictSetCount   = 1  // Number of independent conflict sets that can be live simultaneously. Each set owns its own run of ConflictColorCount colors; opinions and confirmations are formed per set.
+
+	LedgerEnabled = false // Messages carry UTXO transactions and conflicts arise from actual double spends of an output instead of injected colors. Confirmation then applies to transactions, see the Ledger component.
+
+	DoubleSpendSchedule = []string{} // Schedule of conflict issuances as 'delaySeconds:issuer:color' entries, e.g. '20:max:R 40:random:G'. Issuer is 'min', 'max', 'random' or a node ID. Overrides the single conflict after DoubleSpendDelay.
 
 	AccidentalMana = []string{"random", "random"} // Defines nodes which will be used: 'min', 'max', 'random' or valid nodeID
 
-	AdversaryDelays     = []int{}             // Delays in ms of adversary nodes, eg '50 100 200', SimulationTarget must be 'DS'
-	AdversaryTypes      = []int{0, 0}         // Defines group attack strategy, one of the following: 0 - honest node behavior, 1 - shifts opinion, 2 - keeps the same opinion, 3 - nodes not gossiping anything, even DS. SimulationTarget must be 'DS'
-	AdversaryMana       = []float64{}         // Adversary nodes mana in %, e.g. '10 10'. Default value: 1%. SimulationTarget must be 'DS'
-	AdversaryNodeCounts = []int{}             // Defines number of adversary nodes in the group. Leave empty for default value: 1.
-	AdversaryInitColors = []string{"R", "B"}  // Defines initial color for adversary group, one of following: 'R', 'G', 'B'. Mandatory for each group.
-	AdversaryPeeringAll = false               // Defines a flag indicating whether adversarial nodes should be able to send messages to all nodes in the network, instead of following regular peering algorithm.
-	AdversarySpeedup    = []float64{1.0, 1.0} // Defines how many more messages should adversary nodes issue.
+	AdversaryDelays       = []int{}             // Delays in ms of adversary nodes, eg '50 100 200', SimulationTarget must be 'DS'
+	AdversaryTypes        = []int{0, 0}         // Defines group attack strategy, one of the following: 0 - honest node behavior, 1 - shifts opinion, 2 - keeps the same opinion, 3 - drops gossip traffic, all of it by default or only the selection from the AdversaryDrop* options, 4 - balances two conflicting colors by always supporting the trailing one, 5 - censors the issuers listed in AdversaryCensoredIssuers, 6 - withholds its own messages until AdversaryWithholdDelay, 7 - attaches only to the oldest tips to maximize the honest orphanage, 8 - nothing-at-stake voting for every conflicting branch at once, 9 - gossips its own messages to the victims listed in AdversaryVictimNodes only, 10 - runs the YAML rules from AdversaryStrategyFile, 11 - issues its own messages with timestamps skewed by AdversaryTimestampSkew, 12 - grows a private parasitic chain anchored at an old message and attaches it every AdversaryParasiteInterval, 13 - replays stale messages and re-requests them every AdversaryReplayInterval, 14 - stealth attacker matching honest issuance timing and tip selection, only its votes keep the assigned color. SimulationTarget must be 'DS'
+	AdversaryMana         = []float64{}         // Adversary nodes mana in %, e.g. '10 10'. Default value: 1%. SimulationTarget must be 'DS'
+	AdversaryNodeCounts   = []int{}             // Defines number of adversary nodes in the group. Leave empty for default value: 1.
+	AdversaryInitColors   = []string{"R", "B"}  // Defines initial color for adversary group, one of following: 'R', 'G', 'B' or 'C<n>' for colors beyond the third. Mandatory for each group.
+	AdversaryPeeringAll   = false               // Defines a flag indicating whether adversarial nodes should be able to send messages to all nodes in the network, instead of following regular peering algorithm.
+	AdversaryPeeringTopK  = 0                   // Peer every adversary node with the top-k mana holders on top of its regular peering, 0 disables it. Ignored when AdversaryPeeringAll is set.
+	AdversaryPeeringNodes = []int{}             // Explicit list of nodes every adversary node is peered with, e.g. '0 3 7'. Overrides AdversaryPeeringTopK.
+	AdversarySpeedup      = []float64{1.0, 1.0} // Defines how many more messages should adversary nodes issue.
+
+	AdversaryActivationTimes = []int{} // Seconds into the run at which each adversary group becomes active, e.g. '0 30'. Inactive groups stay completely silent, not even issuing honest-looking messages. Leave empty to activate all groups at the start.
+
+	AdversaryCompositions = []string{} // Extra behaviors layered on top of each group's AdversaryType as '+'-joined strategy codes, e.g. '3+7 0'. '0' layers nothing. Leave empty to keep every group a single strategy.
+
+	AdversaryInternalDelay = -1    // Delay in ms on the private links among the members of each adversary group, created on top of the regular peering. -1 disables the private channel.
+	AdversaryAdaptInterval = 0     // Interval in ms between the adaptation ticks handed to adversary nodes implementing the adaptive hook, 0 disables it.
+	AdversaryOmniscient    = false // Hands the adversary decision logic the freshest tangle state seen anywhere in the network instead of only the node's own, the unrealistic upper baseline for comparisons.
+
+	AdversaryAbortConditions = []string{} // Per-group conditions calling the attack off, e.g. 'weight:50 time:60': 'weight:p' aborts once the group's color holds p% of the total weight as seen by the first monitored peer, 'time:s' after s seconds of simulated time, '-' keeps the group attacking. Aborted groups fall back to honest behavior.
+
+	AdversaryDropColor   = ""      // Color whose messages the no-gossip adversary (type 3) drops while forwarding the rest, one of following: 'R', 'G', 'B'. Empty keeps the strategy dropping everything.
+	AdversaryDropIssuers = []int{} // Issuers whose messages the no-gossip adversary drops while forwarding the rest, e.g. '0 3 7'. Empty keeps the strategy dropping everything.
+	AdversaryDropRate    = 1.0     // Probability with which the no-gossip adversary drops a selected message; with no color and no issuers selected it applies to the whole traffic.
+
+	AdversaryCensoredIssuers = []int{} // Nodes whose messages the censorship adversary (type 5) never picks as parents or forwards. Their orphanage is dumped to cs-*.csv.
+
+	AdversaryWithholdDelay = 0 // Seconds after which the withholding adversary (type 6) releases its private side tangle, 0 never releases it.
+
+	AdversaryVictimNodes = []int{} // Victim peers the targeting adversary (type 9) directs its own messages at, e.g. '0 3 7'. Combine with AdversaryPeeringNodes so the victims are actually neighbors.
+	AdversaryVictimBias  = 1.0     // Probability that the targeting adversary skips a non-victim neighbor when gossiping its own messages, 1 targets the victims exclusively.
+
+	AdversaryStrategyFile = "" // Path to a YAML file with declarative rules for the scripted adversary (type 10), pairing conditions on the observed approval weights with issue/assign/silence/resume actions.
+
+	AdversaryTimestampSkew = 0 // Skew in ms the timestamp forger (type 11) adds to the issuance time of its own messages, negative values backdate them. Combine with TimestampWindow to evaluate timestamp-based defenses.
+
+	AdversaryParasiteInterval = 0 // Seconds between the attachments of the parasitic chain (type 12) to the main tangle, 0 never attaches it. The honest approval weight the chain captures is dumped to pc-*.csv.
+
+	AdversaryReplayInterval = 0  // Seconds between the replay rounds of the replay adversary (type 13), 0 disables replaying. The issued requests are dumped as the replayed cause in gs-*.csv.
+	AdversaryReplayBatch    = 10 // Number of stale messages every replay node re-gossips and re-requests per replay round.
+
+	AdversaryBudget         = 0.0 // Total action budget of the adversary, 0 disables the cost model. Every action beyond honest behavior consumes budget at its configured cost; the usage over time is dumped to ab-*.csv for cost-effectiveness comparisons. The accounting does not throttle the attacks.
+	AdversaryCostMessage    = 1.0 // Budget cost of each extra message the adversary issues, such as a spam burst or a replayed stale message.
+	AdversaryCostRelease    = 1.0 // Budget cost of each withheld message released late, such as a released side tangle or an attached parasitic chain segment.
+	AdversaryCostConnection = 1.0 // Budget cost of each connection the adversary peering options add on top of the topology.
+
+	SybilCount     = 0   // Number of additional adversary identities injected mid-run, 0 disables the injection. SimulationMode must be 'Adversary'.
+	SybilStart     = 30  // Seconds into the run at which the sybil identities come online.
+	SybilMana      = 1.0 // Mana in % of the total weight granted to the sybil group at injection, taken proportionally from the honest nodes.
+	SybilType      = 2   // Attack strategy of the sybil identities, same encoding as AdversaryTypes.
+	SybilInitColor = "R" // Color the sybil identities support in the ongoing conflict, one of following: 'R', 'G', 'B'.
+
+	AdversarySpamInterval = 0   // Interval in seconds between the spam bursts of the adversary nodes, 0 disables bursting. Between bursts the nodes issue at their regular rate.
+	AdversarySpamBurst    = 100 // Number of messages every adversary node issues per spam burst, far above its mana-allowed rate.
+
+	AdversaryControlAddress = "" // TCP address (host:port) for the external adversary controller interface, empty to disable.
+
+	EclipseVictim      = -1 // Node whose connections are handed to the adversary for an eclipse attack, -1 disables it. The victim's divergence is dumped to ec-*.csv.
+	EclipseConnections = 0  // Number of the victim's honest connections replaced with adversary connections, 0 eclipses the victim completely.
+
+	AdversaryManaGrowthRate = []float64{} // Mana acquired by each adversary group per simulated minute, in % of NodesTotalWeight. Acquired weight is taken proportionally from honest nodes. Leave empty to keep weights frozen.
+
+	AdversaryManaSchedule = []string{} // Checkpoints of every adversary group's mana share as 'second:percent' entries, e.g. '0:5 120:20', linearly interpolated in between. Weight moves between the honest nodes and the groups and the issuance rates follow live. Leave empty to keep weights frozen.
 )
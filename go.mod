@@ -7,4 +7,5 @@ require (
 	github.com/stretchr/testify v1.6.1
 	go.uber.org/atomic v1.7.0
 	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
@@ -11,15 +11,18 @@ import (
 // region ApprovalManager //////////////////////////////////////////////////////////////////////////////////////////////////
 
 type ApprovalManager struct {
-	tangle *Tangle
-	Events *ApprovalWeightEvents
+	tangle           *Tangle
+	confirmationRule ConfirmationRule
+	Events           *ApprovalWeightEvents
 }
 
 func NewApprovalManager(tangle *Tangle) *ApprovalManager {
 	return &ApprovalManager{
-		tangle: tangle,
+		tangle:           tangle,
+		confirmationRule: ConfirmationRuleFromString(config.ConfirmationRule),
 		Events: &ApprovalWeightEvents{
 			MessageConfirmed:            events.NewEvent(approvalEventCaller),
+			MessageGraded:               events.NewEvent(gradedEventCaller),
 			MessageWeightUpdated:        events.NewEvent(approvalEventCaller),
 			MessageWitnessWeightUpdated: events.NewEvent(witnessWeightEventCaller),
 		},
@@ -34,14 +37,29 @@ func witnessWeightEventCaller(handler interface{}, params ...interface{}) {
 	handler.(func(*Message, uint64))(params[0].(*Message), params[1].(uint64))
 }
 
+func gradedEventCaller(handler interface{}, params ...interface{}) {
+	handler.(func(*Message, *MessageMetadata, int, uint64, int64))(params[0].(*Message), params[1].(*MessageMetadata), params[2].(int), params[3].(uint64), params[4].(int64))
+}
+
 func (a *ApprovalManager) Setup() {
-	a.tangle.Solidifier.Events.MessageSolid.Attach(events.NewClosure(a.ApproveMessages))
+	if config.MarkerBasedAW {
+		a.tangle.Solidifier.Events.MessageSolid.Attach(events.NewClosure(a.ApproveMessagesByMarkers))
+	} else {
+		a.tangle.Solidifier.Events.MessageSolid.Attach(events.NewClosure(a.ApproveMessages))
+	}
+	if config.CoordinatorEnabled {
+		a.tangle.Solidifier.Events.MessageSolid.Attach(events.NewClosure(a.ConfirmMilestoneCone))
+	}
 }
 
 func (a *ApprovalManager) ApproveMessages(messageID MessageID) {
 	count := 0
 	weight := 1
 	a.tangle.Utils.WalkMessagesAndMetadata(func(message *Message, messageMetadata *MessageMetadata, walker *walker.Walker) {
+		// pruned messages are a walk boundary, their weight can no longer change anything
+		if message == nil {
+			return
+		}
 		if int(a.tangle.Peer.ID) == config.MonitoredWitnessWeightPeer && messageMetadata.id == MessageID(config.MonitoredWitnessWeightMessageID) {
 			log.Infof("Peer %d Message %d Witness Weight %d", a.tangle.Peer.ID, messageMetadata.id, messageMetadata.weight)
 			a.Events.MessageWitnessWeightUpdated.Trigger(message, messageMetadata.weight)
@@ -49,16 +67,89 @@ func (a *ApprovalManager) ApproveMessages(messageID MessageID) {
 		if count <= weight {
 			count++
 			a.Events.MessageWeightUpdated.Trigger(message, messageMetadata, messageMetadata.weight)
-			if float64(messageMetadata.weight) >= 50 && messageMetadata.confirmationTime.IsZero() {
-				messageMetadata.confirmationTime = time.Now()
+			if !config.CoordinatorEnabled && a.confirmationRule.MessageConfirmable(message, messageMetadata) && messageMetadata.confirmationTime.IsZero() {
+				messageMetadata.confirmationTime = TimeSource.Now()
 				a.Events.MessageConfirmed.Trigger(message, messageMetadata, messageMetadata.weight, messageIDCounter)
 			}
 		}
 		weight += 1
 		messageMetadata.weight += uint64(weight)
+		a.updateGrades(message, messageMetadata)
 		for strongParentID := range message.StrongParents {
 			walker.Push(strongParentID)
 		}
+		// a weak reference approves the referenced message itself but not its past cone, so weak
+		// parents receive the weight without being walked any further
+		for weakParentID := range message.WeakParents {
+			a.approveWeakParent(weakParentID, uint64(weight))
+		}
+		// a shallow like approves the liked message the same way, the opinion it expresses is
+		// consumed by the OpinionManager via the booked color
+		for likeParentID := range message.LikeParents {
+			a.approveWeakParent(likeParentID, uint64(weight))
+		}
+	}, NewMessageIDs(messageID), false)
+}
+
+// approveWeakParent applies the approval weight of a weak reference to the referenced message only.
+func (a *ApprovalManager) approveWeakParent(messageID MessageID, weight uint64) {
+	message := a.tangle.Storage.Message(messageID)
+	messageMetadata := a.tangle.Storage.MessageMetadata(messageID)
+	if message == nil || messageMetadata == nil {
+		return
+	}
+
+	messageMetadata.weight += weight
+	a.updateGrades(message, messageMetadata)
+	a.Events.MessageWeightUpdated.Trigger(message, messageMetadata, messageMetadata.weight)
+	if !config.CoordinatorEnabled && a.confirmationRule.MessageConfirmable(message, messageMetadata) && messageMetadata.confirmationTime.IsZero() {
+		messageMetadata.confirmationTime = TimeSource.Now()
+		a.Events.MessageConfirmed.Trigger(message, messageMetadata, messageMetadata.weight, messageIDCounter)
+	}
+}
+
+// updateGrades records the confirmation grades the message's witness weight has newly crossed and
+// triggers MessageGraded for each of them. The grade thresholds are the ConfirmationGrades fractions
+// applied to the same scale the binary confirmation rule uses.
+func (a *ApprovalManager) updateGrades(message *Message, messageMetadata *MessageMetadata) {
+	if len(config.ConfirmationGrades) == 0 {
+		return
+	}
+	if messageMetadata.gradeTimes == nil {
+		messageMetadata.gradeTimes = make([]time.Time, len(config.ConfirmationGrades))
+	}
+
+	for i, fraction := range config.ConfirmationGrades {
+		if !messageMetadata.gradeTimes[i].IsZero() || float64(messageMetadata.weight) < fraction*100 {
+			continue
+		}
+		messageMetadata.gradeTimes[i] = TimeSource.Now()
+		a.Events.MessageGraded.Trigger(message, messageMetadata, i+1, messageMetadata.weight, messageIDCounter)
+	}
+}
+
+// ConfirmMilestoneCone implements the coordinator-based confirmation rule: a milestone confirms its
+// entire past cone. The approval weight of the messages is still tracked so both finality notions can
+// be compared under the same traffic, but it plays no role for confirmation.
+func (a *ApprovalManager) ConfirmMilestoneCone(messageID MessageID) {
+	if milestone := a.tangle.Storage.Message(messageID); milestone.MilestoneIndex == 0 {
+		return
+	}
+
+	a.tangle.Utils.WalkMessagesAndMetadata(func(message *Message, messageMetadata *MessageMetadata, walker *walker.Walker) {
+		if message == nil || !messageMetadata.confirmationTime.IsZero() {
+			return
+		}
+		messageMetadata.confirmationTime = TimeSource.Now()
+		a.Events.MessageConfirmed.Trigger(message, messageMetadata, messageMetadata.weight, messageIDCounter)
+
+		// the walk prunes at already confirmed messages, their past cone is confirmed transitively
+		for strongParentID := range message.StrongParents {
+			walker.Push(strongParentID)
+		}
+		for weakParentID := range message.WeakParents {
+			walker.Push(weakParentID)
+		}
 	}, NewMessageIDs(messageID), false)
 }
 
@@ -101,6 +192,7 @@ func (a *ApprovalManager) ApproveMessages(messageID MessageID) {
 
 type ApprovalWeightEvents struct {
 	MessageConfirmed            *events.Event
+	MessageGraded               *events.Event
 	MessageWeightUpdated        *events.Event
 	MessageWitnessWeightUpdated *events.Event
 }
@@ -44,15 +44,38 @@ func (b *Booker) Book(messageID MessageID) {
 	b.Events.MessageBooked.Trigger(messageID)
 }
 
-// The booked message will inherit the color from its parent
+// The booked message will inherit the color from its parent. A shallow like expresses the issuer's
+// opinion on a conflict explicitly, so the color of a like parent takes precedence over the branch a
+// strong parent would pull the message into.
 func (b *Booker) inheritColor(message *Message) (inheritedColor Color, err error) {
 	inheritedColor = message.Payload
+
+	likedConflictSets := make(map[ConflictSetID]bool)
+	for _, colorToInherit := range b.colorsOfLikeParents(message) {
+		if colorToInherit == UndefinedColor {
+			continue
+		}
+		likedConflictSets[colorToInherit.ConflictSet()] = true
+
+		if inheritedColor != UndefinedColor && inheritedColor != colorToInherit {
+			err = xerrors.Errorf("message with %s tried to combine conflicting perceptions of the ledger state: %w", message.ID, cerrors.ErrFatal)
+			return
+		}
+
+		inheritedColor = colorToInherit
+	}
+
 	for _, colorToInherit := range append(make([]Color, 0), b.colorsOfStrongParents(message)...) {
 		if colorToInherit == UndefinedColor {
 			continue
 		}
 
 		if inheritedColor != UndefinedColor && inheritedColor != colorToInherit {
+			if likedConflictSets[colorToInherit.ConflictSet()] {
+				// the shallow like overrides the branch of the strong parent
+				continue
+			}
+
 			err = xerrors.Errorf("message with %s tried to combine conflicting perceptions of the ledger state: %w", message.ID, cerrors.ErrFatal)
 			return
 		}
@@ -75,6 +98,18 @@ func (b *Booker) colorsOfStrongParents(message *Message) (colorsOfStrongParents
 	return
 }
 
+func (b *Booker) colorsOfLikeParents(message *Message) (colorsOfLikeParents []Color) {
+	for likeParent := range message.LikeParents {
+		if likeParent == Genesis {
+			continue
+		}
+
+		colorsOfLikeParents = append(colorsOfLikeParents, b.tangle.Storage.MessageMetadata(likeParent).InheritedColor())
+	}
+
+	return
+}
+
 func (b *Booker) colorsOfWeakParents(message *Message) (colorsOfStrongParents []Color) {
 	for weakParent := range message.WeakParents {
 		if weakParent == Genesis {
@@ -0,0 +1,77 @@
+package multiverse
+
+import (
+	"time"
+
+	"github.com/iotaledger/multivers-simulation/config"
+)
+
+// region ConfirmationRule /////////////////////////////////////////////////////////////////////////////////////////////
+
+// ConfirmationRule decides whether the accumulated witness weight of a message suffices for
+// confirmation; the ApprovalManager evaluates it on every weight update of an unconfirmed message.
+type ConfirmationRule interface {
+	MessageConfirmable(message *Message, messageMetadata *MessageMetadata) bool
+}
+
+// ConfirmationRuleFromString returns the ConfirmationRule implementation matching the config string,
+// the witness weight fraction rule for unknown strings.
+func ConfirmationRuleFromString(rule string) ConfirmationRule {
+	switch rule {
+	case "AW":
+		return AbsoluteWeight{}
+	case "Milestone":
+		return MilestoneOnly{}
+	case "Hybrid":
+		return NewHybrid()
+	default:
+		return WitnessWeight{}
+	}
+}
+
+// WitnessWeight confirms a message once its witness weight crosses the ConfirmationWWFraction of the
+// witness weight scale, the same scale the confirmation grades are relative to.
+type WitnessWeight struct{}
+
+func (WitnessWeight) MessageConfirmable(message *Message, messageMetadata *MessageMetadata) bool {
+	return float64(messageMetadata.weight) >= config.ConfirmationWWFraction*100
+}
+
+// AbsoluteWeight confirms a message once its witness weight crosses the fixed
+// ConfirmationAbsoluteWeight, independent of any scale.
+type AbsoluteWeight struct{}
+
+func (AbsoluteWeight) MessageConfirmable(message *Message, messageMetadata *MessageMetadata) bool {
+	return messageMetadata.weight >= uint64(config.ConfirmationAbsoluteWeight)
+}
+
+// MilestoneOnly never confirms through witness weight; confirmation happens exclusively through the
+// milestone cone of the coordinator, see ApprovalManager.ConfirmMilestoneCone.
+type MilestoneOnly struct{}
+
+func (MilestoneOnly) MessageConfirmable(message *Message, messageMetadata *MessageMetadata) bool {
+	return false
+}
+
+// Hybrid confirms a message once its witness weight crosses the ConfirmationWWFraction and the message
+// is at least ConfirmationMinAge old, so even heavily witnessed messages stay unconfirmed until they
+// matured. A message crossing the weight threshold early is confirmed by the first weight update after
+// it matures.
+type Hybrid struct {
+	minAge time.Duration
+}
+
+func NewHybrid() Hybrid {
+	minAge := time.Duration(config.ConfirmationMinAge) * time.Second
+	if !config.DiscreteEvent {
+		minAge *= time.Duration(config.SlowdownFactor)
+	}
+	return Hybrid{minAge: minAge}
+}
+
+func (h Hybrid) MessageConfirmable(message *Message, messageMetadata *MessageMetadata) bool {
+	return float64(messageMetadata.weight) >= config.ConfirmationWWFraction*100 &&
+		TimeSource.Now().Sub(message.IssuanceTime) >= h.minAge
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
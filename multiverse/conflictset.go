@@ -0,0 +1,54 @@
+package multiverse
+
+import (
+	"github.com/iotaledger/multivers-simulation/config"
+)
+
+// region ConflictSet //////////////////////////////////////////////////////////////////////////////////////////////////
+
+// A ConflictSetID identifies one independent conflict: a group of config.ConflictColorCount colors of
+// which at most one can ever win. With config.ConflictSetCount > 1 several unrelated double spends are
+// live at the same time and the OpinionManager forms one opinion per conflict set, so the interference
+// between concurrent conflicts becomes observable.
+type ConflictSetID int
+
+// NoConflictSet is the conflict set of UndefinedColor, which conflicts with nothing.
+const NoConflictSet = ConflictSetID(-1)
+
+// ConflictSet returns the conflict set the color belongs to. The defined colors are partitioned into
+// consecutive runs of config.ConflictColorCount, so colors of different runs never conflict with each
+// other.
+func (c Color) ConflictSet() ConflictSetID {
+	if c == UndefinedColor {
+		return NoConflictSet
+	}
+	return ConflictSetID((int(c) - 1) / config.ConflictColorCount)
+}
+
+// ConflictSets returns the identifiers of all configured conflict sets, in ascending order.
+func ConflictSets() (sets []ConflictSetID) {
+	sets = make([]ConflictSetID, 0, config.ConflictSetCount)
+	for i := 0; i < config.ConflictSetCount; i++ {
+		sets = append(sets, ConflictSetID(i))
+	}
+	return
+}
+
+// ConflictSetColors returns the colors of the given conflict set, in ascending order.
+func ConflictSetColors(set ConflictSetID) (colors []Color) {
+	if set == NoConflictSet {
+		return
+	}
+	colors = make([]Color, 0, config.ConflictColorCount)
+	for i := 1; i <= config.ConflictColorCount; i++ {
+		colors = append(colors, Color(int(set)*config.ConflictColorCount+i))
+	}
+	return
+}
+
+// totalConflictColors returns the number of defined colors across all conflict sets.
+func totalConflictColors() int {
+	return config.ConflictColorCount * config.ConflictSetCount
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
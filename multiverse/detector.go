@@ -0,0 +1,105 @@
+package multiverse
+
+import (
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/events"
+
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region Detector /////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// Detector maintains the per-node counters behind attacker-detection heuristics: every stored message
+// is checked against what the node has seen from its issuer so far. The counters are dumped to
+// dt-*.csv when DetectionMetrics is enabled. They are heuristics, not proofs - an honest node trips
+// the equivocation counter too when it switches to the leading branch on a legitimate opinion change.
+type Detector struct {
+	tangle *Tangle
+
+	issuerStates          map[network.PeerID]*issuerState
+	equivocations         int64
+	overRateMessages      int64
+	conflictingTimestamps int64
+	mutex                 sync.Mutex
+}
+
+// issuerState remembers what the node has seen from one issuer so far.
+type issuerState struct {
+	lastColor     Color
+	lastTimestamp time.Time
+	firstSeen     time.Time
+	messageCount  int64
+}
+
+func NewDetector(tangle *Tangle) *Detector {
+	return &Detector{
+		tangle:       tangle,
+		issuerStates: make(map[network.PeerID]*issuerState),
+	}
+}
+
+func (d *Detector) Setup() {
+	if !config.DetectionMetrics {
+		return
+	}
+	d.tangle.Storage.Events.MessageStored.Attach(events.NewClosure(d.inspect))
+}
+
+// inspect compares the stored message with the issuer's history: a switch between defined payload
+// colors counts as an equivocation, a message rate above DetectionOverRateTolerance times the
+// issuer's mana-allowed share of the network throughput counts as over-rate and an issuance time
+// behind an earlier message of the same issuer counts as a conflicting timestamp.
+func (d *Detector) inspect(messageID MessageID) {
+	message := d.tangle.Storage.Message(messageID)
+	now := TimeSource.Now()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	state, seen := d.issuerStates[message.Issuer]
+	if !seen {
+		state = &issuerState{
+			lastColor:     UndefinedColor,
+			lastTimestamp: message.IssuanceTime,
+			firstSeen:     now,
+		}
+		d.issuerStates[message.Issuer] = state
+	}
+	state.messageCount++
+
+	if message.Payload != UndefinedColor {
+		if state.lastColor != UndefinedColor && state.lastColor != message.Payload {
+			d.equivocations++
+		}
+		state.lastColor = message.Payload
+	}
+
+	if message.IssuanceTime.Before(state.lastTimestamp) {
+		d.conflictingTimestamps++
+	} else {
+		state.lastTimestamp = message.IssuanceTime
+	}
+
+	if elapsed := now.Sub(state.firstSeen); elapsed > time.Second {
+		allowedRate := float64(d.tangle.WeightDistribution.Weight(message.Issuer)) / float64(config.NodesTotalWeight) * float64(config.TPS)
+		// realtime runs stretch the issuance by the slowdown factor, the virtual clock does not
+		if !config.DiscreteEvent {
+			allowedRate /= float64(config.SlowdownFactor)
+		}
+		if float64(state.messageCount)/elapsed.Seconds() > config.DetectionOverRateTolerance*allowedRate {
+			d.overRateMessages++
+		}
+	}
+}
+
+// Counters returns the detection counters the node has accumulated so far.
+func (d *Detector) Counters() (equivocations, overRateMessages, conflictingTimestamps int64) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.equivocations, d.overRateMessages, d.conflictingTimestamps
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
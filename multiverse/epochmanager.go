@@ -0,0 +1,102 @@
+package multiverse
+
+import (
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/events"
+
+	"github.com/iotaledger/multivers-simulation/config"
+)
+
+// region EpochManager /////////////////////////////////////////////////////////////////////////////////
+
+// EpochIndex identifies one epoch, the EpochDuration-long interval of issuance time it covers. Epoch 0
+// starts at the beginning of the run, see ResetEpochs.
+type EpochIndex int64
+
+// epochGenesis anchors epoch 0; resetRunState re-anchors it at the start of every run.
+var epochGenesis = time.Now()
+
+// ResetEpochs anchors epoch 0 at the current simulation time; called at the start of every run.
+func ResetEpochs() {
+	epochGenesis = TimeSource.Now()
+}
+
+// CurrentEpoch returns the epoch the current simulation time falls into. Epochs below it are sealed:
+// no honestly timestamped message can be added to them anymore.
+func CurrentEpoch() EpochIndex {
+	return epochOf(TimeSource.Now())
+}
+
+func epochOf(t time.Time) EpochIndex {
+	if !t.After(epochGenesis) {
+		return 0
+	}
+	return EpochIndex(t.Sub(epochGenesis) / epochDuration())
+}
+
+func epochDuration() time.Duration {
+	duration := time.Duration(config.EpochDuration) * time.Second
+	// realtime runs stretch the issuance by the slowdown factor, the virtual clock does not
+	if !config.DiscreteEvent {
+		duration *= time.Duration(config.SlowdownFactor)
+	}
+	return duration
+}
+
+// EpochManager groups the confirmed messages of the node's tangle into epochs by their issuance time
+// and maintains one commitment per epoch. The commitment is an order-independent digest of the
+// confirmed content, so two nodes committed to the same epoch content exactly when their commitments
+// match, regardless of the order the confirmations happened in. The divergence of the commitments
+// across nodes is dumped to ep-*.csv when EpochCommitments is enabled.
+type EpochManager struct {
+	tangle *Tangle
+
+	commitments map[EpochIndex]uint64
+	mutex       sync.Mutex
+}
+
+func NewEpochManager(tangle *Tangle) *EpochManager {
+	return &EpochManager{
+		tangle:      tangle,
+		commitments: make(map[EpochIndex]uint64),
+	}
+}
+
+func (e *EpochManager) Setup() {
+	if !config.EpochCommitments {
+		return
+	}
+	e.tangle.ApprovalManager.Events.MessageConfirmed.Attach(events.NewClosure(
+		func(message *Message, messageMetadata *MessageMetadata, weight uint64, messageIDCounter int64) {
+			e.commit(message)
+		}))
+}
+
+// commit folds the confirmed message into the commitment of the epoch its issuance time falls into.
+func (e *EpochManager) commit(message *Message) {
+	epoch := epochOf(message.IssuanceTime)
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.commitments[epoch] ^= mixMessageID(message.ID)
+}
+
+// Commitment returns the commitment of the given epoch, 0 while the node has confirmed nothing in it.
+func (e *EpochManager) Commitment(epoch EpochIndex) uint64 {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.commitments[epoch]
+}
+
+// mixMessageID spreads the message ID over all 64 bits (the splitmix64 finalizer) so that XOR-folding
+// the IDs of an epoch into its commitment does not collide for similar ID sets.
+func mixMessageID(id MessageID) uint64 {
+	z := uint64(id) + 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,70 @@
+package multiverse
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region Genesis set //////////////////////////////////////////////////////////////////////////////////////////////////
+
+// GenesisMessage is one preassigned starting message of the tangle: every node begins with the same
+// colored genesis messages as attachment points, so a run can start mid-conflict instead of from the
+// single uncolored Genesis.
+type GenesisMessage struct {
+	ID     MessageID
+	Color  Color
+	Weight uint64
+}
+
+// BuildGenesisSet allocates the message IDs of the configured genesis messages, one per
+// "color:weight" entry of GenesisMessages. The IDs are global like all message IDs, so the set must
+// be built once and applied to every node's tangle.
+func BuildGenesisSet() (genesisSet []GenesisMessage) {
+	for _, entry := range config.GenesisMessages {
+		// the entries were validated upfront, see the config validation
+		parts := strings.Split(entry, ":")
+		weight, _ := strconv.ParseUint(parts[1], 10, 64)
+		genesisSet = append(genesisSet, GenesisMessage{
+			ID:     NewMessageID(),
+			Color:  ColorFromStr(parts[0]),
+			Weight: weight,
+		})
+	}
+	return
+}
+
+// ApplyGenesisSet injects the genesis messages into the tangle: each one is stored solid with its
+// preassigned color, seeds the approval weight of that branch and becomes an initial tip of the
+// matching tip set, so the node's first own messages already attach mid-conflict. The genesis
+// messages have no issuer.
+func (t *Tangle) ApplyGenesisSet(genesisSet []GenesisMessage) {
+	for _, genesisMessage := range genesisSet {
+		message := &Message{
+			ID:            genesisMessage.ID,
+			StrongParents: NewMessageIDs(Genesis),
+			WeakParents:   NewMessageIDs(),
+			LikeParents:   NewMessageIDs(),
+			Issuer:        network.PeerID(-1),
+			Payload:       genesisMessage.Color,
+			IssuanceTime:  TimeSource.Now(),
+		}
+		metadata := &MessageMetadata{
+			id:             genesisMessage.ID,
+			solid:          true,
+			inheritedColor: genesisMessage.Color,
+			weight:         genesisMessage.Weight,
+			weightSlice:    make([]byte, int(math.Ceil(float64(config.NodesCount)/8.0))),
+		}
+		t.Storage.storeSnapshot(message, metadata)
+		t.OpinionManager.SeedApprovalWeight(genesisMessage.Color, genesisMessage.Weight)
+		t.TipManager.AnalyzeMessage(genesisMessage.ID)
+	}
+
+	t.OpinionManager.WeightsUpdated()
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,123 @@
+package multiverse
+
+import (
+	"sync/atomic"
+
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region MessageInventory /////////////////////////////////////////////////////////////////////////////////////////////
+
+// MessageInventory is the IHAVE announcement of the pull gossip mode: the IDs of the messages its
+// Issuer booked since the last announcement. A receiver requests the announced messages it is missing
+// back from the Issuer, like an IWANT.
+type MessageInventory struct {
+	MessageIDs []MessageID
+	Issuer     network.PeerID
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// region Gossip statistics ////////////////////////////////////////////////////////////////////////////////////////////
+
+var (
+	receivedMessageCounter  int64
+	duplicateMessageCounter int64
+)
+
+// countReceivedMessage accounts an incoming message for the gossip statistics.
+func countReceivedMessage(duplicate bool) {
+	atomic.AddInt64(&receivedMessageCounter, 1)
+	if duplicate {
+		atomic.AddInt64(&duplicateMessageCounter, 1)
+	}
+}
+
+// GossipStatistics returns how many messages the nodes received over gossip in total and how many of
+// them were duplicates of already known messages, quantifying the bandwidth overhead of the configured
+// gossip mode.
+func GossipStatistics() (received int64, duplicates int64) {
+	return atomic.LoadInt64(&receivedMessageCounter), atomic.LoadInt64(&duplicateMessageCounter)
+}
+
+// ResetGossipStatistics zeroes the gossip statistics of a previous Run in the same process.
+func ResetGossipStatistics() {
+	atomic.StoreInt64(&receivedMessageCounter, 0)
+	atomic.StoreInt64(&duplicateMessageCounter, 0)
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// region Node gossip behavior /////////////////////////////////////////////////////////////////////////////////////////
+
+// SetGossipFilter installs a predicate that is consulted before a message is forwarded to the
+// neighbors or served from a MessageRequest; returning false suppresses the forwarding. Censoring
+// adversary nodes use it to keep messages of their victims out of their gossip.
+func (n *Node) SetGossipFilter(filter func(message *Message) bool) {
+	n.gossipFilter = filter
+}
+
+// SetGossipTargets installs a selector returning the subset of neighbors a message is forwarded to;
+// returning nil leaves the message to the configured gossip mode. Targeting adversary nodes use it
+// to direct their own messages at selected victims instead of the whole neighborhood.
+func (n *Node) SetGossipTargets(targets func(message *Message) []network.PeerID) {
+	n.gossipTargets = targets
+}
+
+// gossipMessage forwards a booked message to the neighbors according to the configured gossip mode.
+func (n *Node) gossipMessage(message *Message) {
+	if n.gossipFilter != nil && !n.gossipFilter(message) {
+		return
+	}
+	if n.gossipTargets != nil {
+		if targets := n.gossipTargets(message); targets != nil {
+			n.peer.GossipNetworkMessageToPeers(message, targets)
+			return
+		}
+	}
+
+	switch config.GossipMode {
+	case "fanout":
+		n.peer.GossipNetworkMessageToFanout(message, config.GossipFanout)
+	case "pull":
+		n.peer.GossipNetworkMessageToFanout(message, config.GossipFanout)
+		n.inventoryMutex.Lock()
+		n.pendingInventory = append(n.pendingInventory, message.ID)
+		n.inventoryMutex.Unlock()
+	default:
+		n.peer.GossipNetworkMessage(message)
+	}
+}
+
+// FlushInventory announces the messages booked since the last announcement to all neighbors, so that
+// the pull gossip mode recovers whatever the limited-fanout push did not deliver.
+func (n *Node) FlushInventory() {
+	n.inventoryMutex.Lock()
+	messageIDs := n.pendingInventory
+	n.pendingInventory = nil
+	n.inventoryMutex.Unlock()
+
+	if len(messageIDs) == 0 {
+		return
+	}
+
+	n.peer.GossipNetworkMessage(&MessageInventory{MessageIDs: messageIDs, Issuer: n.peer.ID})
+}
+
+// processInventory requests the announced messages this node is missing back from the announcing
+// neighbor.
+func (n *Node) processInventory(inventory *MessageInventory) {
+	neighborConnection, connected := n.peer.Neighbors[inventory.Issuer]
+	if !connected {
+		return
+	}
+
+	for _, messageID := range inventory.MessageIDs {
+		if n.tangle.Storage.Message(messageID) == nil {
+			neighborConnection.Send(&MessageRequest{MessageID: messageID, Issuer: n.peer.ID})
+		}
+	}
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
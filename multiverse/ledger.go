@@ -0,0 +1,227 @@
+package multiverse
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/types"
+	"github.com/iotaledger/multivers-simulation/config"
+)
+
+// region UTXO model ///////////////////////////////////////////////////////////////////////////////////////////////////
+
+type TransactionID int64
+
+type OutputID int64
+
+// A Transaction spends its inputs and creates fresh outputs. Messages optionally carry a transaction
+// when config.LedgerEnabled is set; conflicts then arise from actual double spends of an output
+// instead of being injected as colors.
+type Transaction struct {
+	ID      TransactionID
+	Inputs  []OutputID
+	Outputs []OutputID
+
+	// Color of the conflict the transaction is part of, UndefinedColor while nothing else spends its
+	// inputs. It is allocated by the spend registry in NewTransaction, so conflicting transactions must
+	// be created before their messages are issued.
+	Color Color
+}
+
+var (
+	transactionIDCounter int64
+	outputIDCounter      int64
+
+	// the spend registry notices double spends at transaction creation time and maps every conflict
+	// onto the colors of a fresh conflict set, so the existing opinion machinery applies unchanged
+	spendRegistryMutex sync.Mutex
+	registeredSpenders = make(map[OutputID][]*Transaction)
+	outputConflictSets = make(map[OutputID]ConflictSetID)
+	conflictSetIndex   int
+)
+
+// NewOutput creates a fresh unspent output.
+func NewOutput() OutputID {
+	return OutputID(atomic.AddInt64(&outputIDCounter, 1))
+}
+
+// NewTransaction creates a transaction spending the given outputs and creating one fresh output. When
+// an input is already spent by another transaction, all its spenders are assigned the colors of a
+// conflict set, making the double spend visible to the opinion machinery.
+func NewTransaction(inputs ...OutputID) (tx *Transaction) {
+	tx = &Transaction{
+		ID:      TransactionID(atomic.AddInt64(&transactionIDCounter, 1)),
+		Inputs:  inputs,
+		Outputs: []OutputID{NewOutput()},
+	}
+
+	spendRegistryMutex.Lock()
+	defer spendRegistryMutex.Unlock()
+	for _, input := range inputs {
+		spenders := append(registeredSpenders[input], tx)
+		registeredSpenders[input] = spenders
+		if len(spenders) < 2 {
+			continue
+		}
+
+		set, allocated := outputConflictSets[input]
+		if !allocated {
+			set = ConflictSetID(conflictSetIndex % config.ConflictSetCount)
+			conflictSetIndex++
+			outputConflictSets[input] = set
+		}
+		colors := ConflictSetColors(set)
+		for i, spender := range spenders {
+			if spender.Color == UndefinedColor {
+				spender.Color = colors[i%len(colors)]
+			}
+		}
+	}
+
+	return
+}
+
+// ResetLedger clears the global spend registry between simulation runs in the same process.
+func ResetLedger() {
+	spendRegistryMutex.Lock()
+	defer spendRegistryMutex.Unlock()
+	atomic.StoreInt64(&transactionIDCounter, 0)
+	atomic.StoreInt64(&outputIDCounter, 0)
+	registeredSpenders = make(map[OutputID][]*Transaction)
+	outputConflictSets = make(map[OutputID]ConflictSetID)
+	conflictSetIndex = 0
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// region Ledger ///////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// The Ledger tracks the transactions carried by the messages of a node's tangle. It detects double
+// spends from the spends themselves and confirms a transaction when its carrying message is confirmed,
+// unless a conflicting spender was confirmed first. It only attaches to the tangle when
+// config.LedgerEnabled is set.
+type Ledger struct {
+	tangle *Tangle
+	Events *LedgerEvents
+
+	mutex        sync.Mutex
+	transactions map[TransactionID]*Transaction
+	spenders     map[OutputID]map[TransactionID]types.Empty
+	conflicting  map[TransactionID]types.Empty
+	confirmed    map[TransactionID]types.Empty
+}
+
+func NewLedger(tangle *Tangle) *Ledger {
+	return &Ledger{
+		tangle: tangle,
+		Events: &LedgerEvents{
+			DoubleSpendDetected:  events.NewEvent(doubleSpendEventHandler),
+			TransactionConfirmed: events.NewEvent(transactionEventHandler),
+		},
+		transactions: make(map[TransactionID]*Transaction),
+		spenders:     make(map[OutputID]map[TransactionID]types.Empty),
+		conflicting:  make(map[TransactionID]types.Empty),
+		confirmed:    make(map[TransactionID]types.Empty),
+	}
+}
+
+func (l *Ledger) Setup() {
+	if !config.LedgerEnabled {
+		return
+	}
+
+	l.tangle.Storage.Events.MessageStored.Attach(events.NewClosure(l.bookTransaction))
+	l.tangle.ApprovalManager.Events.MessageConfirmed.Attach(events.NewClosure(
+		func(message *Message, messageMetadata *MessageMetadata, weight uint64, messageIDCounter int64) {
+			l.confirmTransaction(message)
+		}))
+}
+
+// bookTransaction records the transaction carried by the stored message and flags every spender of a
+// now doubly spent output as conflicting.
+func (l *Ledger) bookTransaction(messageID MessageID) {
+	message := l.tangle.Storage.Message(messageID)
+	tx := message.Transaction
+	if tx == nil {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if _, seen := l.transactions[tx.ID]; seen {
+		return
+	}
+	l.transactions[tx.ID] = tx
+
+	for _, input := range tx.Inputs {
+		spenders, spent := l.spenders[input]
+		if !spent {
+			spenders = make(map[TransactionID]types.Empty)
+			l.spenders[input] = spenders
+		}
+		spenders[tx.ID] = types.Void
+
+		if len(spenders) < 2 {
+			continue
+		}
+		for spenderID := range spenders {
+			if _, flagged := l.conflicting[spenderID]; !flagged {
+				l.conflicting[spenderID] = types.Void
+			}
+		}
+		l.Events.DoubleSpendDetected.Trigger(input, tx.ID)
+	}
+}
+
+// confirmTransaction marks the transaction of the confirmed message as confirmed, unless a conflicting
+// spender of one of its inputs was confirmed first.
+func (l *Ledger) confirmTransaction(message *Message) {
+	tx := message.Transaction
+	if tx == nil {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if _, alreadyConfirmed := l.confirmed[tx.ID]; alreadyConfirmed {
+		return
+	}
+	for _, input := range tx.Inputs {
+		for spenderID := range l.spenders[input] {
+			if _, conflictConfirmed := l.confirmed[spenderID]; conflictConfirmed && spenderID != tx.ID {
+				return
+			}
+		}
+	}
+
+	l.confirmed[tx.ID] = types.Void
+	l.Events.TransactionConfirmed.Trigger(tx.ID)
+}
+
+// TransactionCounts returns how many transactions the node has seen, how many of them are part of a
+// double spend and how many are confirmed.
+func (l *Ledger) TransactionCounts() (seen int64, conflicting int64, confirmed int64) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return int64(len(l.transactions)), int64(len(l.conflicting)), int64(len(l.confirmed))
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// region LedgerEvents /////////////////////////////////////////////////////////////////////////////////////////////////
+
+type LedgerEvents struct {
+	DoubleSpendDetected  *events.Event
+	TransactionConfirmed *events.Event
+}
+
+func doubleSpendEventHandler(handler interface{}, params ...interface{}) {
+	handler.(func(OutputID, TransactionID))(params[0].(OutputID), params[1].(TransactionID))
+}
+
+func transactionEventHandler(handler interface{}, params ...interface{}) {
+	handler.(func(TransactionID))(params[0].(TransactionID))
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,158 @@
+package multiverse
+
+import (
+	"github.com/iotaledger/hive.go/datastructure/walker"
+	"github.com/iotaledger/hive.go/types"
+	"github.com/iotaledger/multivers-simulation/config"
+)
+
+// region Marker-based approval weight /////////////////////////////////////////////////////////////////////////////////
+
+// The marker-based mode propagates approval weight only between marker messages instead of walking
+// the full past cone of every solid message: a message approves its nearest markers, a marker
+// approves the markers nearest before it, and the walk hops from marker to marker. Non-markers carry
+// no weight of their own and confirm through the past cone of the first confirmed marker after them,
+// like the milestone cone of the coordinator. Every message whose height is a multiple of
+// MarkerSpacing is a marker, so the cost per solid message shrinks by roughly that factor; the exact
+// walk stays available through the MarkerBasedAW flag for validating the approximation.
+
+// isMarker reports whether the message is a marker of the marker-based approval weight propagation.
+func (a *ApprovalManager) isMarker(message *Message) bool {
+	return message.height%config.MarkerSpacing == 0
+}
+
+// ApproveMessagesByMarkers is the marker-based counterpart of ApproveMessages: the walk starts at the
+// nearest markers of the newly solid message and only ever visits markers, applying the same weight
+// accounting the exact walk applies to every message of the past cone.
+func (a *ApprovalManager) ApproveMessagesByMarkers(messageID MessageID) {
+	count := 0
+	weight := 1
+	a.tangle.Utils.WalkMessagesAndMetadata(func(message *Message, messageMetadata *MessageMetadata, walker *walker.Walker) {
+		// pruned messages are a walk boundary, their weight can no longer change anything
+		if message == nil {
+			return
+		}
+		if int(a.tangle.Peer.ID) == config.MonitoredWitnessWeightPeer && messageMetadata.id == MessageID(config.MonitoredWitnessWeightMessageID) {
+			log.Infof("Peer %d Message %d Witness Weight %d", a.tangle.Peer.ID, messageMetadata.id, messageMetadata.weight)
+			a.Events.MessageWitnessWeightUpdated.Trigger(message, messageMetadata.weight)
+		}
+		if count <= weight {
+			count++
+			a.Events.MessageWeightUpdated.Trigger(message, messageMetadata, messageMetadata.weight)
+			if !config.CoordinatorEnabled && a.confirmationRule.MessageConfirmable(message, messageMetadata) && messageMetadata.confirmationTime.IsZero() {
+				a.confirmMarkerCone(messageMetadata.id)
+			}
+		}
+		weight += 1
+		messageMetadata.weight += uint64(weight)
+		a.updateGrades(message, messageMetadata)
+		for strongParentID := range message.StrongParents {
+			for markerID := range a.nearestMarkers(strongParentID) {
+				walker.Push(markerID)
+			}
+		}
+		// weak and shallow like references approve the referenced message but not its past cone, so
+		// their nearest markers receive the weight without the walk continuing past them
+		for weakParentID := range message.WeakParents {
+			for markerID := range a.nearestMarkers(weakParentID) {
+				a.approveMarkerWeakly(markerID, uint64(weight))
+			}
+		}
+		for likeParentID := range message.LikeParents {
+			for markerID := range a.nearestMarkers(likeParentID) {
+				a.approveMarkerWeakly(markerID, uint64(weight))
+			}
+		}
+	}, a.nearestMarkers(messageID), false)
+}
+
+// approveMarkerWeakly is the marker-based counterpart of approveWeakParent: the weight of a weak or
+// like reference lands on the referenced marker only, a confirmation it causes still confirms the
+// marker's past cone.
+func (a *ApprovalManager) approveMarkerWeakly(markerID MessageID, weight uint64) {
+	message := a.tangle.Storage.Message(markerID)
+	messageMetadata := a.tangle.Storage.MessageMetadata(markerID)
+	if message == nil || messageMetadata == nil {
+		return
+	}
+
+	messageMetadata.weight += weight
+	a.updateGrades(message, messageMetadata)
+	a.Events.MessageWeightUpdated.Trigger(message, messageMetadata, messageMetadata.weight)
+	if !config.CoordinatorEnabled && a.confirmationRule.MessageConfirmable(message, messageMetadata) && messageMetadata.confirmationTime.IsZero() {
+		a.confirmMarkerCone(markerID)
+	}
+}
+
+// confirmMarkerCone confirms the marker and its entire unconfirmed past cone, the way a milestone
+// does; this is how the non-markers, which carry no weight of their own, reach confirmation.
+func (a *ApprovalManager) confirmMarkerCone(markerID MessageID) {
+	a.tangle.Utils.WalkMessagesAndMetadata(func(message *Message, messageMetadata *MessageMetadata, walker *walker.Walker) {
+		if message == nil || !messageMetadata.confirmationTime.IsZero() {
+			return
+		}
+		messageMetadata.confirmationTime = TimeSource.Now()
+		a.Events.MessageConfirmed.Trigger(message, messageMetadata, messageMetadata.weight, messageIDCounter)
+
+		// the walk prunes at already confirmed messages, their past cone is confirmed transitively
+		for strongParentID := range message.StrongParents {
+			walker.Push(strongParentID)
+		}
+		for weakParentID := range message.WeakParents {
+			walker.Push(weakParentID)
+		}
+	}, NewMessageIDs(markerID), false)
+}
+
+// nearestMarkers returns the nearest markers at or before the message: the message itself if it is a
+// marker, the union of the nearest markers of its strong parents otherwise. The sets are memoized in
+// the message metadata; since parents turn solid before their children the parents are usually
+// resolved already, but the resolution walks unresolved stretches (e.g. after a snapshot import)
+// iteratively. Pruned parents are a boundary like everywhere else.
+func (a *ApprovalManager) nearestMarkers(messageID MessageID) MessageIDs {
+	pending := []MessageID{messageID}
+	for len(pending) > 0 {
+		currentID := pending[len(pending)-1]
+		currentMetadata := a.tangle.Storage.MessageMetadata(currentID)
+		if currentMetadata == nil || currentMetadata.nearestMarkers != nil {
+			pending = pending[:len(pending)-1]
+			continue
+		}
+
+		currentMessage := a.tangle.Storage.Message(currentID)
+		if a.isMarker(currentMessage) {
+			currentMetadata.nearestMarkers = NewMessageIDs(currentID)
+			pending = pending[:len(pending)-1]
+			continue
+		}
+
+		unresolvedParents := false
+		for parentID := range currentMessage.StrongParents {
+			if parentMetadata := a.tangle.Storage.MessageMetadata(parentID); parentMetadata != nil && parentMetadata.nearestMarkers == nil {
+				pending = append(pending, parentID)
+				unresolvedParents = true
+			}
+		}
+		if unresolvedParents {
+			continue
+		}
+
+		markers := NewMessageIDs()
+		for parentID := range currentMessage.StrongParents {
+			if parentMetadata := a.tangle.Storage.MessageMetadata(parentID); parentMetadata != nil {
+				for markerID := range parentMetadata.nearestMarkers {
+					markers[markerID] = types.Void
+				}
+			}
+		}
+		currentMetadata.nearestMarkers = markers
+		pending = pending[:len(pending)-1]
+	}
+
+	if metadata := a.tangle.Storage.MessageMetadata(messageID); metadata != nil {
+		return metadata.nearestMarkers
+	}
+	return NewMessageIDs()
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -2,14 +2,38 @@ package multiverse
 
 import (
 	"sync/atomic"
-	"time"
+
+	"github.com/iotaledger/multivers-simulation/config"
 )
 
+// region Weak reference statistics ////////////////////////////////////////////////////////////////////////////////////
+
+var weakReferenceCounter int64
+
+// countWeakReferences accounts the weak parents of a locally created message.
+func countWeakReferences(count int) {
+	atomic.AddInt64(&weakReferenceCounter, int64(count))
+}
+
+// WeakReferenceStatistics returns how many weak parent references the nodes put into their messages in
+// total, quantifying how often the WeakTipsRatio actually takes effect.
+func WeakReferenceStatistics() (weakReferences int64) {
+	return atomic.LoadInt64(&weakReferenceCounter)
+}
+
+// ResetWeakReferenceStatistics zeroes the weak reference statistics of a previous Run in the same process.
+func ResetWeakReferenceStatistics() {
+	atomic.StoreInt64(&weakReferenceCounter, 0)
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
+
 // region MessageFactory ///////////////////////////////////////////////////////////////////////////////////////////////
 
 type MessageFactory struct {
 	tangle         *Tangle
 	sequenceNumber uint64
+	milestoneIndex uint64
 	numberOfNodes  uint64
 }
 
@@ -21,8 +45,13 @@ func NewMessageFactory(tangle *Tangle, numberOfNodes uint64) (messageFactory *Me
 }
 
 func (m *MessageFactory) CreateMessage(payload Color) (message *Message) {
-	//strongParents, weakParents := m.tangle.TipManager.Tips()
-	strongParents := m.tangle.TipManager.Tips()
+	strongParents, weakParents := m.tangle.TipManager.Tips()
+	countWeakReferences(len(weakParents))
+
+	var likeParents MessageIDs
+	if config.LikeReferencesEnabled {
+		likeParents = m.tangle.TipManager.LikeTips()
+	}
 	parentheight := 0
 	// if strongParents.GetOne() != genesis {
 	// 	parentheight = getmessage(strongParents.GetOne()).height
@@ -53,15 +82,45 @@ func (m *MessageFactory) CreateMessage(payload Color) (message *Message) {
 	}
 
 	return &Message{
-		ID:            NewMessageID(),
-		StrongParents: strongParents,
-		//WeakParents:    weakParents,
+		ID:             NewMessageID(),
+		StrongParents:  strongParents,
+		WeakParents:    weakParents,
+		LikeParents:    likeParents,
 		height:         parentheight + 1,
 		SequenceNumber: atomic.AddUint64(&m.sequenceNumber, 1),
 		Issuer:         m.tangle.Peer.ID,
 		Payload:        payload,
-		IssuanceTime:   time.Now(),
+		IssuanceTime:   TimeSource.Now(),
+	}
+}
+
+// CreateMilestoneMessage creates the next milestone of the coordinator. A milestone is an ordinary
+// message except for its index; its confirmation power comes from the ApprovalManager.
+func (m *MessageFactory) CreateMilestoneMessage() (message *Message) {
+	message = m.CreateMessage(UndefinedColor)
+	message.MilestoneIndex = atomic.AddUint64(&m.milestoneIndex, 1)
+	return
+}
+
+// SkipSequenceNumbers raises the sequence number to at least the given value, so messages issued
+// after a snapshot import continue the imported sequence instead of being discarded as outdated
+// votes, see Tangle.ImportSnapshot.
+func (m *MessageFactory) SkipSequenceNumbers(sequenceNumber uint64) {
+	for {
+		current := atomic.LoadUint64(&m.sequenceNumber)
+		if current >= sequenceNumber || atomic.CompareAndSwapUint64(&m.sequenceNumber, current, sequenceNumber) {
+			return
+		}
 	}
 }
 
+// CreateTransactionMessage creates a message carrying the transaction. The message's payload is the
+// color of the conflict the transaction is part of, so conflicting spends reuse the existing opinion
+// machinery.
+func (m *MessageFactory) CreateTransactionMessage(tx *Transaction) (message *Message) {
+	message = m.CreateMessage(tx.Color)
+	message.Transaction = tx
+	return
+}
+
 // endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -1,6 +1,9 @@
 package multiverse
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -14,13 +17,24 @@ type Message struct {
 	ID             MessageID
 	StrongParents  MessageIDs
 	WeakParents    MessageIDs
+	LikeParents    MessageIDs
 	SequenceNumber uint64
 	height         int
 	Issuer         network.PeerID
 	Payload        Color
 	IssuanceTime   time.Time
+
+	// Transaction is the optional UTXO payload of the message, see Ledger. It stays nil unless
+	// config.LedgerEnabled is set.
+	Transaction *Transaction
+
+	// MilestoneIndex is non-zero for the milestones of the coordinator, see config.CoordinatorEnabled.
+	MilestoneIndex uint64
 }
 
+// MilestonePayload is the network marker the coordinator sends to itself to issue the next milestone.
+type MilestonePayload struct{}
+
 // endregion Message ///////////////////////////////////////////////////////////////////////////////////////////////////
 
 // region MessageMetadata //////////////////////////////////////////////////////////////////////////////////////////////
@@ -32,6 +46,20 @@ type MessageMetadata struct {
 	weightSlice      []byte
 	weight           uint64
 	confirmationTime time.Time
+	gradeTimes       []time.Time
+
+	// nearest markers at or before the message, memoized by the marker-based approval weight
+	// propagation; nil until resolved, see config.MarkerBasedAW
+	nearestMarkers MessageIDs
+}
+
+// GradeTime returns when the message reached the given confirmation grade (1-based), the zero time
+// while it has not, see config.ConfirmationGrades.
+func (m *MessageMetadata) GradeTime(grade int) time.Time {
+	if grade < 1 || grade > len(m.gradeTimes) {
+		return time.Time{}
+	}
+	return m.gradeTimes[grade-1]
 }
 
 func (m *MessageMetadata) WeightSlice() []byte {
@@ -156,9 +184,9 @@ func (m MessageIDs) Trim(length int) {
 // The maxOpinion is the Opinion with the highest Color value and the maxApprovalWeight
 //
 // The approvalWeights stores the accumulated weights of each Color for messages
-//    - The message will have an associated Color inherited from its parents
-//    - The Color of a message is assigned from `IssuePayload`
-//    - The strongTips/weakTips will be selected from the TipSet[ownOpinion]
+//   - The message will have an associated Color inherited from its parents
+//   - The Color of a message is assigned from `IssuePayload`
+//   - The strongTips/weakTips will be selected from the TipSet[ownOpinion]
 //
 // The different color values are used as a tie breaker, i.e., when 2 colors have the same weight, the larger color value
 // opinion will be regarded as the ownOpinion. Each color simply represents a perception of a certain state of a tangle
@@ -166,33 +194,44 @@ func (m MessageIDs) Trim(length int) {
 type Color int64
 
 func (c Color) String() string {
+	return fmt.Sprintf("Color(%s)", c.Name())
+}
+
+// Name returns the bare color name without the Color() wrapper, the form used in result headers.
+// The first three conflicting colors keep their historical names, further colors are numbered.
+func (c Color) Name() string {
 	switch c {
 	case 0:
-		return "Color(Undefined)"
+		return "Undefined"
 	case 1:
-		return "Color(Blue)"
+		return "Blue"
 	case 2:
-		return "Color(Red)"
+		return "Red"
 	case 3:
-		return "Color(Green)"
+		return "Green"
 	default:
-		return "Color(Unknown)"
+		if c > 3 && int64(c) <= int64(totalConflictColors()) {
+			return fmt.Sprintf("C%d", int64(c))
+		}
+		return "Unknown"
+	}
+}
+
+// ConflictingColors returns every defined color across all conflict sets, in ascending order and
+// without UndefinedColor.
+func ConflictingColors() (colors []Color) {
+	colors = make([]Color, 0, totalConflictColors())
+	for i := 1; i <= totalConflictColors(); i++ {
+		colors = append(colors, Color(i))
 	}
+	return
 }
 
 func ColorFromInt(i int) Color {
-	switch i {
-	case 0:
-		return UndefinedColor
-	case 1:
-		return Blue
-	case 2:
-		return Red
-	case 3:
-		return Green
-	default:
+	if i < 1 || i > totalConflictColors() {
 		return UndefinedColor
 	}
+	return Color(i)
 }
 
 func ColorFromStr(s string) Color {
@@ -206,6 +245,9 @@ func ColorFromStr(s string) Color {
 	case "G":
 		return Green
 	default:
+		if number, err := strconv.Atoi(strings.TrimPrefix(s, "C")); err == nil {
+			return ColorFromInt(number)
+		}
 		return UndefinedColor
 	}
 }
@@ -1,29 +1,71 @@
 package multiverse
 
 import (
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/multivers-simulation/config"
 	"github.com/iotaledger/multivers-simulation/logger"
 	"github.com/iotaledger/multivers-simulation/network"
 )
 
 var log = logger.New("Multiverse")
 
+// region Reattachment statistics //////////////////////////////////////////////////////////////////////////////////////
+
+var reattachmentCounter int64
+
+// countReattachment accounts one orphaned own message whose payload was reissued.
+func countReattachment() {
+	atomic.AddInt64(&reattachmentCounter, 1)
+}
+
+// ReattachmentStatistics returns how many orphaned messages the nodes reattached in total.
+func ReattachmentStatistics() (reattachments int64) {
+	return atomic.LoadInt64(&reattachmentCounter)
+}
+
+// ResetReattachmentStatistics zeroes the reattachment statistics of a previous Run in the same process.
+func ResetReattachmentStatistics() {
+	atomic.StoreInt64(&reattachmentCounter, 0)
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
+
 // region Node /////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 type NodeInterface interface {
 	Peer() *network.Peer
 	Tangle() *Tangle
 	IssuePayload(payload Color)
+	IssueTransaction(tx *Transaction)
+	IssueMilestone()
 }
 
 type Node struct {
 	peer   *network.Peer
 	tangle *Tangle
+
+	// messages booked since the last IHAVE announcement of the pull gossip mode, see FlushInventory
+	pendingInventory []MessageID
+	inventoryMutex   sync.Mutex
+
+	// optional censorship predicate, see SetGossipFilter
+	gossipFilter func(message *Message) bool
+
+	// optional restriction of the gossip destinations, see SetGossipTargets
+	gossipTargets func(message *Message) []network.PeerID
+
+	// own messages whose payload was already reissued, see ReattachOrphanedMessages
+	reattachedMessages map[MessageID]bool
 }
 
 func NewNode() interface{} {
 	return &Node{
-		tangle: NewTangle(),
+		tangle:             NewTangle(),
+		reattachedMessages: make(map[MessageID]bool),
 	}
 }
 
@@ -40,29 +82,108 @@ func (n *Node) Setup(peer *network.Peer, weightDistribution *network.ConsensusWe
 
 	n.peer = peer
 	n.tangle.Setup(peer, weightDistribution)
+
+	// when replaying a recorded trace the recorded events are the only source of messages, so neither
+	// gossip nor solidification requests must hit the network layer
+	if config.ReplayTrace != "" {
+		return
+	}
+
 	n.tangle.Requester.Events.Request.Attach(events.NewClosure(func(messageID MessageID) {
 		n.peer.GossipNetworkMessage(&MessageRequest{MessageID: messageID, Issuer: n.peer.ID})
 	}))
+	// with the scheduler enabled, booked messages wait in the per-issuer outbox queues and are
+	// gossiped in deficit round robin order instead of immediately
+	if config.SchedulerEnabled {
+		n.tangle.Booker.Events.MessageBooked.Attach(events.NewClosure(n.tangle.Scheduler.Enqueue))
+		n.tangle.Scheduler.Events.MessageScheduled.Attach(events.NewClosure(func(messageID MessageID) {
+			n.gossipMessage(n.tangle.Storage.Message(messageID))
+		}))
+		return
+	}
+
 	n.tangle.Booker.Events.MessageBooked.Attach(events.NewClosure(func(messageID MessageID) {
-		n.peer.GossipNetworkMessage(n.tangle.Storage.Message(messageID))
+		n.gossipMessage(n.tangle.Storage.Message(messageID))
 	}))
 }
 
+// Shutdown releases the resources held by the node's tangle (pending request timers etc.).
+func (n *Node) Shutdown() {
+	n.tangle.Shutdown()
+}
+
 // IssuePayload sends the Color to the socket for creating a new Message
 func (n *Node) IssuePayload(payload Color) {
-	n.peer.Socket <- payload
+	n.peer.ReceiveNetworkMessage(payload)
+}
+
+// IssueTransaction sends the Transaction to the socket for creating a new Message carrying it
+func (n *Node) IssueTransaction(tx *Transaction) {
+	n.peer.ReceiveNetworkMessage(tx)
+}
+
+// IssueMilestone sends the milestone marker to the socket for creating the next milestone Message
+func (n *Node) IssueMilestone() {
+	n.peer.ReceiveNetworkMessage(&MilestonePayload{})
+}
+
+// ReattachOrphanedMessages reissues the payloads of own messages that stayed unreferenced for longer
+// than the deadline, the wallet behavior of real nodes. Every message is reattached at most once;
+// the new message gets fresh parents through the regular tip selection.
+func (n *Node) ReattachOrphanedMessages(deadline time.Duration) (reattached int) {
+	now := TimeSource.Now()
+	for messageID, message := range n.tangle.Storage.messageDB {
+		if message.Issuer != n.peer.ID || now.Sub(message.IssuanceTime) < deadline || n.reattachedMessages[messageID] {
+			continue
+		}
+		if len(n.tangle.Storage.StrongChildren(messageID)) > 0 ||
+			len(n.tangle.Storage.WeakChildren(messageID)) > 0 ||
+			len(n.tangle.Storage.LikeChildren(messageID)) > 0 {
+			continue
+		}
+		if !n.tangle.Storage.MessageMetadata(messageID).ConfirmationTime().IsZero() {
+			continue
+		}
+
+		n.reattachedMessages[messageID] = true
+		countReattachment()
+		reattached++
+		n.IssuePayload(message.Payload)
+	}
+
+	return
 }
 
 func (n *Node) HandleNetworkMessage(networkMessage interface{}) {
 	switch receivedNetworkMessage := networkMessage.(type) {
 	case *MessageRequest:
-		if requestedMessage := n.tangle.Storage.Message(receivedNetworkMessage.MessageID); requestedMessage != nil {
-			n.peer.Neighbors[receivedNetworkMessage.Issuer].Send(requestedMessage)
+		if requestedMessage := n.tangle.Storage.Message(receivedNetworkMessage.MessageID); requestedMessage != nil &&
+			(n.gossipFilter == nil || n.gossipFilter(requestedMessage)) {
+			// the requesting neighbor may have been disconnected (rewiring, peer scoring) in the meantime
+			if neighborConnection, connected := n.peer.Neighbors[receivedNetworkMessage.Issuer]; connected {
+				neighborConnection.Send(requestedMessage)
+			}
 		}
+	case *MessageInventory:
+		n.processInventory(receivedNetworkMessage)
 	case *Message:
+		if duplicate := n.tangle.Storage.Message(receivedNetworkMessage.ID) != nil; duplicate {
+			countReceivedMessage(true)
+			n.peer.CountDuplicateDelivery()
+			n.peer.ReportInvalidDelivery()
+		} else {
+			countReceivedMessage(false)
+		}
+		if !validateTimestamp(receivedNetworkMessage) {
+			return
+		}
 		n.tangle.ProcessMessage(receivedNetworkMessage)
 	case Color:
 		n.tangle.ProcessMessage(n.tangle.MessageFactory.CreateMessage(receivedNetworkMessage))
+	case *Transaction:
+		n.tangle.ProcessMessage(n.tangle.MessageFactory.CreateTransactionMessage(receivedNetworkMessage))
+	case *MilestonePayload:
+		n.tangle.ProcessMessage(n.tangle.MessageFactory.CreateMilestoneMessage())
 	}
 }
 
@@ -14,9 +14,13 @@ type OpinionManagerInterface interface {
 	Setup()
 	FormOpinion(messageID MessageID)
 	Opinion() Color
+	OpinionOf(set ConflictSetID) Color
 	SetOpinion(opinion Color)
 	WeightsUpdated()
 	UpdateWeights(messageID MessageID) (updated bool)
+	ApplyEpochWeights()
+	FinalizeColor(color Color)
+	SeedApprovalWeight(color Color, weight uint64)
 	UpdateConfirmation(oldOpinion Color, maxOpinion Color)
 	Tangle() *Tangle
 }
@@ -25,10 +29,11 @@ type OpinionManager struct {
 	events *OpinionManagerEvents
 
 	tangle          *Tangle
-	ownOpinion      Color
+	ownOpinions     map[ConflictSetID]Color
 	peerOpinions    map[network.PeerID]*Opinion
 	approvalWeights map[Color]uint64
-	colorConfirmed  bool
+	colorConfirmed  map[ConflictSetID]bool
+	colorGrades     map[Color]int
 }
 
 func NewOpinionManager(tangle *Tangle) (opinionManager *OpinionManager) {
@@ -39,13 +44,16 @@ func NewOpinionManager(tangle *Tangle) (opinionManager *OpinionManager) {
 			ApprovalWeightUpdated:     events.NewEvent(approvalWeightUpdatedHandler),
 			MinConfirmedWeightUpdated: events.NewEvent(approvalWeightUpdatedHandler),
 			ColorConfirmed:            events.NewEvent(colorEventHandler),
+			ColorGraded:               events.NewEvent(colorGradedEventHandler),
 			ColorUnconfirmed:          events.NewEvent(reorgEventHandler),
 		},
 
 		tangle:          tangle,
+		ownOpinions:     make(map[ConflictSetID]Color),
 		peerOpinions:    make(map[network.PeerID]*Opinion),
 		approvalWeights: make(map[Color]uint64),
-		colorConfirmed:  false,
+		colorConfirmed:  make(map[ConflictSetID]bool),
+		colorGrades:     make(map[Color]int),
 	}
 }
 
@@ -104,73 +112,194 @@ func (o *OpinionManager) UpdateWeights(messageID MessageID) (updated bool) {
 
 	if exist {
 		// We calculate the approval weight of the branch based on the node who issued the message to the branch (i.e., it already voted for the branch).
-		o.approvalWeights[lastOpinion.Color] -= o.tangle.WeightDistribution.Weight(message.Issuer)
-		o.events.ApprovalWeightUpdated.Trigger(lastOpinion.Color, int64(-o.tangle.WeightDistribution.Weight(message.Issuer)))
+		o.approvalWeights[lastOpinion.Color] -= o.tangle.WeightDistribution.EpochWeight(message.Issuer)
+		o.events.ApprovalWeightUpdated.Trigger(lastOpinion.Color, int64(-o.tangle.WeightDistribution.EpochWeight(message.Issuer)))
 
 		// Record the min confirmed weight
 		// When the weight of the color < confirmation threshold, but the color is still not unconfirmed yet.
-		if o.colorConfirmed && o.ownOpinion == lastOpinion.Color && !o.checkColorConfirmed(o.ownOpinion) {
+		set := lastOpinion.Color.ConflictSet()
+		if o.colorConfirmed[set] && o.ownOpinions[set] == lastOpinion.Color && !o.checkColorConfirmed(o.ownOpinions[set]) {
 			o.events.MinConfirmedWeightUpdated.Trigger(lastOpinion.Color, int64(o.approvalWeights[lastOpinion.Color]))
 		}
 	}
 
 	// We calculate the approval weight of the branch based on the node who issued the message to the branch (i.e., it already voted for the branch).
-	o.approvalWeights[messageMetadata.InheritedColor()] += o.tangle.WeightDistribution.Weight(message.Issuer)
-	o.events.ApprovalWeightUpdated.Trigger(messageMetadata.InheritedColor(), int64(o.tangle.WeightDistribution.Weight(message.Issuer)))
+	o.approvalWeights[messageMetadata.InheritedColor()] += o.tangle.WeightDistribution.EpochWeight(message.Issuer)
+	o.events.ApprovalWeightUpdated.Trigger(messageMetadata.InheritedColor(), int64(o.tangle.WeightDistribution.EpochWeight(message.Issuer)))
 
 	lastOpinion.Color = messageMetadata.InheritedColor()
 	updated = true
 	return
 }
 
+// Opinion returns the opinion of the first conflict set. With a single conflict set (the default) this
+// is the node's only opinion; tip selection attaches to this branch.
 func (o *OpinionManager) Opinion() Color {
-	return o.ownOpinion
+	return o.ownOpinions[ConflictSetID(0)]
+}
+
+// OpinionOf returns the opinion of the given conflict set, UndefinedColor while no branch of that set
+// has the most approval weight yet.
+func (o *OpinionManager) OpinionOf(set ConflictSetID) Color {
+	return o.ownOpinions[set]
 }
 
 func (o *OpinionManager) SetOpinion(opinion Color) {
-	if oldOpinion := o.ownOpinion; oldOpinion != opinion {
+	set := opinion.ConflictSet()
+	if oldOpinion := o.ownOpinions[set]; oldOpinion != opinion {
 		o.events.OpinionChanged.Trigger(oldOpinion, opinion, int64(o.Tangle().WeightDistribution.Weight(o.Tangle().Peer.ID)), o.tangle.Peer.ID)
 	}
-	o.ownOpinion = opinion
+	o.ownOpinions[set] = opinion
 }
 
 func (o *OpinionManager) UpdateConfirmation(oldOpinion Color, maxOpinion Color) {
-	if o.colorConfirmed && maxOpinion != oldOpinion {
-		o.colorConfirmed = false
-		o.Events().ColorUnconfirmed.Trigger(oldOpinion, int64(o.approvalWeights[o.ownOpinion]), int64(o.tangle.WeightDistribution.Weight(o.tangle.Peer.ID)))
+	set := maxOpinion.ConflictSet()
+	if set == NoConflictSet {
+		set = oldOpinion.ConflictSet()
+	}
+	if set == NoConflictSet {
+		return
+	}
+
+	if o.colorConfirmed[set] && maxOpinion != oldOpinion {
+		o.colorConfirmed[set] = false
+		o.Events().ColorUnconfirmed.Trigger(oldOpinion, int64(o.approvalWeights[o.ownOpinions[set]]), int64(o.tangle.WeightDistribution.Weight(o.tangle.Peer.ID)))
 	}
 
-	if o.checkColorConfirmed(maxOpinion) && !o.colorConfirmed {
+	if o.checkColorConfirmed(maxOpinion) && !o.colorConfirmed[set] {
 		// Here we accumulate the approval weights in our local tangle.
 		o.Events().ColorConfirmed.Trigger(maxOpinion, int64(o.tangle.WeightDistribution.Weight(o.tangle.Peer.ID)))
-		o.colorConfirmed = true
+		o.colorConfirmed[set] = true
 	}
 }
 
-// Update the opinions counter and ownOpinion based on the highest peer color value and maxApprovalWeight
-// Each Color has approvalWeight. The Color with maxApprovalWeight determines the ownOpinion
+// Update the opinions counter and ownOpinions based on the highest peer color value and maxApprovalWeight.
+// Each Color has approvalWeight. Within every conflict set, the Color with maxApprovalWeight determines
+// the opinion of that set; the conflict sets are independent of each other.
 func (o *OpinionManager) WeightsUpdated() {
-	maxOpinion := getMaxOpinion(o.approvalWeights)
-	oldOpinion := o.ownOpinion
-	if maxOpinion != oldOpinion {
-		o.ownOpinion = maxOpinion
-		o.Events().OpinionChanged.Trigger(oldOpinion, maxOpinion, int64(o.tangle.WeightDistribution.Weight(o.tangle.Peer.ID)))
+	// with FPC voting the opinions only move through the query rounds; the approval weights keep
+	// feeding the grade reporting but no longer switch or confirm branches
+	if !config.FPCVotingEnabled {
+		for _, set := range ConflictSets() {
+			maxOpinion := getMaxOpinion(o.conflictSetWeights(set))
+			oldOpinion := o.ownOpinions[set]
+			if maxOpinion != oldOpinion {
+				o.ownOpinions[set] = maxOpinion
+				o.Events().OpinionChanged.Trigger(oldOpinion, maxOpinion, int64(o.tangle.WeightDistribution.Weight(o.tangle.Peer.ID)))
+			}
+			o.UpdateConfirmation(oldOpinion, maxOpinion)
+		}
+	}
+	o.updateColorGrades()
+}
+
+// SeedApprovalWeight credits a preassigned weight to the color's branch, the prior votes a
+// mid-conflict start assumes, see the genesis set. The weight is published like a regular vote.
+func (o *OpinionManager) SeedApprovalWeight(color Color, weight uint64) {
+	if color == UndefinedColor || weight == 0 {
+		return
+	}
+	o.approvalWeights[color] += weight
+	o.events.ApprovalWeightUpdated.Trigger(color, int64(weight))
+}
+
+// FinalizeColor marks the color's conflict set as confirmed on behalf of an external voting mechanism
+// like the FPC rounds, triggering ColorConfirmed the way the approval weight rule does.
+func (o *OpinionManager) FinalizeColor(color Color) {
+	set := color.ConflictSet()
+	if set == NoConflictSet || o.colorConfirmed[set] {
+		return
+	}
+	o.colorConfirmed[set] = true
+	o.events.ColorConfirmed.Trigger(color, int64(o.tangle.WeightDistribution.Weight(o.tangle.Peer.ID)))
+}
+
+// updateColorGrades triggers ColorGraded for every color whose approval weight newly crossed one of
+// the ConfirmationGrades fractions of the total weight. Like the message grades, color grades only
+// ever rise; a later weight drop shows up through ColorUnconfirmed instead.
+func (o *OpinionManager) updateColorGrades() {
+	for color, approvalWeight := range o.approvalWeights {
+		grade := 0
+		for _, fraction := range config.ConfirmationGrades {
+			if float64(approvalWeight) >= fraction*o.relevantTotalWeight() {
+				grade++
+			}
+		}
+		if grade > o.colorGrades[color] {
+			o.colorGrades[color] = grade
+			o.events.ColorGraded.Trigger(color, grade, int64(approvalWeight))
+		}
 	}
-	o.UpdateConfirmation(oldOpinion, maxOpinion)
+}
+
+// ColorGrade returns the highest confirmation grade the color has reached so far, 0 while its
+// approval weight never crossed the first ConfirmationGrades fraction.
+func (o *OpinionManager) ColorGrade(color Color) int {
+	return o.colorGrades[color]
+}
+
+// ApplyEpochWeights rebuilds the approval weights of all colors from the recorded per-peer votes
+// under the weights sealed at the last epoch boundary and re-evaluates the opinions, keeping the vote
+// accounting consistent while the weight distribution evolves during the run. The weight deltas are
+// published through ApprovalWeightUpdated like regular vote changes.
+func (o *OpinionManager) ApplyEpochWeights() {
+	rebuilt := make(map[Color]uint64)
+	for peerID, opinion := range o.peerOpinions {
+		if opinion.Color == UndefinedColor {
+			continue
+		}
+		rebuilt[opinion.Color] += o.tangle.WeightDistribution.EpochWeight(peerID)
+	}
+
+	for color := range o.approvalWeights {
+		if _, voted := rebuilt[color]; !voted {
+			rebuilt[color] = 0
+		}
+	}
+	for color, weight := range rebuilt {
+		if delta := int64(weight) - int64(o.approvalWeights[color]); delta != 0 {
+			o.approvalWeights[color] = weight
+			o.events.ApprovalWeightUpdated.Trigger(color, delta)
+		}
+	}
+
+	o.WeightsUpdated()
+}
+
+// relevantTotalWeight is the total weight the confirmation and grade thresholds are relative to: the
+// static NodesTotalWeight, or the total sealed at the last epoch boundary once the weight distribution
+// evolves during the run.
+func (o *OpinionManager) relevantTotalWeight() float64 {
+	if o.tangle.WeightDistribution.EpochSealed() {
+		return float64(o.tangle.WeightDistribution.EpochTotalWeight())
+	}
+	return float64(config.NodesTotalWeight)
+}
+
+// conflictSetWeights returns the approval weights of the colors in the given conflict set only.
+func (o *OpinionManager) conflictSetWeights(set ConflictSetID) map[Color]uint64 {
+	aw := make(map[Color]uint64)
+	for color, weight := range o.approvalWeights {
+		if color.ConflictSet() == set {
+			aw[color] = weight
+		}
+	}
+	return aw
 }
 
 func (o *OpinionManager) checkColorConfirmed(newOpinion Color) bool {
 	if config.ConfirmationThresholdAbsolute {
-		return float64(o.approvalWeights[newOpinion]) > float64(config.NodesTotalWeight)*config.ConfirmationThreshold
+		return float64(o.approvalWeights[newOpinion]) > o.relevantTotalWeight()*config.ConfirmationThreshold
 	} else {
+		// only the colors of the same conflict set compete with newOpinion
 		aw := make(map[Color]uint64)
 		for key, value := range o.approvalWeights {
-			if key != newOpinion {
+			if key != newOpinion && key.ConflictSet() == newOpinion.ConflictSet() {
 				aw[key] = value
 			}
 		}
 		alternativeOpinion := getMaxOpinion(aw)
-		return float64(o.approvalWeights[newOpinion])-float64(o.approvalWeights[alternativeOpinion]) > float64(config.NodesTotalWeight)*config.ConfirmationThreshold
+		return float64(o.approvalWeights[newOpinion])-float64(o.approvalWeights[alternativeOpinion]) > o.relevantTotalWeight()*config.ConfirmationThreshold
 	}
 }
 
@@ -206,6 +335,7 @@ type OpinionManagerEvents struct {
 	ApprovalWeightUpdated     *events.Event
 	MinConfirmedWeightUpdated *events.Event
 	ColorConfirmed            *events.Event
+	ColorGraded               *events.Event
 	ColorUnconfirmed          *events.Event
 }
 
@@ -215,6 +345,9 @@ func opinionChangedEventHandler(handler interface{}, params ...interface{}) {
 func colorEventHandler(handler interface{}, params ...interface{}) {
 	handler.(func(Color, int64))(params[0].(Color), params[1].(int64))
 }
+func colorGradedEventHandler(handler interface{}, params ...interface{}) {
+	handler.(func(Color, int, int64))(params[0].(Color), params[1].(int), params[2].(int64))
+}
 func reorgEventHandler(handler interface{}, params ...interface{}) {
 	handler.(func(Color, int64, int64))(params[0].(Color), params[1].(int64), params[2].(int64))
 }
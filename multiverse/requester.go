@@ -2,13 +2,48 @@ package multiverse
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/iotaledger/hive.go/events"
 	"github.com/iotaledger/hive.go/timedexecutor"
+
+	"github.com/iotaledger/multivers-simulation/config"
+)
+
+// region Request statistics ///////////////////////////////////////////////////////////////////////////////////////////
+
+var (
+	missingRequestCounter  int64
+	retriedRequestCounter  int64
+	replayedRequestCounter int64
+	failedRequestCounter   int64
 )
 
-const retryInterval = 5 * time.Second
+// CountReplayedRequest accounts a request for a message the issuing node already holds, issued by the
+// replay adversary purely to inflate the requester traffic.
+func CountReplayedRequest() {
+	atomic.AddInt64(&replayedRequestCounter, 1)
+}
+
+// MessageRequestStatistics returns how many message requests the nodes issued, split by cause:
+// first-time requests for genuinely missing messages, retries for messages that stayed missing, the
+// replay adversary's requests for messages it already holds and requests that were given up after
+// RequesterTimeout.
+func MessageRequestStatistics() (missing int64, retried int64, replayed int64, failed int64) {
+	return atomic.LoadInt64(&missingRequestCounter), atomic.LoadInt64(&retriedRequestCounter),
+		atomic.LoadInt64(&replayedRequestCounter), atomic.LoadInt64(&failedRequestCounter)
+}
+
+// ResetRequestStatistics zeroes the request statistics of a previous Run in the same process.
+func ResetRequestStatistics() {
+	atomic.StoreInt64(&missingRequestCounter, 0)
+	atomic.StoreInt64(&retriedRequestCounter, 0)
+	atomic.StoreInt64(&replayedRequestCounter, 0)
+	atomic.StoreInt64(&failedRequestCounter, 0)
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 // region Requester ////////////////////////////////////////////////////////////////////////////////////////////////////
 
@@ -17,19 +52,30 @@ type Requester struct {
 
 	tangle         *Tangle
 	timedExecutor  *timedexecutor.TimedExecutor
-	queuedElements map[MessageID]*timedexecutor.ScheduledTask
+	queuedElements map[MessageID]*pendingRequest
 	mutex          sync.Mutex
 }
 
+// pendingRequest is the retry state of one missing message: the scheduled retry task, the interval
+// the next retry waits for (it doubles with every unanswered retry) and when the request started, the
+// base of the give-up timeout.
+type pendingRequest struct {
+	task     *timedexecutor.ScheduledTask
+	interval time.Duration
+	started  time.Time
+}
+
 func NewRequester(tangle *Tangle) (requester *Requester) {
 	requester = &Requester{
 		Events: &RequesterEvents{
-			Request: events.NewEvent(messageIDEventCaller),
+			Request:        events.NewEvent(messageIDEventCaller),
+			RequestRetried: events.NewEvent(messageIDEventCaller),
+			RequestFailed:  events.NewEvent(messageIDEventCaller),
 		},
 
 		tangle:         tangle,
 		timedExecutor:  timedexecutor.New(1),
-		queuedElements: make(map[MessageID]*timedexecutor.ScheduledTask),
+		queuedElements: make(map[MessageID]*pendingRequest),
 	}
 
 	return
@@ -40,6 +86,18 @@ func (r *Requester) Setup() {
 	r.tangle.Storage.Events.MessageStored.Attach(events.NewClosure(r.StopRequest))
 }
 
+// Shutdown cancels all pending retry timers so no goroutines stay behind after the network stopped.
+func (r *Requester) Shutdown() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for messageID, request := range r.queuedElements {
+		request.task.Cancel()
+		delete(r.queuedElements, messageID)
+	}
+	r.timedExecutor.Shutdown(timedexecutor.CancelPendingTasks)
+}
+
 func (r *Requester) StartRequest(messageID MessageID) {
 	// Comment out this funcion to turn off solidifier
 
@@ -50,6 +108,11 @@ func (r *Requester) StartRequest(messageID MessageID) {
 		return
 	}
 
+	atomic.AddInt64(&missingRequestCounter, 1)
+	r.queuedElements[messageID] = &pendingRequest{
+		interval: time.Duration(config.RequesterBaseInterval) * time.Second,
+		started:  TimeSource.Now(),
+	}
 	r.triggerRequestAndScheduleRetry(messageID)
 }
 
@@ -62,26 +125,41 @@ func (r *Requester) StopRequest(messageID MessageID) {
 		return
 	}
 
-	request.Cancel()
+	request.task.Cancel()
 	delete(r.queuedElements, messageID)
 }
 
 func (r *Requester) triggerRequestAndScheduleRetry(messageID MessageID) {
 	r.Events.Request.Trigger(messageID)
 
-	r.queuedElements[messageID] = r.timedExecutor.ExecuteAfter(func() {
+	request := r.queuedElements[messageID]
+	request.task = r.timedExecutor.ExecuteAfter(func() {
 		r.retry(messageID)
-	}, retryInterval)
+	}, request.interval)
 }
 
 func (r *Requester) retry(messageID MessageID) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	if _, requestExists := r.queuedElements[messageID]; !requestExists {
+	request, requestExists := r.queuedElements[messageID]
+	if !requestExists {
+		return
+	}
+
+	// give the message up once it stayed missing for the whole timeout, the walker above it stays
+	// unsolid but the requester traffic stops
+	if TimeSource.Now().Sub(request.started) >= time.Duration(config.RequesterTimeout)*time.Second {
+		delete(r.queuedElements, messageID)
+		atomic.AddInt64(&failedRequestCounter, 1)
+		r.Events.RequestFailed.Trigger(messageID)
 		return
 	}
 
+	// every unanswered retry doubles the wait before the next one
+	request.interval *= 2
+	atomic.AddInt64(&retriedRequestCounter, 1)
+	r.Events.RequestRetried.Trigger(messageID)
 	r.triggerRequestAndScheduleRetry(messageID)
 }
 
@@ -90,7 +168,9 @@ func (r *Requester) retry(messageID MessageID) {
 // region RequesterEvents //////////////////////////////////////////////////////////////////////////////////////////////
 
 type RequesterEvents struct {
-	Request *events.Event
+	Request        *events.Event
+	RequestRetried *events.Event
+	RequestFailed  *events.Event
 }
 
 // endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,150 @@
+package multiverse
+
+import (
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/events"
+
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region Scheduler ////////////////////////////////////////////////////////////////////////////////////
+
+// Scheduler sits between booking and gossip when SchedulerEnabled is set: booked messages enter an
+// outbox queue per issuer and leave at the node's scheduling rate via deficit round robin with
+// mana-proportional quanta. A low-mana spammer therefore only inflates its own queue instead of
+// crowding out the traffic of the honest issuers. The queue lengths and the queueing delays are
+// dumped to sc-*.csv.
+type Scheduler struct {
+	tangle *Tangle
+	Events *SchedulerEvents
+
+	queues   map[network.PeerID][]outboxEntry
+	deficits map[network.PeerID]float64
+	ring     []network.PeerID
+
+	scheduledMessages int64
+	cumulativeDelay   time.Duration
+	mutex             sync.Mutex
+}
+
+// outboxEntry is one queued message together with its enqueue time, the base of the delay metric.
+type outboxEntry struct {
+	messageID MessageID
+	enqueued  time.Time
+}
+
+func NewScheduler(tangle *Tangle) *Scheduler {
+	return &Scheduler{
+		tangle: tangle,
+		Events: &SchedulerEvents{
+			MessageScheduled: events.NewEvent(messageIDEventCaller),
+		},
+		queues:   make(map[network.PeerID][]outboxEntry),
+		deficits: make(map[network.PeerID]float64),
+	}
+}
+
+// Enqueue puts the booked message into the outbox queue of its issuer.
+func (s *Scheduler) Enqueue(messageID MessageID) {
+	issuer := s.tangle.Storage.Message(messageID).Issuer
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.queues[issuer]; !exists {
+		s.ring = append(s.ring, issuer)
+	}
+	s.queues[issuer] = append(s.queues[issuer], outboxEntry{messageID: messageID, enqueued: TimeSource.Now()})
+}
+
+// ScheduleBatch releases up to maxCount queued messages in deficit round robin order and triggers
+// MessageScheduled for each of them; the drain worker calls it at the configured scheduling rate.
+func (s *Scheduler) ScheduleBatch(maxCount int) {
+	now := TimeSource.Now()
+
+	s.mutex.Lock()
+	released := make([]MessageID, 0, maxCount)
+	for len(released) < maxCount && s.queuedLocked() > 0 {
+		for _, issuer := range s.ring {
+			queue := s.queues[issuer]
+			if len(queue) == 0 {
+				continue
+			}
+
+			s.deficits[issuer] += s.quantum(issuer)
+			for len(queue) > 0 && s.deficits[issuer] >= 1 && len(released) < maxCount {
+				entry := queue[0]
+				queue = queue[1:]
+				s.deficits[issuer]--
+
+				s.scheduledMessages++
+				s.cumulativeDelay += now.Sub(entry.enqueued)
+				released = append(released, entry.messageID)
+			}
+			s.queues[issuer] = queue
+			if len(queue) == 0 {
+				s.deficits[issuer] = 0
+			}
+		}
+	}
+	s.mutex.Unlock()
+
+	// the gossip triggered by the event sends over the neighbor sockets, keep that outside the lock
+	for _, messageID := range released {
+		s.Events.MessageScheduled.Trigger(messageID)
+	}
+}
+
+// quantum returns the deficit an issuer earns per round robin pass. It is proportional to the
+// issuer's mana and normalized so the network-wide average is 1; the floor keeps zero-mana issuers
+// from starving entirely.
+func (s *Scheduler) quantum(issuer network.PeerID) float64 {
+	quantum := float64(s.tangle.WeightDistribution.Weight(issuer)) * float64(config.NodesCount) / float64(config.NodesTotalWeight)
+	if quantum < 0.01 {
+		quantum = 0.01
+	}
+	return quantum
+}
+
+// QueueStatistics returns the total number of queued messages and the length of the longest
+// per-issuer queue.
+func (s *Scheduler) QueueStatistics() (queuedMessages int, longestQueue int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, queue := range s.queues {
+		queuedMessages += len(queue)
+		if len(queue) > longestQueue {
+			longestQueue = len(queue)
+		}
+	}
+	return
+}
+
+// DelayStatistics returns how many messages the scheduler released so far and their cumulative
+// queueing delay.
+func (s *Scheduler) DelayStatistics() (scheduledMessages int64, cumulativeDelay time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.scheduledMessages, s.cumulativeDelay
+}
+
+func (s *Scheduler) queuedLocked() (queuedMessages int) {
+	for _, queue := range s.queues {
+		queuedMessages += len(queue)
+	}
+	return
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////
+
+// region SchedulerEvents //////////////////////////////////////////////////////////////////////////////
+
+type SchedulerEvents struct {
+	MessageScheduled *events.Event
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,134 @@
+package multiverse
+
+import (
+	"math"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region Snapshot /////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// A Snapshot captures the confirmed state of one node's tangle. A node importing it starts with the
+// confirmed past cone already solid and confirmed and with its opinions matching the exported state,
+// enabling warm-started simulations and "node joins late and syncs" experiments.
+type Snapshot struct {
+	Messages []SnapshotMessage `json:"messages"`
+}
+
+// SnapshotMessage is the serialized form of one confirmed message together with the metadata the
+// importing node needs to treat it as settled.
+type SnapshotMessage struct {
+	ID               MessageID      `json:"id"`
+	StrongParents    []MessageID    `json:"strongParents"`
+	WeakParents      []MessageID    `json:"weakParents,omitempty"`
+	LikeParents      []MessageID    `json:"likeParents,omitempty"`
+	SequenceNumber   uint64         `json:"sequenceNumber"`
+	Issuer           network.PeerID `json:"issuer"`
+	Payload          Color          `json:"payload"`
+	InheritedColor   Color          `json:"inheritedColor"`
+	Weight           uint64         `json:"weight"`
+	IssuanceTime     int64          `json:"issuanceTimeUnixNano"`
+	ConfirmationTime int64          `json:"confirmationTimeUnixNano"`
+}
+
+// ExportSnapshot returns the confirmed messages of the tangle, ordered by message ID so the votes
+// they carry can be replayed in issuance order on import.
+func (t *Tangle) ExportSnapshot() (snapshot *Snapshot) {
+	snapshot = &Snapshot{}
+	for messageID, messageMetadata := range t.Storage.messageMetadataDB {
+		if messageMetadata.confirmationTime.IsZero() {
+			continue
+		}
+
+		message := t.Storage.messageDB[messageID]
+		snapshot.Messages = append(snapshot.Messages, SnapshotMessage{
+			ID:               message.ID,
+			StrongParents:    sortedMessageIDs(message.StrongParents),
+			WeakParents:      sortedMessageIDs(message.WeakParents),
+			LikeParents:      sortedMessageIDs(message.LikeParents),
+			SequenceNumber:   message.SequenceNumber,
+			Issuer:           message.Issuer,
+			Payload:          message.Payload,
+			InheritedColor:   messageMetadata.inheritedColor,
+			Weight:           messageMetadata.weight,
+			IssuanceTime:     message.IssuanceTime.UnixNano(),
+			ConfirmationTime: messageMetadata.confirmationTime.UnixNano(),
+		})
+	}
+	sort.Slice(snapshot.Messages, func(i, j int) bool {
+		return snapshot.Messages[i].ID < snapshot.Messages[j].ID
+	})
+
+	return
+}
+
+// ImportSnapshot stores the messages of the snapshot as already solid and confirmed without
+// triggering any tangle events and replays their votes, so the node's opinions match the exported
+// state before the first live message arrives.
+func (t *Tangle) ImportSnapshot(snapshot *Snapshot) {
+	maxMessageID := Genesis
+	ownSequenceNumber := uint64(0)
+	for _, snapshotMessage := range snapshot.Messages {
+		message := &Message{
+			ID:             snapshotMessage.ID,
+			StrongParents:  NewMessageIDs(snapshotMessage.StrongParents...),
+			WeakParents:    NewMessageIDs(snapshotMessage.WeakParents...),
+			LikeParents:    NewMessageIDs(snapshotMessage.LikeParents...),
+			SequenceNumber: snapshotMessage.SequenceNumber,
+			Issuer:         snapshotMessage.Issuer,
+			Payload:        snapshotMessage.Payload,
+			IssuanceTime:   time.Unix(0, snapshotMessage.IssuanceTime),
+		}
+		messageMetadata := &MessageMetadata{
+			id:               snapshotMessage.ID,
+			solid:            true,
+			inheritedColor:   snapshotMessage.InheritedColor,
+			weightSlice:      make([]byte, int(math.Ceil(float64(config.NodesCount)/8.0))),
+			weight:           snapshotMessage.Weight,
+			confirmationTime: time.Unix(0, snapshotMessage.ConfirmationTime),
+		}
+		t.Storage.storeSnapshot(message, messageMetadata)
+
+		// the messages are ordered by ID, so the sequence-number logic of the opinion manager sees
+		// the votes in issuance order
+		t.OpinionManager.UpdateWeights(message.ID)
+
+		if message.ID > maxMessageID {
+			maxMessageID = message.ID
+		}
+		if message.Issuer == t.Peer.ID && message.SequenceNumber > ownSequenceNumber {
+			ownSequenceNumber = message.SequenceNumber
+		}
+	}
+	t.OpinionManager.WeightsUpdated()
+
+	// newly issued messages must not reuse the IDs and sequence numbers of the imported ones
+	advanceMessageIDCounter(maxMessageID)
+	t.MessageFactory.SkipSequenceNumbers(ownSequenceNumber)
+}
+
+func sortedMessageIDs(messageIDs MessageIDs) (sortedIDs []MessageID) {
+	sortedIDs = make([]MessageID, 0, len(messageIDs))
+	for messageID := range messageIDs {
+		sortedIDs = append(sortedIDs, messageID)
+	}
+	sort.Slice(sortedIDs, func(i, j int) bool { return sortedIDs[i] < sortedIDs[j] })
+
+	return
+}
+
+// advanceMessageIDCounter raises the global message ID counter to at least the given ID.
+func advanceMessageIDCounter(messageID MessageID) {
+	for {
+		current := atomic.LoadInt64(&messageIDCounter)
+		if current >= int64(messageID) || atomic.CompareAndSwapInt64(&messageIDCounter, current, int64(messageID)) {
+			return
+		}
+	}
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -44,6 +44,9 @@ func (s *Solidifier) Solidify(messageID MessageID) {
 		for weakChildID := range s.tangle.Storage.WeakChildren(message.ID) {
 			walker.Push(weakChildID)
 		}
+		for likeChildID := range s.tangle.Storage.LikeChildren(message.ID) {
+			walker.Push(likeChildID)
+		}
 	}, NewMessageIDs(messageID), true)
 }
 
@@ -55,6 +58,9 @@ func (s *Solidifier) messageSolid(message *Message) (isSolid bool) {
 	if !s.parentsSolid(message.WeakParents) {
 		isSolid = false
 	}
+	if !s.parentsSolid(message.LikeParents) {
+		isSolid = false
+	}
 
 	return
 }
@@ -65,6 +71,10 @@ func (s *Solidifier) parentsSolid(parentMessageIDs MessageIDs) (parentsSolid boo
 		if parentMessageID == Genesis {
 			continue
 		}
+		// a pruned parent was confirmed long ago, it counts as solid without being requested again
+		if s.tangle.Storage.Pruned(parentMessageID) {
+			continue
+		}
 
 		parentMessageMetadata := s.tangle.Storage.MessageMetadata(parentMessageID)
 		if parentMessageMetadata == nil {
@@ -2,9 +2,12 @@ package multiverse
 
 import (
 	"math"
+	"time"
 
 	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/types"
 	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/network"
 )
 
 // region Storage //////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -17,6 +20,8 @@ type Storage struct {
 	messageMetadataDB map[MessageID]*MessageMetadata
 	strongChildrenDB  map[MessageID]MessageIDs
 	weakChildrenDB    map[MessageID]MessageIDs
+	likeChildrenDB    map[MessageID]MessageIDs
+	prunedDB          map[MessageID]types.Empty
 }
 
 func NewStorage(tangle *Tangle) (storage *Storage) {
@@ -30,6 +35,8 @@ func NewStorage(tangle *Tangle) (storage *Storage) {
 		messageMetadataDB: make(map[MessageID]*MessageMetadata),
 		strongChildrenDB:  make(map[MessageID]MessageIDs),
 		weakChildrenDB:    make(map[MessageID]MessageIDs),
+		likeChildrenDB:    make(map[MessageID]MessageIDs),
+		prunedDB:          make(map[MessageID]types.Empty),
 	}
 }
 
@@ -37,15 +44,33 @@ func (s *Storage) Store(message *Message) {
 	if _, exists := s.messageDB[message.ID]; exists {
 		return
 	}
+	if _, pruned := s.prunedDB[message.ID]; pruned {
+		return
+	}
 
 	s.messageDB[message.ID] = message
 	s.messageMetadataDB[message.ID] = &MessageMetadata{id: message.ID, weightSlice: make([]byte, int(math.Ceil(float64(config.NodesCount)/8.0)))}
 	s.storeChildReferences(message.ID, s.strongChildrenDB, message.StrongParents)
 	s.storeChildReferences(message.ID, s.weakChildrenDB, message.WeakParents)
+	s.storeChildReferences(message.ID, s.likeChildrenDB, message.LikeParents)
 
 	s.Events.MessageStored.Trigger(message.ID)
 }
 
+// storeSnapshot inserts an imported message with its settled metadata without triggering
+// MessageStored, the snapshot state is applied silently before the run starts, see ImportSnapshot.
+func (s *Storage) storeSnapshot(message *Message, messageMetadata *MessageMetadata) {
+	if _, exists := s.messageDB[message.ID]; exists {
+		return
+	}
+
+	s.messageDB[message.ID] = message
+	s.messageMetadataDB[message.ID] = messageMetadata
+	s.storeChildReferences(message.ID, s.strongChildrenDB, message.StrongParents)
+	s.storeChildReferences(message.ID, s.weakChildrenDB, message.WeakParents)
+	s.storeChildReferences(message.ID, s.likeChildrenDB, message.LikeParents)
+}
+
 func (s *Storage) Message(messageID MessageID) (message *Message) {
 	message = s.messageDB[messageID]
 	return
@@ -56,6 +81,96 @@ func (s *Storage) MessageMetadata(messageID MessageID) (messageMetadata *Message
 	return
 }
 
+// Pruned reports whether the message was evicted by PruneConfirmed. Pruned messages count as solid
+// parents and are never stored or requested again.
+func (s *Storage) Pruned(messageID MessageID) (pruned bool) {
+	_, pruned = s.prunedDB[messageID]
+	return
+}
+
+// PrunedMessages returns how many messages this node's tangle has evicted so far.
+func (s *Storage) PrunedMessages() int {
+	return len(s.prunedDB)
+}
+
+// PruneConfirmed evicts every message that has been confirmed for longer than maturityAge from the
+// in-memory tangle. Only the pruned marker remains as summary state: the confirmation itself was
+// already counted when it happened and an evicted message can no longer change any opinion, so very
+// long simulations stop growing memory without bound.
+func (s *Storage) PruneConfirmed(maturityAge time.Duration) (prunedMessages int) {
+	now := TimeSource.Now()
+	for messageID, messageMetadata := range s.messageMetadataDB {
+		if messageMetadata.confirmationTime.IsZero() || now.Sub(messageMetadata.confirmationTime) < maturityAge {
+			continue
+		}
+
+		message := s.messageDB[messageID]
+		s.removeChildReferences(messageID, s.strongChildrenDB, message.StrongParents)
+		s.removeChildReferences(messageID, s.weakChildrenDB, message.WeakParents)
+		s.removeChildReferences(messageID, s.likeChildrenDB, message.LikeParents)
+		delete(s.strongChildrenDB, messageID)
+		delete(s.weakChildrenDB, messageID)
+		delete(s.likeChildrenDB, messageID)
+		delete(s.messageDB, messageID)
+		delete(s.messageMetadataDB, messageID)
+
+		s.prunedDB[messageID] = types.Void
+		prunedMessages++
+	}
+
+	return
+}
+
+// IssuerOrphanage counts per issuer how many of its messages older than maturityAge are still not
+// referenced by any other message in this node's tangle, the success measure of a censorship attack.
+func (s *Storage) IssuerOrphanage(maturityAge time.Duration) (issued map[network.PeerID]int, orphaned map[network.PeerID]int) {
+	issued = make(map[network.PeerID]int)
+	orphaned = make(map[network.PeerID]int)
+
+	now := TimeSource.Now()
+	for messageID, message := range s.messageDB {
+		if now.Sub(message.IssuanceTime) < maturityAge {
+			continue
+		}
+		issued[message.Issuer]++
+		if len(s.strongChildrenDB[messageID]) == 0 && len(s.weakChildrenDB[messageID]) == 0 {
+			orphaned[message.Issuer]++
+		}
+	}
+
+	return
+}
+
+// CapturedApprovalWeight reports how many messages of the given issuers this node's tangle holds and
+// how much approval weight of other issuers' messages directly references them, the success measure
+// of a parasitic chain attack. Every referencing message is counted once, weighted with its issuer's
+// consensus weight.
+func (s *Storage) CapturedApprovalWeight(issuers map[network.PeerID]types.Empty) (trackedMessages int, capturingApprovers map[MessageID]types.Empty, capturedWeight uint64) {
+	capturingApprovers = make(map[MessageID]types.Empty)
+	for messageID, message := range s.messageDB {
+		if _, tracked := issuers[message.Issuer]; !tracked {
+			continue
+		}
+		trackedMessages++
+		for childID := range s.strongChildrenDB[messageID] {
+			child := s.messageDB[childID]
+			if child == nil {
+				continue
+			}
+			if _, fromTracked := issuers[child.Issuer]; fromTracked {
+				continue
+			}
+			capturingApprovers[childID] = types.Void
+		}
+	}
+
+	for childID := range capturingApprovers {
+		capturedWeight += s.tangle.WeightDistribution.Weight(s.messageDB[childID].Issuer)
+	}
+
+	return
+}
+
 func (s *Storage) StrongChildren(messageID MessageID) (strongChildren MessageIDs) {
 	return s.strongChildrenDB[messageID]
 }
@@ -64,6 +179,10 @@ func (s *Storage) WeakChildren(messageID MessageID) (weakChildren MessageIDs) {
 	return s.weakChildrenDB[messageID]
 }
 
+func (s *Storage) LikeChildren(messageID MessageID) (likeChildren MessageIDs) {
+	return s.likeChildrenDB[messageID]
+}
+
 func (s *Storage) storeChildReferences(messageID MessageID, childReferenceDB map[MessageID]MessageIDs, parents MessageIDs) {
 	for parent := range parents {
 		if _, exists := childReferenceDB[parent]; !exists {
@@ -74,6 +193,14 @@ func (s *Storage) storeChildReferences(messageID MessageID, childReferenceDB map
 	}
 }
 
+func (s *Storage) removeChildReferences(messageID MessageID, childReferenceDB map[MessageID]MessageIDs, parents MessageIDs) {
+	for parent := range parents {
+		if children, exists := childReferenceDB[parent]; exists {
+			delete(children, messageID)
+		}
+	}
+}
+
 // endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 // region StorageEvents ////////////////////////////////////////////////////////////////////////////////////////////////
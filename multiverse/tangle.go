@@ -1,10 +1,16 @@
 package multiverse
 
 import (
+	"github.com/iotaledger/multivers-simulation/clock"
 	"github.com/iotaledger/multivers-simulation/config"
 	"github.com/iotaledger/multivers-simulation/network"
 )
 
+// TimeSource is the Clock all multiverse components read the current time from (message issuance and
+// confirmation timestamps, tip age checks). Realtime runs keep the wall clock default; the discrete-event
+// mode swaps in the virtual scheduler clock, making timestamps deterministic.
+var TimeSource clock.Clock = clock.WallClock{}
+
 type Tangle struct {
 	Peer               *network.Peer
 	WeightDistribution *network.ConsensusWeightDistribution
@@ -16,6 +22,10 @@ type Tangle struct {
 	OpinionManager     OpinionManagerInterface
 	TipManager         *TipManager
 	MessageFactory     *MessageFactory
+	Detector           *Detector
+	Ledger             *Ledger
+	EpochManager       *EpochManager
+	Scheduler          *Scheduler
 	Utils              *Utils
 }
 
@@ -29,7 +39,11 @@ func NewTangle() (tangle *Tangle) {
 	tangle.OpinionManager = NewOpinionManager(tangle)
 	tangle.TipManager = NewTipManager(tangle, config.TSA)
 	tangle.MessageFactory = NewMessageFactory(tangle, uint64(config.NodesCount))
+	tangle.Detector = NewDetector(tangle)
 	tangle.ApprovalManager = NewApprovalManager(tangle)
+	tangle.Ledger = NewLedger(tangle)
+	tangle.EpochManager = NewEpochManager(tangle)
+	tangle.Scheduler = NewScheduler(tangle)
 	tangle.Utils = NewUtils(tangle)
 
 	return
@@ -44,9 +58,23 @@ func (t *Tangle) Setup(peer *network.Peer, weightDistribution *network.Consensus
 	t.Booker.Setup()
 	t.OpinionManager.Setup()
 	t.TipManager.Setup()
+	t.Detector.Setup()
 	t.ApprovalManager.Setup()
+	t.Ledger.Setup()
+	t.EpochManager.Setup()
+}
+
+func (t *Tangle) Shutdown() {
+	t.Requester.Shutdown()
 }
 
+// MessageProcessedTraceHook is called for every message entering a node's tangle (own issuances and
+// deliveries alike) when event trace recording is enabled; it stays nil otherwise.
+var MessageProcessedTraceHook func(peerID network.PeerID, message *Message)
+
 func (t *Tangle) ProcessMessage(message *Message) {
+	if MessageProcessedTraceHook != nil {
+		MessageProcessedTraceHook(t.Peer.ID, message)
+	}
 	t.Storage.Store(message)
 }
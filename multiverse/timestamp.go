@@ -0,0 +1,44 @@
+package multiverse
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/iotaledger/multivers-simulation/config"
+)
+
+// region Timestamp validation /////////////////////////////////////////////////////////////////////////////////////////
+
+var rejectedTimestampCounter int64
+
+// validateTimestamp reports whether the issuance time of a received message lies within the
+// TimestampWindow around the local clock; with the window disabled every timestamp is accepted.
+// Rejected messages never enter the tangle, which is the honest defense a timestamp-forging
+// adversary is evaluated against.
+func validateTimestamp(message *Message) (valid bool) {
+	if config.TimestampWindow <= 0 {
+		return true
+	}
+
+	window := time.Duration(config.TimestampWindow * float64(time.Second))
+	if offset := TimeSource.Now().Sub(message.IssuanceTime); offset < -window || offset > window {
+		atomic.AddInt64(&rejectedTimestampCounter, 1)
+		return false
+	}
+
+	return true
+}
+
+// RejectedTimestampCount returns how many received messages were dropped because their issuance time
+// fell outside the TimestampWindow.
+func RejectedTimestampCount() int64 {
+	return atomic.LoadInt64(&rejectedTimestampCounter)
+}
+
+// ResetTimestampStatistics zeroes the timestamp validation statistics of a previous Run in the same
+// process.
+func ResetTimestampStatistics() {
+	atomic.StoreInt64(&rejectedTimestampCounter, 0)
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -1,18 +1,39 @@
 package multiverse
 
 import (
+	"math"
 	"strings"
-	"time"
+	"sync/atomic"
 
+	"github.com/iotaledger/hive.go/crypto"
 	"github.com/iotaledger/hive.go/datastructure/randommap"
 	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/types"
 	"github.com/iotaledger/multivers-simulation/config"
 )
 
-var (
-	OptimalStrongParentsCount = int(float64(config.ParentsCount) * (1 - config.WeakTipsRatio))
-	OptimalWeakParentsCount   = int(float64(config.ParentsCount) * config.WeakTipsRatio)
-)
+// region Aged-out tip statistics //////////////////////////////////////////////////////////////////////////////////////
+
+var agedOutTipCounter int64
+
+// countAgedOutTip accounts a tip the RURTS age restriction dropped from a tip pool; such a message
+// can no longer be selected as a parent by that node and is orphaned unless it was referenced before.
+func countAgedOutTip() {
+	atomic.AddInt64(&agedOutTipCounter, 1)
+}
+
+// AgedOutTipStatistics returns how many tips the RURTS age restriction (DeltaURTS) dropped in total
+// across all nodes, the counterpart of the orphan rate in the or output.
+func AgedOutTipStatistics() (agedOutTips int64) {
+	return atomic.LoadInt64(&agedOutTipCounter)
+}
+
+// ResetAgedOutTipStatistics zeroes the aged-out tip statistics of a previous Run in the same process.
+func ResetAgedOutTipStatistics() {
+	atomic.StoreInt64(&agedOutTipCounter, 0)
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 // region TipManager ///////////////////////////////////////////////////////////////////////////////////////////////////
 
@@ -23,27 +44,29 @@ type TipManager struct {
 	tsa                 TipSelector
 	tipSets             map[Color]*TipSet
 	msgProcessedCounter map[Color]uint64
+
+	// latest processed message of every branch, referenced by shallow likes
+	latestBranchMessage map[Color]MessageID
+
+	// colors approved anywhere in each message's past cone, maintained when PastConeCheckEnabled
+	pastConeColors map[MessageID]map[Color]types.Empty
+
+	// optional censorship predicate, see SetTipFilter
+	tipFilter func(message *Message) bool
+
+	// optional override of which color's tip set the next message attaches to, see SetTipColorChooser
+	tipColorChooser func() Color
 }
 
 func NewTipManager(tangle *Tangle, tsaString string) (tipManager *TipManager) {
-	tsaString = strings.ToUpper(tsaString) // make sure string is upper case
-	var tsa TipSelector
-	switch tsaString {
-	case "POW":
-		tsa = POW{}
-	case "URTS":
-		tsa = URTS{}
-	case "RURTS":
-		tsa = RURTS{}
-	default:
-		tsa = URTS{}
-	}
+	tsa := bindTipSelector(tangle, TipSelectorFromString(tsaString))
 
 	// Initialize the counters
 	msgProcessedCounter := make(map[Color]uint64)
 	msgProcessedCounter[UndefinedColor] = 0
-	msgProcessedCounter[Red] = 0
-	msgProcessedCounter[Green] = 0
+	for _, color := range ConflictingColors() {
+		msgProcessedCounter[color] = 0
+	}
 
 	return &TipManager{
 		Events: &TipManagerEvents{
@@ -54,6 +77,8 @@ func NewTipManager(tangle *Tangle, tsaString string) (tipManager *TipManager) {
 		tsa:                 tsa,
 		tipSets:             make(map[Color]*TipSet),
 		msgProcessedCounter: msgProcessedCounter,
+		latestBranchMessage: make(map[Color]MessageID),
+		pastConeColors:      make(map[MessageID]map[Color]types.Empty),
 	}
 }
 
@@ -61,10 +86,63 @@ func (t *TipManager) Setup() {
 	t.tangle.OpinionManager.Events().OpinionFormed.Attach(events.NewClosure(t.AnalyzeMessage))
 }
 
+// TipSelectorFromString returns the tip selection algorithm of the given name, URTS for unknown names.
+func TipSelectorFromString(tsaString string) TipSelector {
+	switch strings.ToUpper(tsaString) {
+	case "POW":
+		return POW{}
+	case "RURTS":
+		return RURTS{}
+	case "MRTS":
+		return MRTS{}
+	default:
+		return URTS{}
+	}
+}
+
+// bindTipSelector attaches the tangle to the tip selectors that need more than the tip pool itself.
+func bindTipSelector(tangle *Tangle, tsa TipSelector) TipSelector {
+	if manaTSA, ok := tsa.(MRTS); ok {
+		manaTSA.tangle = tangle
+		return manaTSA
+	}
+
+	return tsa
+}
+
+// SetTipSelector replaces the tip selection algorithm of this node, used by adversary nodes whose
+// attack is the parent selection itself.
+func (t *TipManager) SetTipSelector(tsa TipSelector) {
+	t.tsa = bindTipSelector(t.tangle, tsa)
+}
+
+// SetTipFilter installs a predicate that is consulted before a message enters the tip sets; messages
+// it rejects are never selected as parents by this node. Censoring adversary nodes use it to build
+// around the messages of their victims.
+func (t *TipManager) SetTipFilter(filter func(message *Message) bool) {
+	t.tipFilter = filter
+}
+
+// SetTipColorChooser overrides which color's tip set the next message attaches to, instead of always
+// following the node's own opinion. Equivocating adversary nodes use it to spread their messages
+// over all conflicting branches.
+func (t *TipManager) SetTipColorChooser(chooser func() Color) {
+	t.tipColorChooser = chooser
+}
+
 func (t *TipManager) AnalyzeMessage(messageID MessageID) {
 	message := t.tangle.Storage.Message(messageID)
+	if t.tipFilter != nil && !t.tipFilter(message) {
+		return
+	}
 	messageMetadata := t.tangle.Storage.MessageMetadata(messageID)
 	inheritedColor := messageMetadata.InheritedColor()
+	if inheritedColor != UndefinedColor {
+		t.latestBranchMessage[inheritedColor] = messageID
+	}
+	if config.PastConeCheckEnabled {
+		t.recordPastConeColors(message, inheritedColor)
+	}
 	tipSet := t.TipSet(inheritedColor)
 	// Calculate the current tip pool size before calling AddStrongTip
 	currentTipPoolSize := tipSet.strongTips.Size()
@@ -80,12 +158,13 @@ func (t *TipManager) AnalyzeMessage(messageID MessageID) {
 	t.Events.MessageProcessed.Trigger(inheritedColor, currentTipPoolSize,
 		t.msgProcessedCounter[inheritedColor], messageIDCounter)
 
-	// Remove the weak tip codes
-	// for color, tipSet := range t.TipSets(inheritedColor) {
-	// 	if !addedAsStrongTip[color] {
-	// 		tipSet.AddWeakTip(message)
-	// 	}
-	// }
+	// the tip sets of the other branches keep the message as a weak tip, so their messages can
+	// reference it without inheriting its branch
+	for color, tipSet := range t.tipSets {
+		if !addedAsStrongTip[color] {
+			tipSet.AddWeakTip(message)
+		}
+	}
 }
 
 func (t *TipManager) TipSets(color Color) map[Color]*TipSet {
@@ -127,43 +206,148 @@ func (t *TipManager) GetTip(messageID interface{}) (height int, true bool) {
 	// else cast interface to Message type, index and return height
 }
 
-func (t *TipManager) Tips() (strongTips MessageIDs) {
+// Tips returns the strong and weak parents of the next message. The split between them follows
+// config.WeakTipsRatio; whenever one of the pools cannot fill its share, the other one fills up the
+// parent count. How many parents are targeted follows config.ParentsCountPolicy.
+func (t *TipManager) Tips() (strongTips MessageIDs, weakTips MessageIDs) {
 	// The tips is selected from the tipSet of the current ownOpinion
-	tipSet := t.TipSet(t.tangle.OpinionManager.Opinion())
+	tipSetColor := t.tangle.OpinionManager.Opinion()
+	if t.tipColorChooser != nil {
+		tipSetColor = t.tipColorChooser()
+	}
+	tipSet := t.TipSet(tipSetColor)
+	parentsCount := t.parentsCountTarget(tipSet)
+
+	strongSampleCount := parentsCount
+	if config.ParentsCountPolicy == "exact" {
+		// oversample so the past-cone filter and short random draws still leave the full count
+		// whenever the pool holds enough distinct tips
+		strongSampleCount = 2 * parentsCount
+	}
+	strongTips = tipSet.StrongTips(strongSampleCount, t.tsa)
+	// the quality check refuses strong parents whose past cone approves a disliked branch, which the
+	// tip set split by inherited color alone cannot see across conflict sets
+	if config.PastConeCheckEnabled {
+		for strongTip := range strongTips {
+			if !t.pastConeLiked(strongTip) {
+				delete(strongTips, strongTip)
+			}
+		}
+		if len(strongTips) == 0 {
+			strongTips = NewMessageIDs(Genesis)
+		}
+	}
+	if len(strongTips) > parentsCount {
+		strongTips.Trim(parentsCount)
+	}
+	weakTips = tipSet.WeakTips(parentsCount-1, t.tsa)
 
-	strongTips = tipSet.StrongTips(config.ParentsCount, t.tsa)
-	// In the paper we consider all strong tips
-	// weakTips = tipSet.WeakTips(config.ParentsCount-1, t.tsa)
+	if len(weakTips) == 0 {
+		return
+	}
 
-	// Remove the weakTips-related codes
-	// if len(weakTips) == 0 {
-	// 	return
-	// }
+	optimalStrongParentsCount := int(float64(parentsCount) * (1 - config.WeakTipsRatio))
+	optimalWeakParentsCount := int(float64(parentsCount) * config.WeakTipsRatio)
 
-	// if strongParentsCount := len(strongTips); strongParentsCount < OptimalStrongParentsCount {
-	// 	fillUpCount := config.ParentsCount - strongParentsCount
+	if strongParentsCount := len(strongTips); strongParentsCount < optimalStrongParentsCount {
+		fillUpCount := parentsCount - strongParentsCount
 
-	// 	if fillUpCount >= len(weakTips) {
-	// 		return
-	// 	}
+		if fillUpCount >= len(weakTips) {
+			return
+		}
 
-	// 	weakTips.Trim(fillUpCount)
-	// 	return
-	// }
+		weakTips.Trim(fillUpCount)
+		return
+	}
 
-	// if weakParentsCount := len(weakTips); weakParentsCount < OptimalWeakParentsCount {
-	// 	fillUpCount := config.ParentsCount - weakParentsCount
+	if weakParentsCount := len(weakTips); weakParentsCount < optimalWeakParentsCount {
+		fillUpCount := parentsCount - weakParentsCount
 
-	// 	if fillUpCount >= len(strongTips) {
-	// 		return
-	// 	}
+		if fillUpCount >= len(strongTips) {
+			return
+		}
 
-	// 	strongTips.Trim(fillUpCount)
-	// 	return
-	// }
+		strongTips.Trim(fillUpCount)
+		return
+	}
 
-	// strongTips.Trim(OptimalStrongParentsCount)
-	// weakTips.Trim(OptimalWeakParentsCount)
+	strongTips.Trim(optimalStrongParentsCount)
+	weakTips.Trim(optimalWeakParentsCount)
+
+	return
+}
+
+// parentsCountTarget returns how many parents the next message aims for. With the exact and up-to
+// policies this is ParentsCount; the adaptive policy scales the count with the size of the strong
+// tip pool, so a growing pool is drained with more references per message.
+func (t *TipManager) parentsCountTarget(tipSet *TipSet) (parentsCount int) {
+	if config.ParentsCountPolicy != "adaptive" {
+		return config.ParentsCount
+	}
+
+	parentsCount = (tipSet.strongTips.Size() + config.AdaptiveParentsTipsPerParent - 1) / config.AdaptiveParentsTipsPerParent
+	if parentsCount < 1 {
+		parentsCount = 1
+	}
+	if parentsCount > config.ParentsCount {
+		parentsCount = config.ParentsCount
+	}
+
+	return
+}
+
+// TipPoolSize returns the number of strong tips in the tip set the node currently attaches to, the
+// congestion signal of the rate setter.
+func (t *TipManager) TipPoolSize() int {
+	return t.TipSet(t.tangle.OpinionManager.Opinion()).strongTips.Size()
+}
+
+// recordPastConeColors accumulates the colors a message approves through its strong parents. The
+// colors of a past cone are static once the message is booked, so one union per message replaces a
+// full cone walk at every tip selection.
+func (t *TipManager) recordPastConeColors(message *Message, inheritedColor Color) {
+	colors := make(map[Color]types.Empty)
+	for strongParent := range message.StrongParents {
+		for color := range t.pastConeColors[strongParent] {
+			colors[color] = types.Void
+		}
+	}
+	if inheritedColor != UndefinedColor {
+		colors[inheritedColor] = types.Void
+	}
+
+	if len(colors) > 0 {
+		t.pastConeColors[message.ID] = colors
+	}
+}
+
+// pastConeLiked reports whether the message approves no branch the node currently dislikes, see
+// config.PastConeCheckEnabled.
+func (t *TipManager) pastConeLiked(messageID MessageID) bool {
+	for color := range t.pastConeColors[messageID] {
+		if opinion := t.tangle.OpinionManager.OpinionOf(color.ConflictSet()); opinion != UndefinedColor && opinion != color {
+			return false
+		}
+	}
+
+	return true
+}
+
+// LikeTips returns for every conflict set the node has an opinion on a message of the liked branch, to
+// be referenced with a shallow like. The likes express the node's conflict opinions independent of
+// what the message's other parents vote for.
+func (t *TipManager) LikeTips() (likeTips MessageIDs) {
+	likeTips = make(MessageIDs)
+	for _, set := range ConflictSets() {
+		opinion := t.tangle.OpinionManager.OpinionOf(set)
+		if opinion == UndefinedColor {
+			continue
+		}
+
+		if likedMessageID, exists := t.latestBranchMessage[opinion]; exists {
+			likeTips.Add(likedMessageID)
+		}
+	}
 
 	return
 }
@@ -174,22 +358,22 @@ func (t *TipManager) Tips() (strongTips MessageIDs) {
 
 type TipSet struct {
 	strongTips *randommap.RandomMap
-	//weakTips   *randommap.RandomMap
+	weakTips   *randommap.RandomMap
 }
 
 func NewTipSet(tipsToInherit *TipSet) (tipSet *TipSet) {
 	tipSet = &TipSet{
 		strongTips: randommap.New(),
-		//weakTips:   randommap.New(),
+		weakTips:   randommap.New(),
 	}
 
 	if tipsToInherit != nil {
 		tipsToInherit.strongTips.ForEach(func(key interface{}, value interface{}) {
 			tipSet.strongTips.Set(key, value)
 		})
-		// tipsToInherit.weakTips.ForEach(func(key interface{}, value interface{}) {
-		// 	tipSet.weakTips.Set(key, value)
-		// })
+		tipsToInherit.weakTips.ForEach(func(key interface{}, value interface{}) {
+			tipSet.weakTips.Set(key, value)
+		})
 	}
 
 	return
@@ -219,14 +403,14 @@ func (t *TipSet) AddStrongTip(message *Message) {
 		t.strongTips.Delete(strongParent)
 	}
 
-	// for weakParent := range message.WeakParents {
-	// 	t.weakTips.Delete(weakParent)
-	// }
+	for weakParent := range message.WeakParents {
+		t.weakTips.Delete(weakParent)
+	}
 }
 
-// func (t *TipSet) AddWeakTip(message *Message) {
-// 	t.weakTips.Set(message.ID, message)
-// }
+func (t *TipSet) AddWeakTip(message *Message) {
+	t.weakTips.Set(message.ID, message)
+}
 
 // func (t *TipSet) GetTip(messageID interface{}) (interface{}, bool) {
 // 	// either create a new tipsset and copy over all tips
@@ -251,18 +435,18 @@ func (t *TipSet) StrongTips(maxAmount int, tsa TipSelector) (strongTips MessageI
 	return
 }
 
-// func (t *TipSet) WeakTips(maxAmount int, tsa TipSelector) (weakTips MessageIDs) {
-// 	if t.weakTips.Size() == 0 {
-// 		return
-// 	}
+func (t *TipSet) WeakTips(maxAmount int, tsa TipSelector) (weakTips MessageIDs) {
+	if t.weakTips.Size() == 0 {
+		return
+	}
 
-// 	weakTips = make(MessageIDs)
-// 	for _, weakTip := range tsa.TipSelect(t.weakTips, maxAmount) {
-// 		weakTips.Add(weakTip.(*Message).ID)
-// 	}
+	weakTips = make(MessageIDs)
+	for _, weakTip := range tsa.TipSelect(t.weakTips, maxAmount) {
+		weakTips.Add(weakTip.(*Message).ID)
+	}
 
-// 	return
-// }
+	return
+}
 
 // endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
 
@@ -286,6 +470,13 @@ type RURTS struct {
 	TipSelector
 }
 
+// MRTS implements mana-weighted random tip selection, where a tip is picked with probability
+// proportional to its issuer's consensus mana, to compare against the uniform selection
+type MRTS struct {
+	TipSelector
+	tangle *Tangle
+}
+
 // endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 func (POW) TipSelect(tips *randommap.RandomMap, maxAmount int) []interface{} {
@@ -331,12 +522,13 @@ func (RURTS) TipSelect(tips *randommap.RandomMap, maxAmount int) []interface{} {
 		}
 
 		// Get the current time
-		currentTime := time.Now()
+		currentTime := TimeSource.Now()
 		for _, tip := range tipsNew {
 
 			// If the time difference is greater than DeltaURTS, delete it from tips
 			if currentTime.Sub(tip.(*Message).IssuanceTime).Seconds() > config.DeltaURTS {
-				tips.Delete(tip)
+				tips.Delete(tip.(*Message).ID)
+				countAgedOutTip()
 			} else {
 				// Append the valid tip to tipsToReturn and decrease the amountLeft
 				tipsToReturn = append(tipsToReturn, tip)
@@ -354,6 +546,44 @@ func (RURTS) TipSelect(tips *randommap.RandomMap, maxAmount int) []interface{} {
 
 }
 
+// TipSelect selects up to maxAmount distinct tips, each picked with probability proportional to the
+// consensus mana of its issuer. Tips of zero-mana issuers keep a minimal chance, so they are biased
+// against but not orphaned by design.
+func (m MRTS) TipSelect(tips *randommap.RandomMap, maxAmount int) []interface{} {
+	type weightedTip struct {
+		message *Message
+		weight  float64
+	}
+
+	candidates := make([]weightedTip, 0, tips.Size())
+	totalWeight := 0.0
+	tips.ForEach(func(key, value interface{}) {
+		message := value.(*Message)
+		weight := math.Max(float64(m.tangle.WeightDistribution.Weight(message.Issuer)), 1)
+		candidates = append(candidates, weightedTip{message: message, weight: weight})
+		totalWeight += weight
+	})
+
+	var tipsToReturn []interface{}
+	for len(tipsToReturn) < maxAmount && len(candidates) > 0 {
+		target := crypto.Randomness.Float64() * totalWeight
+		selected := len(candidates) - 1
+		for i, candidate := range candidates {
+			if target -= candidate.weight; target <= 0 {
+				selected = i
+				break
+			}
+		}
+
+		tipsToReturn = append(tipsToReturn, candidates[selected].message)
+		totalWeight -= candidates[selected].weight
+		candidates[selected] = candidates[len(candidates)-1]
+		candidates = candidates[:len(candidates)-1]
+	}
+
+	return tipsToReturn
+}
+
 // endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 // region TipManagerEvents /////////////////////////////////////////////////////////////////////////////////////////
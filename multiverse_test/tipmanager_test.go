@@ -26,7 +26,7 @@ func TestTipManager(t *testing.T) {
 		network.HonestNode: network.NodeClosure(multiverse.NewNode),
 	}
 	testNetwork := network.New(
-		network.Nodes(nodeCount, nodeFactories, network.ZIPFDistribution(config.ZipfParameter, float64(config.NodesTotalWeight))),
+		network.Nodes(nodeCount, nodeFactories, network.ZIPFDistribution(config.ZipfParameter)),
 		network.Delay(30*time.Millisecond, 250*time.Millisecond),
 		network.PacketLoss(0, 0.05),
 		network.Topology(network.WattsStrogatz(4, 1)),
@@ -47,7 +47,7 @@ func monitorNetworkState(testNetwork *network.Network) {
 	for _, id := range config.MonitoredAWPeers {
 		awPeer := testNetwork.Peers[id]
 		awPeer.Node.(multiverse.NodeInterface).Tangle().ApprovalManager.Events.MessageConfirmed.Attach(
-			events.NewClosure(func(message *multiverse.Message, messageMetadata *multiverse.MessageMetadata, weight uint64) {
+			events.NewClosure(func(message *multiverse.Message, messageMetadata *multiverse.MessageMetadata, weight uint64, messageIDCounter int64) {
 				atomic.AddInt64(&confirmedMessageCounter, 1)
 			}))
 	}
@@ -0,0 +1,43 @@
+package multiverse
+
+import (
+	"testing"
+
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeakTipSelection(t *testing.T) {
+	tipSet := multiverse.NewTipSet(nil)
+
+	strongMessage := &multiverse.Message{
+		ID:            multiverse.NewMessageID(),
+		StrongParents: multiverse.NewMessageIDs(multiverse.Genesis),
+	}
+	weakMessage := &multiverse.Message{
+		ID:            multiverse.NewMessageID(),
+		StrongParents: multiverse.NewMessageIDs(multiverse.Genesis),
+	}
+
+	tipSet.AddStrongTip(strongMessage)
+	tipSet.AddWeakTip(weakMessage)
+
+	strongTips := tipSet.StrongTips(2, multiverse.URTS{})
+	assert.Len(t, strongTips, 1, "The strong tip pool should only offer the strong message!")
+	assert.Contains(t, strongTips, strongMessage.ID)
+
+	weakTips := tipSet.WeakTips(2, multiverse.URTS{})
+	assert.Len(t, weakTips, 1, "The weak tip pool should only offer the weak message!")
+	assert.Contains(t, weakTips, weakMessage.ID)
+
+	// referencing a weak tip as weak parent removes it from the weak tip pool
+	childMessage := &multiverse.Message{
+		ID:            multiverse.NewMessageID(),
+		StrongParents: multiverse.NewMessageIDs(strongMessage.ID),
+		WeakParents:   multiverse.NewMessageIDs(weakMessage.ID),
+	}
+	tipSet.AddStrongTip(childMessage)
+
+	assert.Len(t, tipSet.StrongTips(2, multiverse.URTS{}), 1, "The child message should replace its strong parent as tip!")
+	assert.Empty(t, tipSet.WeakTips(2, multiverse.URTS{}), "The referenced weak tip should leave the weak tip pool!")
+}
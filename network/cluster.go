@@ -0,0 +1,90 @@
+package network
+
+import (
+	"time"
+
+	"github.com/iotaledger/hive.go/crypto"
+)
+
+// region Cluster topology /////////////////////////////////////////////////////////////////////////////////////////////
+
+// Clusters builds a hierarchical multi-region topology: the nodes are split into contiguous clusters,
+// densely connected inside a cluster with a low fixed delay, and sparsely connected between clusters
+// with a high fixed delay and extra packet loss, modelling geo-distributed validator clusters.
+// intraNeighbors is the number of ring neighbors per node inside its cluster, interLinks the number of
+// random links between every pair of clusters.
+func Clusters(clusterSizes []int, intraNeighbors int, interLinks int, intraDelay time.Duration, interDelay time.Duration, interPacketLoss float64) PeeringStrategy {
+	return func(network *Network, configuration *Configuration) {
+		nodeCount := len(network.Peers)
+
+		clusterTotal := 0
+		for _, size := range clusterSizes {
+			if size < 2 {
+				log.Fatalf("Cluster sizes need at least 2 nodes, got %d", size)
+			}
+			clusterTotal += size
+		}
+		if clusterTotal != nodeCount {
+			log.Fatalf("The cluster sizes sum to %d nodes instead of %d", clusterTotal, nodeCount)
+		}
+
+		clusters := make([][]int, 0, len(clusterSizes))
+		nextNodeID := 0
+		for _, size := range clusterSizes {
+			cluster := make([]int, 0, size)
+			for i := 0; i < size; i++ {
+				cluster = append(cluster, nextNodeID)
+				nextNodeID++
+			}
+			clusters = append(clusters, cluster)
+		}
+
+		connect := func(tail int, head int, delay time.Duration, packetLoss float64) {
+			tailConnection := NewConnection(network.Peers[tail], network.Peers[head], delay, packetLoss, configuration)
+			headConnection := NewConnection(network.Peers[head], network.Peers[tail], delay, packetLoss, configuration)
+			tailConnection.FixDelay()
+			headConnection.FixDelay()
+			network.Peers[tail].Neighbors[PeerID(head)] = tailConnection
+			network.Peers[head].Neighbors[PeerID(tail)] = headConnection
+		}
+
+		// dense intra-cluster ring lattice, like WattsStrogatz without rewiring
+		for _, cluster := range clusters {
+			ringNeighbors := intraNeighbors / 2
+			if ringNeighbors > (len(cluster)-1)/2 {
+				ringNeighbors = (len(cluster) - 1) / 2
+			}
+			if ringNeighbors < 1 {
+				ringNeighbors = 1
+			}
+			for i := range cluster {
+				for offset := 1; offset <= ringNeighbors; offset++ {
+					connect(cluster[i], cluster[(i+offset)%len(cluster)], intraDelay, configuration.RandomPacketLoss())
+				}
+			}
+		}
+
+		// sparse random inter-cluster links with high delay and extra loss
+		for tailCluster := 0; tailCluster < len(clusters); tailCluster++ {
+			for headCluster := tailCluster + 1; headCluster < len(clusters); headCluster++ {
+				for link := 0; link < interLinks; link++ {
+					tail := clusters[tailCluster][crypto.Randomness.Intn(len(clusters[tailCluster]))]
+					head := clusters[headCluster][crypto.Randomness.Intn(len(clusters[headCluster]))]
+					if _, connected := network.Peers[tail].Neighbors[PeerID(head)]; connected {
+						continue
+					}
+					connect(tail, head, interDelay, interPacketLoss)
+				}
+			}
+		}
+
+		totalNeighborCount := 0
+		for _, peer := range network.Peers {
+			totalNeighborCount += len(peer.Neighbors)
+		}
+		log.Infof("Built %d clusters, average number of neighbors: %.1f",
+			len(clusters), float64(totalNeighborCount)/float64(nodeCount))
+	}
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -41,6 +41,11 @@ type ConsensusWeightDistribution struct {
 	weights       map[PeerID]uint64
 	totalWeight   uint64
 	largestWeight uint64
+
+	// frozen copy of the weights taken at the last epoch boundary, see SealEpoch
+	epochWeights     map[PeerID]uint64
+	epochTotalWeight uint64
+	epochSealed      bool
 }
 
 func NewConsensusWeightDistribution() *ConsensusWeightDistribution {
@@ -78,6 +83,42 @@ func (c *ConsensusWeightDistribution) LargestWeight() uint64 {
 	return c.largestWeight
 }
 
+// SealEpoch freezes the current weights as the epoch-consistent view the consensus components read
+// through EpochWeight and EpochTotalWeight, so weight changes during an epoch only take effect at the
+// next epoch boundary.
+func (c *ConsensusWeightDistribution) SealEpoch() {
+	c.epochWeights = make(map[PeerID]uint64, len(c.weights))
+	for peerID, weight := range c.weights {
+		c.epochWeights[peerID] = weight
+	}
+	c.epochTotalWeight = c.totalWeight
+	c.epochSealed = true
+}
+
+// EpochSealed reports whether an epoch boundary has frozen the weights yet; before the first seal the
+// epoch view falls through to the live weights.
+func (c *ConsensusWeightDistribution) EpochSealed() bool {
+	return c.epochSealed
+}
+
+// EpochWeight returns the peer's weight as of the last epoch boundary, the live weight while no epoch
+// has been sealed.
+func (c *ConsensusWeightDistribution) EpochWeight(peerID PeerID) uint64 {
+	if !c.epochSealed {
+		return c.weights[peerID]
+	}
+	return c.epochWeights[peerID]
+}
+
+// EpochTotalWeight returns the total weight as of the last epoch boundary, the live total while no
+// epoch has been sealed.
+func (c *ConsensusWeightDistribution) EpochTotalWeight() uint64 {
+	if !c.epochSealed {
+		return c.totalWeight
+	}
+	return c.epochTotalWeight
+}
+
 func (c *ConsensusWeightDistribution) rescanForLargestWeight() {
 	c.largestWeight = 0
 	for _, weight := range c.weights {
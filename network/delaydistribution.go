@@ -0,0 +1,84 @@
+package network
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/hive.go/crypto"
+)
+
+// region Delay distribution ///////////////////////////////////////////////////////////////////////////////////////////
+
+// Internet latency is heavy-tailed, which the uniform Min/MaxDelay range cannot capture. The delay
+// sampling can therefore be replaced with a named distribution, configured as 'name:param:param...'.
+
+// delaySampler draws one network delay.
+type delaySampler func() time.Duration
+
+// DelayDistribution replaces the uniform delay sampling of the configuration with the distribution
+// given as 'name:param:param...'. Supported specs:
+//
+//	uniform                — the Min/MaxDelay range (the default)
+//	lognormal:mu:sigma     — exp(Normal(mu, sigma)) ms, mu and sigma in log-ms
+//	pareto:scaleMs:alpha   — Pareto with the given scale in ms and tail index alpha
+//	bimodal:p:lowMs:highMs — highMs with probability p, lowMs otherwise
+//
+// The sampled delays are scaled by the slowdown factor like the Min/MaxDelay range.
+func DelayDistribution(spec string, slowdownFactor int) Option {
+	return func(configuration *Configuration) {
+		configuration.delaySampler = parseDelayDistribution(spec, slowdownFactor)
+	}
+}
+
+func parseDelayDistribution(spec string, slowdownFactor int) delaySampler {
+	parts := strings.Split(spec, ":")
+	params := make([]float64, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			log.Fatalf("Delay distribution %s has a non-numeric parameter %s", spec, part)
+		}
+		params = append(params, value)
+	}
+	scale := float64(slowdownFactor) * float64(time.Millisecond)
+
+	switch parts[0] {
+	case "uniform":
+		return nil
+	case "lognormal":
+		if len(params) != 2 {
+			log.Fatalf("The lognormal delay distribution needs 'lognormal:mu:sigma', got %s", spec)
+		}
+		mu, sigma := params[0], params[1]
+		return func() time.Duration {
+			return time.Duration(math.Exp(mu+sigma*crypto.Randomness.NormFloat64()) * scale)
+		}
+	case "pareto":
+		if len(params) != 2 {
+			log.Fatalf("The pareto delay distribution needs 'pareto:scaleMs:alpha', got %s", spec)
+		}
+		scaleMs, alpha := params[0], params[1]
+		return func() time.Duration {
+			return time.Duration(scaleMs * math.Pow(1-crypto.Randomness.Float64(), -1/alpha) * scale)
+		}
+	case "bimodal":
+		if len(params) != 3 {
+			log.Fatalf("The bimodal delay distribution needs 'bimodal:p:lowMs:highMs', got %s", spec)
+		}
+		p, lowMs, highMs := params[0], params[1], params[2]
+		return func() time.Duration {
+			if crypto.Randomness.Float64() < p {
+				return time.Duration(highMs * scale)
+			}
+			return time.Duration(lowMs * scale)
+		}
+	default:
+		log.Fatalf("Unknown delay distribution %s, supported are uniform, lognormal, pareto, bimodal", parts[0])
+	}
+
+	return nil
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,54 @@
+package network
+
+// region Eclipse //////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// ApplyEclipse hands connections of the victim peer over to the adversary: up to connectionCount of the
+// victim's honest links are dropped and replaced with links to adversary nodes, so the adversary
+// controls the victim's view of the tangle. A connectionCount of 0 eclipses the victim completely.
+func ApplyEclipse(n *Network, victimID int, connectionCount int) {
+	victim := n.Peers[victimID]
+
+	adversaryIDs := make([]int, 0)
+	for _, peer := range n.Peers {
+		if IsAdversary(int(peer.ID)) {
+			adversaryIDs = append(adversaryIDs, int(peer.ID))
+		}
+	}
+	if len(adversaryIDs) == 0 {
+		log.Fatalf("An eclipse attack needs adversary nodes, see -adversaryNodeCounts")
+	}
+
+	removed := 0
+	for neighborID, connection := range victim.Neighbors {
+		if IsAdversary(int(neighborID)) {
+			continue
+		}
+		if connectionCount > 0 && removed >= connectionCount {
+			break
+		}
+
+		connection.Shutdown()
+		n.Peers[neighborID].Neighbors[victim.ID].Shutdown()
+		delete(victim.Neighbors, neighborID)
+		delete(n.Peers[neighborID].Neighbors, victim.ID)
+		removed++
+	}
+
+	added := 0
+	for i := 0; added < removed && i < removed; i++ {
+		adversaryID := adversaryIDs[i%len(adversaryIDs)]
+		if _, connected := victim.Neighbors[PeerID(adversaryID)]; connected {
+			continue
+		}
+
+		networkDelay := n.Configuration.RandomNetworkDelay()
+		packetLoss := n.Configuration.RandomPacketLoss()
+		victim.Neighbors[PeerID(adversaryID)] = NewConnection(victim, n.Peers[adversaryID], networkDelay, packetLoss, n.Configuration)
+		n.Peers[adversaryID].Neighbors[victim.ID] = NewConnection(n.Peers[adversaryID], victim, networkDelay, packetLoss, n.Configuration)
+		added++
+	}
+
+	log.Infof("Eclipsed %s: replaced %d honest connections with %d adversary connections", victim, removed, added)
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -2,6 +2,7 @@ package network
 
 import (
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/iotaledger/hive.go/crypto"
@@ -18,6 +19,17 @@ const (
 	ShiftOpinion
 	TheSameOpinion
 	NoGossip
+	Balancing
+	Censorship
+	Withholding
+	Orphanage
+	NothingAtStake
+	Targeting
+	Scripted
+	TimestampForger
+	Parasite
+	Replay
+	Stealth
 )
 
 func ToAdversaryType(adv int) AdversaryType {
@@ -28,6 +40,28 @@ func ToAdversaryType(adv int) AdversaryType {
 		return TheSameOpinion
 	case int(NoGossip):
 		return NoGossip
+	case int(Balancing):
+		return Balancing
+	case int(Censorship):
+		return Censorship
+	case int(Withholding):
+		return Withholding
+	case int(Orphanage):
+		return Orphanage
+	case int(NothingAtStake):
+		return NothingAtStake
+	case int(Targeting):
+		return Targeting
+	case int(Scripted):
+		return Scripted
+	case int(TimestampForger):
+		return TimestampForger
+	case int(Parasite):
+		return Parasite
+	case int(Replay):
+		return Replay
+	case int(Stealth):
+		return Stealth
 	default:
 		return HonestNode
 	}
@@ -43,6 +77,28 @@ func AdversaryTypeToString(adv AdversaryType) string {
 		return "TheSameOpinion"
 	case NoGossip:
 		return "NoGossip"
+	case Balancing:
+		return "Balancing"
+	case Censorship:
+		return "Censorship"
+	case Withholding:
+		return "Withholding"
+	case Orphanage:
+		return "Orphanage"
+	case NothingAtStake:
+		return "NothingAtStake"
+	case Targeting:
+		return "Targeting"
+	case Scripted:
+		return "Scripted"
+	case TimestampForger:
+		return "TimestampForger"
+	case Parasite:
+		return "Parasite"
+	case Replay:
+		return "Replay"
+	case Stealth:
+		return "Stealth"
 	}
 	return ""
 }
@@ -53,6 +109,16 @@ func AdversaryTypeToString(adv AdversaryType) string {
 
 var AdversaryNodeIDToGroupIDMap = make(map[int]int)
 
+// extraAdversaryLinks counts the connections the adversary peering options created on top of the
+// links the topology gave the adversary nodes, the connection cost of the attack.
+var extraAdversaryLinks int
+
+// ExtraAdversaryLinks returns how many connections the adversary peering options added on top of the
+// topology.
+func ExtraAdversaryLinks() int {
+	return extraAdversaryLinks
+}
+
 func IsAdversary(nodeID int) bool {
 	_, ok := AdversaryNodeIDToGroupIDMap[nodeID]
 	return ok
@@ -66,6 +132,17 @@ type AdversaryGroup struct {
 	AdversaryType        AdversaryType
 	InitColor            string
 	NodeCount            int
+
+	// ActivationTime is how far into the run the group becomes active; before that its members stay
+	// completely silent. Zero activates the group right from the start.
+	ActivationTime time.Duration
+
+	// Composition is the full list of behaviors layered onto the group's nodes; it is only set when
+	// extra behaviors are composed on top of AdversaryType, see adversary.CompositeNode.
+	Composition []AdversaryType
+
+	// Sybil marks the group of identities injected mid-run, see ActivateSybils
+	Sybil bool
 }
 
 func (g *AdversaryGroup) AddNodeID(id, groupId int) {
@@ -95,6 +172,11 @@ func NewAdversaryGroups() (groups AdversaryGroups) {
 			nCount = config.AdversaryNodeCounts[i]
 		}
 
+		activation := 0
+		if len(config.AdversaryActivationTimes) > 0 {
+			activation = config.AdversaryActivationTimes[i]
+		}
+
 		color = config.AdversaryInitColors[i]
 		group := &AdversaryGroup{
 			NodeIDs:              make([]int, 0, nCount),
@@ -103,10 +185,46 @@ func NewAdversaryGroups() (groups AdversaryGroups) {
 			AdversaryType:        ToAdversaryType(configAdvType),
 			InitColor:            color,
 			NodeCount:            nCount,
+			ActivationTime:       time.Second * time.Duration(activation),
+		}
+		if len(config.AdversaryCompositions) > 0 {
+			if extras := parseComposition(config.AdversaryCompositions[i]); len(extras) > 0 {
+				group.Composition = append([]AdversaryType{group.AdversaryType}, extras...)
+			}
 		}
 		groups = append(groups, group)
 	}
 
+	// the sybil identities injected mid-run start offline and without any mana; they get their share
+	// of the total weight when ActivateSybils brings them online
+	if config.SybilCount > 0 && config.SimulationMode == "Adversary" {
+		groups = append(groups, &AdversaryGroup{
+			NodeIDs:       make([]int, 0, config.SybilCount),
+			Delay:         time.Millisecond * time.Duration(config.MinDelay),
+			AdversaryType: ToAdversaryType(config.SybilType),
+			InitColor:     config.SybilInitColor,
+			NodeCount:     config.SybilCount,
+			Sybil:         true,
+		})
+	}
+
+	return
+}
+
+// parseComposition turns a '+'-joined list of strategy codes (e.g. '3+7') into the adversary types
+// layered on top of the group's own type; '0' layers nothing.
+func parseComposition(entry string) (types []AdversaryType) {
+	for _, code := range strings.Split(entry, "+") {
+		value, err := strconv.Atoi(code)
+		if err != nil {
+			log.Warnf("Ignoring invalid strategy code %q in adversary composition %q", code, entry)
+			continue
+		}
+		if value == int(HonestNode) {
+			continue
+		}
+		types = append(types, ToAdversaryType(value))
+	}
 	return
 }
 
@@ -156,6 +274,133 @@ func (g *AdversaryGroups) updateGroupMana() {
 	}
 }
 
+// ActivateSybils brings the nodes of the sybil group online and hands them manaPercentage% of the
+// total weight, taken proportionally from the honest nodes so the total weight stays constant. It
+// returns the IDs of the activated nodes.
+func (g *AdversaryGroups) ActivateSybils(network *Network, manaPercentage float64) (activated []int) {
+	for _, group := range *g {
+		if !group.Sybil {
+			continue
+		}
+
+		grantedWeight := manaPercentage * float64(config.NodesTotalWeight) / 100.0
+		honestWeight := uint64(0)
+		for _, peer := range network.Peers {
+			if !IsAdversary(int(peer.ID)) {
+				honestWeight += network.WeightDistribution.Weight(peer.ID)
+			}
+		}
+		if honestWeight <= uint64(grantedWeight) {
+			log.Warnf("Sybil injection needs %.0f weight but the honest nodes only hold %d, skipped", grantedWeight, honestWeight)
+			continue
+		}
+
+		// scale the honest nodes down to free up the granted weight
+		scale := (float64(honestWeight) - grantedWeight) / float64(honestWeight)
+		for _, peer := range network.Peers {
+			if !IsAdversary(int(peer.ID)) {
+				network.WeightDistribution.SetWeight(peer.ID, uint64(float64(network.WeightDistribution.Weight(peer.ID))*scale))
+			}
+		}
+
+		group.GroupMana = grantedWeight
+		for _, nodeID := range group.NodeIDs {
+			peer := network.Peer(nodeID)
+			network.WeightDistribution.SetWeight(peer.ID, uint64(grantedWeight/float64(len(group.NodeIDs))))
+			peer.SetOffline(false)
+			activated = append(activated, nodeID)
+		}
+	}
+
+	return
+}
+
+// GrowMana applies one step of the configured mana acquisition schedule: every group with a growth rate
+// gains rate% of NodesTotalWeight per simulated minute, taken proportionally from the honest nodes so the
+// total weight stays constant.
+func (g *AdversaryGroups) GrowMana(network *Network, elapsed time.Duration) {
+	for groupIndex, group := range *g {
+		if groupIndex >= len(config.AdversaryManaGrowthRate) || config.AdversaryManaGrowthRate[groupIndex] == 0 {
+			continue
+		}
+
+		delta := config.AdversaryManaGrowthRate[groupIndex] / 100.0 * float64(config.NodesTotalWeight) * elapsed.Minutes()
+		if delta <= 0 {
+			continue
+		}
+
+		honestWeight := uint64(0)
+		for _, peer := range network.Peers {
+			if !IsAdversary(int(peer.ID)) {
+				honestWeight += network.WeightDistribution.Weight(peer.ID)
+			}
+		}
+		if honestWeight <= uint64(delta) {
+			log.Warnf("Adversary group %d mana growth exhausted the honest weight, growth stopped", groupIndex)
+			continue
+		}
+
+		// scale the honest nodes down to free up the acquired weight
+		scale := (float64(honestWeight) - delta) / float64(honestWeight)
+		for _, peer := range network.Peers {
+			if !IsAdversary(int(peer.ID)) {
+				network.WeightDistribution.SetWeight(peer.ID, uint64(float64(network.WeightDistribution.Weight(peer.ID))*scale))
+			}
+		}
+
+		group.GroupMana += delta
+		for _, nodeID := range group.NodeIDs {
+			peerID := PeerID(nodeID)
+			network.WeightDistribution.SetWeight(peerID, network.WeightDistribution.Weight(peerID)+uint64(delta/float64(len(group.NodeIDs))))
+		}
+	}
+}
+
+// RetargetMana moves every non-sybil adversary group's weight to targetPercentage% of the total
+// weight, taking the difference proportionally from the honest nodes or handing it back to them
+// when the schedule ramps down.
+func (g *AdversaryGroups) RetargetMana(network *Network, targetPercentage float64) {
+	for groupIndex, group := range *g {
+		if group.Sybil || len(group.NodeIDs) == 0 {
+			continue
+		}
+
+		target := targetPercentage / 100.0 * float64(config.NodesTotalWeight)
+		current := float64(0)
+		for _, nodeID := range group.NodeIDs {
+			current += float64(network.WeightDistribution.Weight(PeerID(nodeID)))
+		}
+		delta := target - current
+		if delta == 0 {
+			continue
+		}
+
+		honestWeight := uint64(0)
+		for _, peer := range network.Peers {
+			if !IsAdversary(int(peer.ID)) {
+				honestWeight += network.WeightDistribution.Weight(peer.ID)
+			}
+		}
+		if delta > 0 && float64(honestWeight) <= delta {
+			log.Warnf("Adversary group %d mana retarget needs %.0f weight but the honest nodes only hold %d, skipped", groupIndex, delta, honestWeight)
+			continue
+		}
+
+		// scale the honest nodes to free up the acquired weight, or to absorb the released one
+		scale := (float64(honestWeight) - delta) / float64(honestWeight)
+		for _, peer := range network.Peers {
+			if !IsAdversary(int(peer.ID)) {
+				network.WeightDistribution.SetWeight(peer.ID, uint64(float64(network.WeightDistribution.Weight(peer.ID))*scale))
+			}
+		}
+
+		group.GroupMana = target
+		for _, nodeID := range group.NodeIDs {
+			network.WeightDistribution.SetWeight(PeerID(nodeID), uint64(target/float64(len(group.NodeIDs))))
+		}
+	}
+}
+
 func (g *AdversaryGroups) ApplyNetworkDelayForAdversaryNodes(network *Network) {
 	for _, adversaryGroup := range *g {
 		for _, nodeID := range adversaryGroup.NodeIDs {
@@ -172,8 +417,12 @@ func (g *AdversaryGroups) ApplyNeighborsAdversaryNodes(network *Network, configu
 		for _, nodeID := range adversaryGroup.NodeIDs {
 			adversary := network.Peer(nodeID)
 			for _, peer := range network.Peers {
+				if _, connected := adversary.Neighbors[peer.ID]; !connected {
+					extraAdversaryLinks++
+				}
 				adversary.Neighbors[peer.ID] = NewConnection(
-					network.Peers[peer.ID].Socket,
+					adversary,
+					network.Peers[peer.ID],
 					adversaryGroup.Delay,
 					0,
 					configuration,
@@ -183,6 +432,60 @@ func (g *AdversaryGroups) ApplyNeighborsAdversaryNodes(network *Network, configu
 	}
 }
 
+// ApplyNeighborsTargetNodes connects every adversary node to the given target nodes on top of its
+// regular peering. With the Zipf weight distribution the node IDs are ordered by descending weight, so
+// targets 0..k-1 peer the adversary with the top-k mana holders.
+func (g *AdversaryGroups) ApplyNeighborsTargetNodes(network *Network, configuration *Configuration, targets []int) {
+	for _, adversaryGroup := range *g {
+		for _, nodeID := range adversaryGroup.NodeIDs {
+			adversary := network.Peer(nodeID)
+			for _, targetID := range targets {
+				if targetID == nodeID {
+					continue
+				}
+				if _, connected := adversary.Neighbors[PeerID(targetID)]; !connected {
+					extraAdversaryLinks++
+				}
+				adversary.Neighbors[PeerID(targetID)] = NewConnection(
+					adversary,
+					network.Peers[targetID],
+					adversaryGroup.Delay,
+					0,
+					configuration,
+				)
+			}
+		}
+	}
+}
+
+// ApplyInternalDelay wires every pair of nodes within an adversary group with the configured internal
+// delay and no packet loss, creating the links the topology did not, modeling a colluding attacker
+// that exchanges messages over a fast private channel.
+func (g *AdversaryGroups) ApplyInternalDelay(network *Network, configuration *Configuration) {
+	for _, adversaryGroup := range *g {
+		for _, nodeID := range adversaryGroup.NodeIDs {
+			member := network.Peer(nodeID)
+			for _, peerID := range adversaryGroup.NodeIDs {
+				if peerID == nodeID {
+					continue
+				}
+				if existingConnection, connected := member.Neighbors[PeerID(peerID)]; connected {
+					existingConnection.SetDelay(configuration.adversaryInternalDelay)
+					continue
+				}
+				extraAdversaryLinks++
+				member.Neighbors[PeerID(peerID)] = NewConnection(
+					member,
+					network.Peers[peerID],
+					configuration.adversaryInternalDelay,
+					0,
+					configuration,
+				)
+			}
+		}
+	}
+}
+
 func randomWeightIndex(weights []uint64, count int) (randomWeights []int) {
 	selectedPeers := set.New()
 	for len(randomWeights) < count {
@@ -225,4 +528,24 @@ func GetAccidentalIssuers(network *Network) []*Peer {
 	return peers
 }
 
+// GetIssuer resolves a single issuer specification ('min', 'max', 'random' or a node ID) to a peer,
+// following the same rules as the AccidentalMana parameter. It returns nil for invalid specifications.
+func GetIssuer(network *Network, issuer string) *Peer {
+	switch issuer {
+	case "max":
+		return network.Peer(0)
+	case "min":
+		return network.Peer(len(network.WeightDistribution.weights) - 1)
+	case "random":
+		return network.RandomPeers(1)[0]
+	default:
+		customId, err := strconv.Atoi(issuer)
+		if err != nil || config.NodesCount-1 < customId || customId < 0 {
+			log.Warnf("Issuer parameter: %s is incorrect, so not processed", issuer)
+			return nil
+		}
+		return network.Peer(customId)
+	}
+}
+
 // endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,97 @@
+package network
+
+import (
+	"encoding/csv"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// region Latency matrix ///////////////////////////////////////////////////////////////////////////////////////////////
+
+// A latency matrix drives the link delays from measured inter-city RTTs (e.g. WonderNetwork data) instead
+// of the uniform Min/MaxDelay range. The file is a CSV matrix whose first row and column carry the
+// location names and whose cells are RTTs in milliseconds; links use half the RTT as one-way delay.
+
+// latencyMatrix holds the parsed locations and their pairwise one-way delays.
+type latencyMatrix struct {
+	locations []string
+	delays    [][]time.Duration
+}
+
+// ApplyLatencyMatrix assigns every peer a location of the imported matrix and pins each connection's
+// delay to the measured one-way delay between the endpoints' locations. Locations are assigned
+// round-robin with the "uniform" distribution and drawn from the peer's private randomness stream with
+// "random". The slowdown factor scales the delays like all other realtime delays.
+func ApplyLatencyMatrix(n *Network, fileName string, distribution string, slowdownFactor int) {
+	matrix := parseLatencyMatrix(fileName)
+
+	locationOf := make(map[PeerID]int)
+	for _, peer := range n.Peers {
+		switch distribution {
+		case "random":
+			locationOf[peer.ID] = peer.Rand.Intn(len(matrix.locations))
+		default:
+			locationOf[peer.ID] = int(peer.ID) % len(matrix.locations)
+		}
+	}
+
+	totalDelay := time.Duration(0)
+	connectionCount := 0
+	for _, peer := range n.Peers {
+		for neighborID, connection := range peer.Neighbors {
+			networkDelay := matrix.delays[locationOf[peer.ID]][locationOf[neighborID]]
+			connection.SetDelay(time.Duration(slowdownFactor) * networkDelay)
+			connection.FixDelay()
+
+			totalDelay += networkDelay
+			connectionCount++
+		}
+	}
+
+	if connectionCount > 0 {
+		log.Infof("Applied latency matrix with %d locations: average link delay %s",
+			len(matrix.locations), (totalDelay / time.Duration(connectionCount)).Round(time.Microsecond))
+	}
+}
+
+// parseLatencyMatrix parses the CSV matrix of RTTs in milliseconds.
+func parseLatencyMatrix(fileName string) (matrix latencyMatrix) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		log.Fatalf("Failed to load latency matrix from %s: %s", fileName, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	records, err := reader.ReadAll()
+	if err != nil {
+		log.Fatalf("Failed to parse latency matrix %s: %s", fileName, err)
+	}
+	if len(records) < 2 || len(records[0]) != len(records) {
+		log.Fatalf("Latency matrix %s is not square", fileName)
+	}
+
+	for _, location := range records[0][1:] {
+		matrix.locations = append(matrix.locations, strings.TrimSpace(location))
+	}
+	for rowIndex, record := range records[1:] {
+		if len(record) != len(records[0]) {
+			log.Fatalf("Latency matrix %s row %d has %d cells, expected %d", fileName, rowIndex+2, len(record), len(records[0]))
+		}
+		row := make([]time.Duration, 0, len(matrix.locations))
+		for _, cell := range record[1:] {
+			rttMs, err := strconv.ParseFloat(strings.TrimSpace(cell), 64)
+			if err != nil || rttMs < 0 {
+				log.Fatalf("Latency matrix %s has an invalid RTT %q", fileName, cell)
+			}
+			row = append(row, time.Duration(rttMs/2*float64(time.Millisecond)))
+		}
+		matrix.delays = append(matrix.delays, row)
+	}
+
+	return
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
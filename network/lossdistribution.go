@@ -0,0 +1,82 @@
+package network
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/iotaledger/hive.go/crypto"
+)
+
+// region Loss distribution ////////////////////////////////////////////////////////////////////////////////////////////
+
+// Real links do not all lose the same share of their packets, so the per-link loss sampling can be
+// replaced with a named distribution, configured as 'name:param:param...' like the delay distribution.
+
+// lossSampler draws one per-link packet loss rate.
+type lossSampler func() float64
+
+// LossDistribution replaces the uniform per-link loss sampling of the configuration with the
+// distribution given as 'name:param:param...'. Supported specs:
+//
+//	uniform              — the PacketLoss range (the default)
+//	exponential:mean     — exponentially distributed with the given mean loss rate
+//	bimodal:p:low:high   — the high loss rate with probability p, the low one otherwise
+//
+// The sampled rates are clamped to [0, 1].
+func LossDistribution(spec string) Option {
+	return func(configuration *Configuration) {
+		configuration.lossSampler = parseLossDistribution(spec)
+	}
+}
+
+func parseLossDistribution(spec string) lossSampler {
+	parts := strings.Split(spec, ":")
+	params := make([]float64, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			log.Fatalf("Loss distribution %s has a non-numeric parameter %s", spec, part)
+		}
+		params = append(params, value)
+	}
+
+	switch parts[0] {
+	case "uniform":
+		return nil
+	case "exponential":
+		if len(params) != 1 {
+			log.Fatalf("The exponential loss distribution needs 'exponential:mean', got %s", spec)
+		}
+		mean := params[0]
+		return func() float64 {
+			return clampLossRate(crypto.Randomness.ExpFloat64() * mean)
+		}
+	case "bimodal":
+		if len(params) != 3 {
+			log.Fatalf("The bimodal loss distribution needs 'bimodal:p:low:high', got %s", spec)
+		}
+		p, low, high := params[0], params[1], params[2]
+		return func() float64 {
+			if crypto.Randomness.Float64() < p {
+				return clampLossRate(high)
+			}
+			return clampLossRate(low)
+		}
+	default:
+		log.Fatalf("Unknown loss distribution %s, supported are uniform, exponential, bimodal", parts[0])
+	}
+
+	return nil
+}
+
+func clampLossRate(lossRate float64) float64 {
+	if lossRate < 0 {
+		return 0
+	}
+	if lossRate > 1 {
+		return 1
+	}
+	return lossRate
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,70 @@
+package network
+
+import (
+	"github.com/iotaledger/hive.go/crypto"
+)
+
+// region NAT //////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// ApplyNat puts the given fraction of the nodes behind a NAT that blocks inbound dial-in: a link
+// between a NATed node and an open node only delivers in the NATed node's outbound direction, and
+// links between two NATed nodes, which neither side could have established, are removed entirely.
+func ApplyNat(n *Network, natFraction float64) {
+	n.nattedPeers = make(map[PeerID]bool)
+	for _, peer := range n.Peers {
+		if crypto.Randomness.Float64() < natFraction {
+			n.nattedPeers[peer.ID] = true
+		}
+	}
+
+	unidirectional, removed := 0, 0
+	for _, peer := range n.Peers {
+		for neighborID, connection := range peer.Neighbors {
+			if !n.nattedPeers[neighborID] {
+				continue
+			}
+			// the delivery towards the NATed neighbor is blocked
+			connection.Shutdown()
+			delete(peer.Neighbors, neighborID)
+			if n.nattedPeers[peer.ID] {
+				// the reverse direction is dropped when the iteration reaches the neighbor
+				if peer.ID < neighborID {
+					removed++
+				}
+			} else {
+				unidirectional++
+			}
+		}
+	}
+
+	withoutInbound := 0
+	for _, inboundDegree := range n.InboundDegrees() {
+		if inboundDegree == 0 {
+			withoutInbound++
+		}
+	}
+	log.Infof("NAT: %d nodes behind a NAT, %d links became unidirectional, %d links removed, %d nodes without inbound connectivity",
+		len(n.nattedPeers), unidirectional, removed, withoutInbound)
+}
+
+// IsNatted reports whether the peer is behind a NAT, see ApplyNat.
+func (n *Network) IsNatted(peerID PeerID) bool {
+	return n.nattedPeers[peerID]
+}
+
+// InboundDegrees returns for every peer how many neighbors can deliver messages to it. Peers missing
+// from the Peers slice of another peer's Neighbors map have no inbound connectivity and appear with a
+// zero degree.
+func (n *Network) InboundDegrees() map[PeerID]int {
+	degrees := make(map[PeerID]int, len(n.Peers))
+	for _, peer := range n.Peers {
+		degrees[peer.ID] += 0
+		for neighborID := range peer.Neighbors {
+			degrees[neighborID]++
+		}
+	}
+
+	return degrees
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -19,6 +19,10 @@ type Network struct {
 	Peers              []*Peer
 	WeightDistribution *ConsensusWeightDistribution
 	AdversaryGroups    AdversaryGroups
+	Configuration      *Configuration
+
+	partitionedLinks []partitionedLink
+	nattedPeers      map[PeerID]bool
 }
 
 func New(option ...Option) (network *Network) {
@@ -31,6 +35,7 @@ func New(option ...Option) (network *Network) {
 	}
 
 	configuration := NewConfiguration(option...)
+	network.Configuration = configuration
 	configuration.CreatePeers(network)
 	configuration.ConnectPeers(network)
 
@@ -69,14 +74,21 @@ func (n *Network) Peer(index int) *Peer {
 // region Configuration ////////////////////////////////////////////////////////////////////////////////////////////////
 
 type Configuration struct {
-	nodes               []*NodesSpecification
-	minDelay            time.Duration
-	maxDelay            time.Duration
-	minPacketLoss       float64
-	maxPacketLoss       float64
-	peeringStrategy     PeeringStrategy
-	adversaryPeeringAll bool
-	adversarySpeedup    []float64
+	nodes                   []*NodesSpecification
+	minDelay                time.Duration
+	maxDelay                time.Duration
+	delaySampler            delaySampler
+	jitter                  time.Duration
+	minPacketLoss           float64
+	maxPacketLoss           float64
+	lossSampler             lossSampler
+	peeringStrategy         PeeringStrategy
+	adversaryPeeringAll     bool
+	adversaryPeeringTargets []int
+	adversaryInternalLinks  bool
+	adversaryInternalDelay  time.Duration
+	adversarySpeedup        []float64
+	compositeNodeFactory    CompositeNodeFactory
 }
 
 func NewConfiguration(options ...Option) (configuration *Configuration) {
@@ -89,6 +101,10 @@ func NewConfiguration(options ...Option) (configuration *Configuration) {
 }
 
 func (c *Configuration) RandomNetworkDelay() time.Duration {
+	if c.delaySampler != nil {
+		return c.delaySampler()
+	}
+
 	return c.minDelay + time.Duration(crypto.Randomness.Float64()*float64(c.maxDelay-c.minDelay))
 }
 
@@ -96,7 +112,21 @@ func (c *Configuration) ExpRandomNetworkDelay() time.Duration {
 	return time.Duration(rand.ExpFloat64() * (float64(c.maxDelay+c.minDelay) / 2))
 }
 
+// RandomJitter draws the per-message jitter term, a zero-mean normal with the configured standard
+// deviation.
+func (c *Configuration) RandomJitter() time.Duration {
+	if c.jitter == 0 {
+		return 0
+	}
+
+	return time.Duration(crypto.Randomness.NormFloat64() * float64(c.jitter))
+}
+
 func (c *Configuration) RandomPacketLoss() float64 {
+	if c.lossSampler != nil {
+		return c.lossSampler()
+	}
+
 	return c.minPacketLoss + crypto.Randomness.Float64()*(c.maxPacketLoss-c.minPacketLoss)
 }
 
@@ -112,15 +142,24 @@ func (c *Configuration) CreatePeers(network *Network) {
 		for i := 0; i < nodesSpecification.nodeCount; i++ {
 			nodeType := HonestNode
 			speedupFactor := 1.0
+			var composition []AdversaryType
 			// this is adversary node
 			if groupIndex, ok := AdversaryNodeIDToGroupIDMap[i]; ok {
 				nodeType = network.AdversaryGroups[groupIndex].AdversaryType
-				speedupFactor = c.adversarySpeedup[groupIndex]
+				composition = network.AdversaryGroups[groupIndex].Composition
+				// the appended sybil group has no adversarySpeedup entry and keeps the default
+				if groupIndex < len(c.adversarySpeedup) {
+					speedupFactor = c.adversarySpeedup[groupIndex]
+				}
 			}
 			nodeFactory := nodesSpecification.nodeFactories[nodeType]
+			// a composed group combines several behaviors instead of one dedicated node type
+			if len(composition) > 0 && c.compositeNodeFactory != nil {
+				nodeFactory = c.compositeNodeFactory(composition)
+			}
 
 			peer := NewPeer(nodeFactory())
-			peer.AdversarySpeedup = speedupFactor
+			peer.SetAdversarySpeedup(speedupFactor)
 			network.Peers = append(network.Peers, peer)
 			log.Debugf("Created %s ... [DONE]", peer)
 
@@ -135,11 +174,16 @@ func (c *Configuration) ConnectPeers(network *Network) {
 	defer log.Info("Connecting peers ... [DONE]")
 
 	c.peeringStrategy(network, c)
+	extraAdversaryLinks = 0
 	if c.adversaryPeeringAll {
 		network.AdversaryGroups.ApplyNeighborsAdversaryNodes(network, c)
+	} else if len(c.adversaryPeeringTargets) > 0 {
+		network.AdversaryGroups.ApplyNeighborsTargetNodes(network, c, c.adversaryPeeringTargets)
 	}
 	network.AdversaryGroups.ApplyNetworkDelayForAdversaryNodes(network)
-
+	if c.adversaryInternalLinks {
+		network.AdversaryGroups.ApplyInternalDelay(network, c)
+	}
 }
 
 // endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -195,6 +239,14 @@ func Delay(minDelay time.Duration, maxDelay time.Duration) Option {
 	}
 }
 
+// Jitter adds a zero-mean normal jitter term with the given standard deviation to every delivery,
+// resampled per message, so delivery-order inversions also happen on fixed-delay links.
+func Jitter(stdDev time.Duration) Option {
+	return func(config *Configuration) {
+		config.jitter = stdDev
+	}
+}
+
 func PacketLoss(minPacketLoss float64, maxPacketLoss float64) Option {
 	return func(config *Configuration) {
 		config.minPacketLoss = minPacketLoss
@@ -208,18 +260,45 @@ func Topology(peeringStrategy PeeringStrategy) Option {
 	}
 }
 
+// AdversaryPeeringTargets peers every adversary node with the given nodes on top of its regular
+// peering. Ignored when AdversaryPeeringAll is set.
+func AdversaryPeeringTargets(targets []int) Option {
+	return func(config *Configuration) {
+		config.adversaryPeeringTargets = targets
+	}
+}
+
 func AdversaryPeeringAll(adversaryPeeringAll bool) Option {
 	return func(config *Configuration) {
 		config.adversaryPeeringAll = adversaryPeeringAll
 	}
 }
 
+// AdversaryInternalDelay wires every pair of nodes within an adversary group with the given delay on
+// top of the regular peering, regardless of topology. A negative delay disables the private channel.
+func AdversaryInternalDelay(delayInMs int) Option {
+	return func(config *Configuration) {
+		config.adversaryInternalLinks = delayInMs >= 0
+		config.adversaryInternalDelay = time.Duration(delayInMs) * time.Millisecond
+	}
+}
+
 func AdversarySpeedup(adversarySpeedupFactors []float64) Option {
 	return func(config *Configuration) {
 		config.adversarySpeedup = adversarySpeedupFactors
 	}
 }
 
+// CompositeNodeFactory builds the node combining all behaviors of a composed adversary group; it
+// lives in the adversary package and is injected here to avoid the import cycle.
+type CompositeNodeFactory func(composition []AdversaryType) NodeFactory
+
+func CompositeNodes(factory CompositeNodeFactory) Option {
+	return func(config *Configuration) {
+		config.compositeNodeFactory = factory
+	}
+}
+
 type PeeringStrategy func(network *Network, options *Configuration)
 
 func WattsStrogatz(meanDegree int, randomness float64) PeeringStrategy {
@@ -242,45 +321,210 @@ func WattsStrogatz(meanDegree int, randomness float64) PeeringStrategy {
 		for tail, edges := range graph {
 			for head := range edges {
 				if crypto.Randomness.Float64() < randomness {
+					// when the tail is already connected to (almost) every other node there might be no
+					// valid rewiring candidate, so give up on this edge after a bounded number of draws
 					newHead := crypto.Randomness.Intn(nodeCount)
-					for newHead == tail || graph[newHead][tail] || edges[newHead] {
+					for attempts := 0; newHead == tail || graph[newHead][tail] || edges[newHead]; attempts++ {
+						if attempts >= 10*nodeCount {
+							newHead = -1
+							break
+						}
 						newHead = crypto.Randomness.Intn(nodeCount)
 					}
+					if newHead == -1 {
+						continue
+					}
 
 					delete(edges, head)
 					edges[newHead] = true
 				}
 			}
 		}
-		for sourceNodeID, targetNodeIDs := range graph {
-			for targetNodeID := range targetNodeIDs {
-				randomNetworkDelay := configuration.RandomNetworkDelay()
-				randomPacketLoss := configuration.RandomPacketLoss()
-
-				network.Peers[sourceNodeID].Neighbors[PeerID(targetNodeID)] = NewConnection(
-					network.Peers[targetNodeID].Socket,
-					randomNetworkDelay,
-					randomPacketLoss,
-					configuration,
-				)
-
-				network.Peers[targetNodeID].Neighbors[PeerID(sourceNodeID)] = NewConnection(
-					network.Peers[sourceNodeID].Socket,
-					randomNetworkDelay,
-					randomPacketLoss,
-					configuration,
-				)
-
-				log.Debugf("Connecting %s <-> %s [network delay (%s), packet loss (%0.4f%%)] ... [DONE]", network.Peers[sourceNodeID], network.Peers[targetNodeID], randomNetworkDelay, randomPacketLoss*100)
+		connectGraph(network, configuration, graph)
+	}
+}
+
+// ErdosRenyi connects every pair of nodes independently with probability p, producing a purely random
+// graph without the clustering of the WattsStrogatz ring.
+func ErdosRenyi(p float64) PeeringStrategy {
+	if p <= 0 || p > 1 {
+		panic("Invalid argument: p needs to be within (0, 1]")
+	}
+
+	return func(network *Network, configuration *Configuration) {
+		nodeCount := len(network.Peers)
+		graph := make(map[int]map[int]bool)
+
+		for nodeID := 0; nodeID < nodeCount; nodeID++ {
+			graph[nodeID] = make(map[int]bool)
+		}
+		for tail := 0; tail < nodeCount; tail++ {
+			for head := tail + 1; head < nodeCount; head++ {
+				if crypto.Randomness.Float64() < p {
+					graph[tail][head] = true
+				}
 			}
 		}
-		totalNeighborCount := 0
-		for _, peer := range network.Peers {
-			log.Debugf("%d %d", peer.ID, len(peer.Neighbors))
-			totalNeighborCount += len(peer.Neighbors)
+
+		connectGraph(network, configuration, graph)
+	}
+}
+
+// BarabasiAlbert grows a scale-free graph by preferential attachment: every new node connects m edges to
+// existing nodes with probability proportional to their current degree, producing the heavy-tailed degree
+// distribution (hubs) of real P2P overlays.
+func BarabasiAlbert(m int) PeeringStrategy {
+	if m < 1 {
+		panic("Invalid argument: m needs to be at least 1")
+	}
+
+	return func(network *Network, configuration *Configuration) {
+		nodeCount := len(network.Peers)
+		if m >= nodeCount {
+			panic("Invalid argument: m needs to be smaller than the node count")
+		}
+		graph := make(map[int]map[int]bool)
+		for nodeID := 0; nodeID < nodeCount; nodeID++ {
+			graph[nodeID] = make(map[int]bool)
+		}
+
+		// every edge endpoint is one entry, so drawing a random entry is degree-proportional
+		degreeList := make([]int, 0, 2*m*nodeCount)
+
+		// seed clique of the first m+1 nodes, so every new node finds m distinct attachment targets
+		for tail := 0; tail <= m; tail++ {
+			for head := tail + 1; head <= m; head++ {
+				graph[tail][head] = true
+				degreeList = append(degreeList, tail, head)
+			}
+		}
+
+		for nodeID := m + 1; nodeID < nodeCount; nodeID++ {
+			targets := make(map[int]bool)
+			for len(targets) < m {
+				target := degreeList[crypto.Randomness.Intn(len(degreeList))]
+				if target != nodeID {
+					targets[target] = true
+				}
+			}
+			for target := range targets {
+				graph[target][nodeID] = true
+				degreeList = append(degreeList, target, nodeID)
+			}
+		}
+
+		connectGraph(network, configuration, graph)
+	}
+}
+
+// ConfigurationModel builds a simple graph matching the given degree sequence (one entry per node) by
+// stub matching, so the effect of the degree distribution can be isolated from other topology
+// properties. Matchings producing self-loops or duplicate edges are redrawn; if none is found the
+// offending stubs are dropped, leaving those nodes slightly below their target degree.
+func ConfigurationModel(degrees []int) PeeringStrategy {
+	return func(network *Network, configuration *Configuration) {
+		nodeCount := len(network.Peers)
+		if len(degrees) != nodeCount {
+			log.Fatalf("The degree sequence has %d entries for %d nodes", len(degrees), nodeCount)
+		}
+
+		stubCount := 0
+		for _, degree := range degrees {
+			if degree < 0 || degree >= nodeCount {
+				log.Fatalf("Degree %d is outside the valid range [0, %d)", degree, nodeCount)
+			}
+			stubCount += degree
 		}
-		log.Infof("Average number of neighbors: %.1f", float64(totalNeighborCount)/float64(nodeCount))
+		if stubCount%2 != 0 {
+			log.Fatalf("The degree sequence sums to %d, which is odd and cannot be matched", stubCount)
+		}
+
+		stubs := make([]int, 0, stubCount)
+		for nodeID, degree := range degrees {
+			for i := 0; i < degree; i++ {
+				stubs = append(stubs, nodeID)
+			}
+		}
+
+		var graph map[int]map[int]bool
+		for attempt := 0; attempt < 100; attempt++ {
+			crypto.Randomness.Shuffle(len(stubs), func(i, j int) {
+				stubs[i], stubs[j] = stubs[j], stubs[i]
+			})
+
+			graph = make(map[int]map[int]bool)
+			for nodeID := 0; nodeID < nodeCount; nodeID++ {
+				graph[nodeID] = make(map[int]bool)
+			}
+
+			conflicts := 0
+			for i := 0; i < len(stubs); i += 2 {
+				tail, head := stubs[i], stubs[i+1]
+				if head < tail {
+					tail, head = head, tail
+				}
+				if tail == head || graph[tail][head] {
+					conflicts++
+					continue
+				}
+				graph[tail][head] = true
+			}
+
+			if conflicts == 0 {
+				break
+			}
+			if attempt == 99 {
+				log.Warnf("No conflict-free stub matching found, %d edges of the degree sequence are dropped", conflicts)
+			}
+		}
+
+		connectGraph(network, configuration, graph)
+	}
+}
+
+// connectGraph materializes an undirected graph (given as adjacency sets) into peer connections with
+// randomized delay and packet loss per link.
+func connectGraph(network *Network, configuration *Configuration, graph map[int]map[int]bool) {
+	for sourceNodeID, targetNodeIDs := range graph {
+		for targetNodeID := range targetNodeIDs {
+			randomNetworkDelay := configuration.RandomNetworkDelay()
+			reverseNetworkDelay := randomNetworkDelay
+			if config.AsymmetricDelays {
+				reverseNetworkDelay = configuration.RandomNetworkDelay()
+			}
+			randomPacketLoss := configuration.RandomPacketLoss()
+
+			forwardConnection := NewConnection(
+				network.Peers[sourceNodeID],
+				network.Peers[targetNodeID],
+				randomNetworkDelay,
+				randomPacketLoss,
+				configuration,
+			)
+			reverseConnection := NewConnection(
+				network.Peers[targetNodeID],
+				network.Peers[sourceNodeID],
+				reverseNetworkDelay,
+				randomPacketLoss,
+				configuration,
+			)
+			// asymmetric delays are only observable when the links stick to their per-direction delay
+			if config.AsymmetricDelays {
+				forwardConnection.FixDelay()
+				reverseConnection.FixDelay()
+			}
+			network.Peers[sourceNodeID].Neighbors[PeerID(targetNodeID)] = forwardConnection
+			network.Peers[targetNodeID].Neighbors[PeerID(sourceNodeID)] = reverseConnection
+
+			log.Debugf("Connecting %s <-> %s [network delay (%s/%s), packet loss (%0.4f%%)] ... [DONE]", network.Peers[sourceNodeID], network.Peers[targetNodeID], randomNetworkDelay, reverseNetworkDelay, randomPacketLoss*100)
+		}
+	}
+	totalNeighborCount := 0
+	for _, peer := range network.Peers {
+		log.Debugf("%d %d", peer.ID, len(peer.Neighbors))
+		totalNeighborCount += len(peer.Neighbors)
 	}
+	log.Infof("Average number of neighbors: %.1f", float64(totalNeighborCount)/float64(len(network.Peers)))
 }
 
 // endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -4,6 +4,7 @@ package network
 type Node interface {
 	Setup(peer *Peer, weightDistribution *ConsensusWeightDistribution)
 	HandleNetworkMessage(networkMessage interface{})
+	Shutdown()
 }
 
 type NodeFactory func() Node
@@ -0,0 +1,52 @@
+package network
+
+// region Partition ////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// Partitioning splits the network into components whose members cannot reach each other, by blocking
+// every cross-component connection. Healing restores the blocked connections, after which the
+// components reconcile their tangles through the regular gossip and solidification machinery.
+
+// partitionedLink remembers a blocked connection together with its original packet loss so healing can
+// restore links that carry a non-default loss (e.g. from a topology file).
+type partitionedLink struct {
+	connection *Connection
+	packetLoss float64
+}
+
+// Partition splits the network into k components, assigning the peers round-robin by ID, and blocks all
+// cross-component connections by raising their packet loss to 1. It returns the component membership
+// indexed by peer ID.
+func (n *Network) Partition(k int) (membership []int) {
+	membership = make([]int, len(n.Peers))
+	for _, peer := range n.Peers {
+		membership[peer.ID] = int(peer.ID) % k
+	}
+
+	for sourceNodeID, peer := range n.Peers {
+		for neighborID, connection := range peer.Neighbors {
+			if membership[sourceNodeID] == membership[int(neighborID)] {
+				continue
+			}
+
+			n.partitionedLinks = append(n.partitionedLinks, partitionedLink{connection, connection.PacketLoss()})
+			connection.SetPacketLoss(1.0)
+		}
+	}
+
+	return
+}
+
+// Heal unblocks the connections cut by the last Partition call, restoring their original packet loss,
+// and returns the number of restored connections.
+func (n *Network) Heal() (healed int) {
+	for _, link := range n.partitionedLinks {
+		link.connection.SetPacketLoss(link.packetLoss)
+	}
+
+	healed = len(n.partitionedLinks)
+	n.partitionedLinks = nil
+
+	return
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
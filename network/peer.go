@@ -2,41 +2,79 @@ package network
 
 import (
 	"fmt"
+	"math"
+	"math/rand"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/iotaledger/hive.go/crypto"
 	"github.com/iotaledger/hive.go/timedexecutor"
+	"github.com/iotaledger/multivers-simulation/clock"
+	"github.com/iotaledger/multivers-simulation/config"
 )
 
 // region Peer /////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 type Peer struct {
-	ID               PeerID
-	Neighbors        map[PeerID]*Connection
-	Socket           chan interface{}
-	Node             Node
-	AdversarySpeedup float64
+	ID        PeerID
+	Neighbors map[PeerID]*Connection
+	Socket    chan interface{}
+	Node      Node
 
+	// current issuance speedup factor as math.Float64bits, see AdversarySpeedup
+	adversarySpeedupBits uint64
+
+	// Rand is the peer's private randomness stream, seeded from the master seed plus the peer ID so that
+	// adding or removing a node does not perturb the random decisions of the other peers. It is not safe
+	// for concurrent use.
+	Rand *rand.Rand
+
+	offline        int32
 	startOnce      sync.Once
 	shutdownOnce   sync.Once
 	shutdownSignal chan struct{}
+
+	// cumulative number of received duplicate message copies, see CountDuplicateDelivery
+	duplicateDeliveries int64
+
+	// neighbor scoring state, see ReportInvalidDelivery
+	currentSenderID    PeerID
+	neighborDeliveries map[PeerID]int64
+	invalidDeliveries  map[PeerID]int64
+	scoringMutex       sync.Mutex
 }
 
 func NewPeer(node Node) (peer *Peer) {
+	id := NewPeerID()
 	peer = &Peer{
-		ID:        NewPeerID(),
+		ID:        id,
 		Neighbors: make(map[PeerID]*Connection),
 		Socket:    make(chan interface{}, 1024),
 		Node:      node,
+		Rand:      rand.New(rand.NewSource(peerSeed(id))),
+
+		adversarySpeedupBits: math.Float64bits(1.0),
 
 		shutdownSignal: make(chan struct{}, 1),
+
+		currentSenderID:    -1,
+		neighborDeliveries: make(map[PeerID]int64),
+		invalidDeliveries:  make(map[PeerID]int64),
 	}
 
 	return
 }
 
+// peerSeed derives the seed of a peer's private randomness stream from the configured master seed. With
+// an unset master seed the streams stay time-based but remain independent per peer.
+func peerSeed(id PeerID) int64 {
+	if config.Seed != 0 {
+		return config.Seed + int64(id)
+	}
+	return time.Now().UnixNano() + int64(id)
+}
+
 func (p *Peer) SetupNode(consensusWeightDistribution *ConsensusWeightDistribution) {
 	p.Node.Setup(p, consensusWeightDistribution)
 }
@@ -50,19 +88,152 @@ func (p *Peer) Start() {
 func (p *Peer) Shutdown() {
 	p.shutdownOnce.Do(func() {
 		close(p.shutdownSignal)
+
+		for _, neighborConnection := range p.Neighbors {
+			neighborConnection.Shutdown()
+		}
+		p.Node.Shutdown()
 	})
 }
 
 func (p *Peer) ReceiveNetworkMessage(message interface{}) {
+	// with the discrete-event scheduler all peers are processed on the scheduler goroutine, so messages
+	// are handled inline instead of being queued on the socket
+	if config.DiscreteEvent {
+		p.Node.HandleNetworkMessage(message)
+		return
+	}
+
 	p.Socket <- message
 }
 
 func (p *Peer) GossipNetworkMessage(message interface{}) {
+	if p.IsOffline() {
+		return
+	}
+
 	for _, neighborConnection := range p.Neighbors {
 		neighborConnection.Send(message)
 	}
 }
 
+// GossipNetworkMessageToPeers sends the message only to the given neighbors, used by targeting
+// adversary nodes that direct their messages at selected victims.
+func (p *Peer) GossipNetworkMessageToPeers(message interface{}, targets []PeerID) {
+	if p.IsOffline() {
+		return
+	}
+
+	for _, targetID := range targets {
+		if neighborConnection, connected := p.Neighbors[targetID]; connected {
+			neighborConnection.Send(message)
+		}
+	}
+}
+
+// GossipNetworkMessageToFanout sends the message to at most fanout randomly drawn neighbors instead of
+// all of them, trading propagation redundancy for bandwidth. A non-positive fanout falls back to
+// flooding.
+func (p *Peer) GossipNetworkMessageToFanout(message interface{}, fanout int) {
+	if fanout <= 0 || fanout >= len(p.Neighbors) {
+		p.GossipNetworkMessage(message)
+		return
+	}
+
+	if p.IsOffline() {
+		return
+	}
+
+	neighborIDs := make([]PeerID, 0, len(p.Neighbors))
+	for neighborID := range p.Neighbors {
+		neighborIDs = append(neighborIDs, neighborID)
+	}
+	crypto.Randomness.Shuffle(len(neighborIDs), func(i, j int) {
+		neighborIDs[i], neighborIDs[j] = neighborIDs[j], neighborIDs[i]
+	})
+
+	for _, neighborID := range neighborIDs[:fanout] {
+		p.Neighbors[neighborID].Send(message)
+	}
+}
+
+// SetOffline marks the peer offline or back online. An offline peer neither gossips nor receives
+// messages, so after coming back it has to re-solidify the part of the tangle it missed through the
+// regular solidification requests.
+func (p *Peer) SetOffline(offline bool) {
+	if offline {
+		atomic.StoreInt32(&p.offline, 1)
+		return
+	}
+	atomic.StoreInt32(&p.offline, 0)
+}
+
+func (p *Peer) IsOffline() bool {
+	return atomic.LoadInt32(&p.offline) == 1
+}
+
+// receiveFromNeighbor accounts the delivery for the neighbor scoring and hands the message to the node.
+// The sending neighbor is remembered for the duration of the inline handling, so the node can charge it
+// for a duplicate or invalid delivery via ReportInvalidDelivery.
+func (p *Peer) receiveFromNeighbor(senderID PeerID, message interface{}) {
+	if config.PeerScoringInterval > 0 {
+		p.scoringMutex.Lock()
+		p.neighborDeliveries[senderID]++
+		p.currentSenderID = senderID
+		p.scoringMutex.Unlock()
+	}
+
+	p.Node.HandleNetworkMessage(message)
+}
+
+// ReportInvalidDelivery charges the neighbor that forwarded the message currently being handled, e.g.
+// for delivering a duplicate or conflicting message. Neighbors whose share of charged deliveries grows
+// too large are dropped, see Network.DropMisbehavingNeighbors.
+func (p *Peer) ReportInvalidDelivery() {
+	if config.PeerScoringInterval == 0 {
+		return
+	}
+
+	p.scoringMutex.Lock()
+	if p.currentSenderID >= 0 {
+		p.invalidDeliveries[p.currentSenderID]++
+	}
+	p.scoringMutex.Unlock()
+}
+
+// AdversarySpeedup returns the factor by which the peer issues more messages than its mana would
+// allow, 1 for honest nodes. Adaptive adversary strategies may retarget it mid-run, so the issuance
+// loops re-read it on every iteration.
+func (p *Peer) AdversarySpeedup() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&p.adversarySpeedupBits))
+}
+
+// SetAdversarySpeedup atomically replaces the issuance speedup factor.
+func (p *Peer) SetAdversarySpeedup(speedup float64) {
+	atomic.StoreUint64(&p.adversarySpeedupBits, math.Float64bits(speedup))
+}
+
+// CountDuplicateDelivery accounts the delivery of an already known message, the redundancy cost of the
+// gossip protocol.
+func (p *Peer) CountDuplicateDelivery() {
+	atomic.AddInt64(&p.duplicateDeliveries, 1)
+}
+
+// DuplicateDeliveries returns how many duplicate copies of already known messages the peer received.
+func (p *Peer) DuplicateDeliveries() int64 {
+	return atomic.LoadInt64(&p.duplicateDeliveries)
+}
+
+// OutboundQueueLength returns the number of messages queued on the peer's bandwidth-limited outbound
+// connections.
+func (p *Peer) OutboundQueueLength() (length int) {
+	for _, neighborConnection := range p.Neighbors {
+		length += neighborConnection.QueueLength()
+	}
+
+	return
+}
+
 func (p *Peer) String() string {
 	return fmt.Sprintf("Peer%d", p.ID)
 }
@@ -73,7 +244,11 @@ func (p *Peer) run() {
 		case <-p.shutdownSignal:
 			return
 		case networkMessage := <-p.Socket:
-			p.Node.HandleNetworkMessage(networkMessage)
+			if delivery, fromNeighbor := networkMessage.(neighborDelivery); fromNeighbor {
+				p.receiveFromNeighbor(delivery.senderID, delivery.message)
+			} else {
+				p.Node.HandleNetworkMessage(networkMessage)
+			}
 		}
 	}
 }
@@ -94,24 +269,52 @@ func NewPeerID() PeerID {
 
 // region Connection ///////////////////////////////////////////////////////////////////////////////////////////////////
 
+// neighborDelivery carries a message delivered over a connection together with the sending neighbor,
+// so the receiving peer can attribute the delivery for its neighbor scoring.
+type neighborDelivery struct {
+	senderID PeerID
+	message  interface{}
+}
+
 type Connection struct {
+	sourcePeer    *Peer
+	targetPeer    *Peer
 	socket        chan<- interface{}
 	networkDelay  time.Duration
+	fixedDelay    bool
 	packetLoss    float64
 	timedExecutor *timedexecutor.TimedExecutor
 	shutdownOnce  sync.Once
 	configuration *Configuration
+
+	// bandwidth queuing state, see enqueue
+	serviceTime time.Duration
+	queueUntil  time.Time
+	queueMutex  sync.Mutex
+
+	// Gilbert-Elliott burst loss state, true while the link is in the lossy burst state
+	burstState int32
 }
 
-func NewConnection(socket chan<- interface{}, networkDelay time.Duration, packetLoss float64, configuration *Configuration) (connection *Connection) {
+func NewConnection(sourcePeer *Peer, targetPeer *Peer, networkDelay time.Duration, packetLoss float64, configuration *Configuration) (connection *Connection) {
 	connection = &Connection{
-		socket:        socket,
+		sourcePeer:    sourcePeer,
+		targetPeer:    targetPeer,
+		socket:        targetPeer.Socket,
 		networkDelay:  networkDelay,
 		packetLoss:    packetLoss,
 		timedExecutor: timedexecutor.New(1),
 		configuration: configuration,
 	}
 
+	if config.BandwidthMsgPerSec > 0 {
+		serviceTime := time.Duration(float64(time.Second) / float64(config.BandwidthMsgPerSec))
+		if !config.DiscreteEvent {
+			serviceTime *= time.Duration(config.SlowdownFactor)
+		}
+		connection.serviceTime = serviceTime
+	}
+
 	return
 }
 
@@ -123,19 +326,129 @@ func (c *Connection) PacketLoss() float64 {
 	return c.packetLoss
 }
 
+// PauseHook, when set, blocks the calling goroutine while the simulation is paused, so no messages are
+// delivered until it is resumed.
+var PauseHook func()
+
 func (c *Connection) Send(message interface{}) {
-	if crypto.Randomness.Float64() <= c.packetLoss {
+	if PauseHook != nil {
+		PauseHook()
+	}
+
+	if c.targetPeer.IsOffline() {
+		return
+	}
+
+	if c.dropMessage() {
+		return
+	}
+
+	networkDelay := c.configuration.RandomNetworkDelay()
+	if c.fixedDelay {
+		networkDelay = c.networkDelay
+	}
+
+	// the jitter term is resampled per message, so even fixed-delay links see delivery-order inversions
+	if jitter := c.configuration.RandomJitter(); jitter != 0 {
+		networkDelay += jitter
+		if networkDelay < 0 {
+			networkDelay = 0
+		}
+	}
+
+	// on a bandwidth-limited connection the message additionally waits behind the earlier ones
+	if c.serviceTime > 0 {
+		networkDelay += c.enqueue()
+	}
+
+	if config.DiscreteEvent {
+		clock.Virtual.ExecuteAfter(networkDelay, func() {
+			c.targetPeer.receiveFromNeighbor(c.sourcePeer.ID, message)
+		})
 		return
 	}
+
 	c.timedExecutor.ExecuteAfter(func() {
-		c.socket <- message
-	}, c.configuration.RandomNetworkDelay())
+		c.socket <- neighborDelivery{senderID: c.sourcePeer.ID, message: message}
+	}, networkDelay)
+}
+
+// dropMessage decides whether the message is lost on the connection. By default losses are independent
+// with the link's loss rate. With burst loss enabled the link follows the Gilbert-Elliott two-state
+// chain instead: in the burst state it drops BurstLossBad of its messages, outside of it the link's
+// loss rate applies, and the state transitions are evaluated per message.
+func (c *Connection) dropMessage() bool {
+	if !config.BurstLoss {
+		return crypto.Randomness.Float64() <= c.packetLoss
+	}
+
+	if atomic.LoadInt32(&c.burstState) == 1 {
+		if crypto.Randomness.Float64() < config.BurstLossPBadToGood {
+			atomic.StoreInt32(&c.burstState, 0)
+		}
+	} else if crypto.Randomness.Float64() < config.BurstLossPGoodToBad {
+		atomic.StoreInt32(&c.burstState, 1)
+	}
+
+	if atomic.LoadInt32(&c.burstState) == 1 {
+		return crypto.Randomness.Float64() <= config.BurstLossBad
+	}
+	return crypto.Randomness.Float64() <= c.packetLoss
+}
+
+// enqueue books the message's transmission slot on the bandwidth-limited connection and returns the
+// combined queuing and transmission delay it experiences.
+func (c *Connection) enqueue() time.Duration {
+	now := time.Now()
+	if config.DiscreteEvent {
+		now = clock.Virtual.Now()
+	}
+
+	c.queueMutex.Lock()
+	defer c.queueMutex.Unlock()
+
+	if c.queueUntil.Before(now) {
+		c.queueUntil = now
+	}
+	c.queueUntil = c.queueUntil.Add(c.serviceTime)
+
+	return c.queueUntil.Sub(now)
+}
+
+// QueueLength returns the number of messages currently queued on the connection.
+func (c *Connection) QueueLength() int {
+	if c.serviceTime == 0 {
+		return 0
+	}
+
+	now := time.Now()
+	if config.DiscreteEvent {
+		now = clock.Virtual.Now()
+	}
+
+	c.queueMutex.Lock()
+	defer c.queueMutex.Unlock()
+
+	if !c.queueUntil.After(now) {
+		return 0
+	}
+
+	return int(c.queueUntil.Sub(now) / c.serviceTime)
 }
 
 func (c *Connection) SetDelay(delay time.Duration) {
 	c.networkDelay = delay
 }
 
+// FixDelay pins the connection to its configured delay instead of drawing a random delay per message.
+func (c *Connection) FixDelay() {
+	c.fixedDelay = true
+}
+
+func (c *Connection) SetPacketLoss(packetLoss float64) {
+	c.packetLoss = packetLoss
+}
+
 func (c *Connection) Shutdown() {
 	c.shutdownOnce.Do(func() {
 		c.timedExecutor.Shutdown(timedexecutor.CancelPendingTasks)
@@ -0,0 +1,100 @@
+package network
+
+import (
+	"github.com/iotaledger/hive.go/crypto"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/multivers-simulation/config"
+)
+
+// region Rewiring /////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// Rewiring models neighbor churn: a fraction of the connections is dropped and re-established between
+// randomly drawn pairs mid-simulation. The ConnectionsRewired event is exposed so metrics can correlate
+// opinion flips with rewiring rounds.
+
+// RewireEvents holds the events of the rewiring machinery.
+type RewireEvents struct {
+	// ConnectionsRewired is triggered after every rewiring round with the number of rewired connections.
+	ConnectionsRewired *events.Event
+}
+
+var Events = &RewireEvents{
+	ConnectionsRewired: events.NewEvent(rewireEventCaller),
+}
+
+func rewireEventCaller(handler interface{}, params ...interface{}) {
+	handler.(func(int))(params[0].(int))
+}
+
+// Rewire drops the given fraction of the network's connections and re-establishes the same number of
+// links between randomly drawn unconnected pairs, with fresh delay and loss drawn from the configured
+// ranges. It returns the number of rewired links.
+func (n *Network) Rewire(fraction float64) (rewired int) {
+	configuration := n.Configuration
+	type link struct{ source, target int }
+
+	links := make([]link, 0)
+	for sourceNodeID, peer := range n.Peers {
+		for neighborID := range peer.Neighbors {
+			if sourceNodeID < int(neighborID) {
+				links = append(links, link{sourceNodeID, int(neighborID)})
+			}
+		}
+	}
+
+	rewireCount := int(fraction * float64(len(links)))
+	for i := 0; i < rewireCount; i++ {
+		// drop a random remaining link
+		linkIndex := crypto.Randomness.Intn(len(links))
+		dropped := links[linkIndex]
+		links[linkIndex] = links[len(links)-1]
+		links = links[:len(links)-1]
+
+		n.Peers[dropped.source].Neighbors[PeerID(dropped.target)].Shutdown()
+		n.Peers[dropped.target].Neighbors[PeerID(dropped.source)].Shutdown()
+		delete(n.Peers[dropped.source].Neighbors, PeerID(dropped.target))
+		delete(n.Peers[dropped.target].Neighbors, PeerID(dropped.source))
+
+		// connect a random unconnected pair, giving up after a bounded number of draws on dense graphs
+		for attempts := 0; attempts < 10*len(n.Peers); attempts++ {
+			source := crypto.Randomness.Intn(len(n.Peers))
+			target := crypto.Randomness.Intn(len(n.Peers))
+			if source == target {
+				continue
+			}
+			if _, connected := n.Peers[source].Neighbors[PeerID(target)]; connected {
+				continue
+			}
+
+			randomNetworkDelay := configuration.RandomNetworkDelay()
+			reverseNetworkDelay := randomNetworkDelay
+			if config.AsymmetricDelays {
+				reverseNetworkDelay = configuration.RandomNetworkDelay()
+			}
+			randomPacketLoss := configuration.RandomPacketLoss()
+			forwardConnection := NewConnection(n.Peers[source], n.Peers[target], randomNetworkDelay, randomPacketLoss, configuration)
+			reverseConnection := NewConnection(n.Peers[target], n.Peers[source], reverseNetworkDelay, randomPacketLoss, configuration)
+			if config.AsymmetricDelays {
+				forwardConnection.FixDelay()
+				reverseConnection.FixDelay()
+			}
+			n.Peers[source].Neighbors[PeerID(target)] = forwardConnection
+			n.Peers[target].Neighbors[PeerID(source)] = reverseConnection
+			if source < target {
+				links = append(links, link{source, target})
+			} else {
+				links = append(links, link{target, source})
+			}
+			rewired++
+			break
+		}
+	}
+
+	if rewired > 0 {
+		Events.ConnectionsRewired.Trigger(rewired)
+	}
+
+	return
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,46 @@
+package network
+
+// region Peer scoring /////////////////////////////////////////////////////////////////////////////////////////////////
+
+// DropMisbehavingNeighbors evaluates the neighbor scores of all peers and disconnects the neighbors
+// whose share of invalid deliveries (duplicates, conflicting messages) exceeds maxInvalidRatio, once
+// they have delivered at least minDeliveries messages. It returns the number of dropped connections.
+func (n *Network) DropMisbehavingNeighbors(maxInvalidRatio float64, minDeliveries int64) (dropped int) {
+	for _, peer := range n.Peers {
+		misbehaving := make([]PeerID, 0)
+
+		peer.scoringMutex.Lock()
+		for neighborID, deliveries := range peer.neighborDeliveries {
+			if deliveries < minDeliveries {
+				continue
+			}
+			if invalid := peer.invalidDeliveries[neighborID]; float64(invalid) > maxInvalidRatio*float64(deliveries) {
+				misbehaving = append(misbehaving, neighborID)
+				log.Infof("Peer %d dropped neighbor %d: %d of %d deliveries were invalid",
+					peer.ID, neighborID, invalid, deliveries)
+			}
+		}
+		// the scores are reset on the drop, so a re-established connection starts with a clean slate
+		for _, neighborID := range misbehaving {
+			delete(peer.neighborDeliveries, neighborID)
+			delete(peer.invalidDeliveries, neighborID)
+		}
+		peer.scoringMutex.Unlock()
+
+		for _, neighborID := range misbehaving {
+			if connection, connected := peer.Neighbors[neighborID]; connected {
+				connection.Shutdown()
+				delete(peer.Neighbors, neighborID)
+				dropped++
+			}
+			if connection, connected := n.Peers[neighborID].Neighbors[peer.ID]; connected {
+				connection.Shutdown()
+				delete(n.Peers[neighborID].Neighbors, peer.ID)
+			}
+		}
+	}
+
+	return
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
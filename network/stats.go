@@ -0,0 +1,107 @@
+package network
+
+// region Topology statistics //////////////////////////////////////////////////////////////////////////////////////////
+
+// TopologyStatistics summarizes the structure of the generated peering graph, so runs can be
+// stratified by graph properties in later analysis. Diameter and average path length only cover the
+// reachable pairs when the graph is not connected.
+type TopologyStatistics struct {
+	Nodes                 int
+	Links                 int
+	Connected             bool
+	Diameter              int
+	AveragePathLength     float64
+	ClusteringCoefficient float64
+	DegreeHistogram       map[int]int
+}
+
+// ComputeTopologyStatistics analyzes the undirected view of the peering graph, where two peers count
+// as linked when at least one direction delivers.
+func (n *Network) ComputeTopologyStatistics() (statistics TopologyStatistics) {
+	nodeCount := len(n.Peers)
+	adjacency := make([]map[int]bool, nodeCount)
+	for i := range adjacency {
+		adjacency[i] = make(map[int]bool)
+	}
+	for _, peer := range n.Peers {
+		for neighborID := range peer.Neighbors {
+			adjacency[int(peer.ID)][int(neighborID)] = true
+			adjacency[int(neighborID)][int(peer.ID)] = true
+		}
+	}
+
+	statistics.Nodes = nodeCount
+	statistics.DegreeHistogram = make(map[int]int)
+	for _, neighbors := range adjacency {
+		statistics.Links += len(neighbors)
+		statistics.DegreeHistogram[len(neighbors)]++
+	}
+	statistics.Links /= 2
+
+	// breadth-first searches from every node for the diameter and the average path length
+	totalDistance, reachablePairs := 0, 0
+	statistics.Connected = true
+	for source := 0; source < nodeCount; source++ {
+		distances := make([]int, nodeCount)
+		for i := range distances {
+			distances[i] = -1
+		}
+		distances[source] = 0
+		queue := []int{source}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for neighbor := range adjacency[current] {
+				if distances[neighbor] < 0 {
+					distances[neighbor] = distances[current] + 1
+					queue = append(queue, neighbor)
+				}
+			}
+		}
+		for target, distance := range distances {
+			if target == source {
+				continue
+			}
+			if distance < 0 {
+				statistics.Connected = false
+				continue
+			}
+			totalDistance += distance
+			reachablePairs++
+			if distance > statistics.Diameter {
+				statistics.Diameter = distance
+			}
+		}
+	}
+	if reachablePairs > 0 {
+		statistics.AveragePathLength = float64(totalDistance) / float64(reachablePairs)
+	}
+
+	// average local clustering coefficient over the nodes with at least two neighbors
+	clusteringSum, clusteredNodes := 0.0, 0
+	for node, neighbors := range adjacency {
+		if len(neighbors) < 2 {
+			continue
+		}
+		linkedNeighborPairs := 0
+		for first := range neighbors {
+			for second := range adjacency[node] {
+				if first < second && adjacency[first][second] {
+					linkedNeighborPairs++
+				}
+			}
+		}
+		clusteringSum += float64(2*linkedNeighborPairs) / float64(len(neighbors)*(len(neighbors)-1))
+		clusteredNodes++
+	}
+	if clusteredNodes > 0 {
+		statistics.ClusteringCoefficient = clusteringSum / float64(clusteredNodes)
+	}
+
+	log.Infof("Topology statistics: %d links, diameter %d, average path length %.2f, clustering coefficient %.3f",
+		statistics.Links, statistics.Diameter, statistics.AveragePathLength, statistics.ClusteringCoefficient)
+
+	return
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
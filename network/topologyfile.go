@@ -0,0 +1,202 @@
+package network
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// region Topology file ////////////////////////////////////////////////////////////////////////////////////////////////
+
+// TopologyFromFile loads an explicit adjacency list as the network topology, so measured overlays or
+// hand-crafted pathological graphs can be replayed. CSV rows are
+// 'source,target[,delayMs[,packetLoss[,reverseDelayMs]]]' ('#' starts a comment); .graphml files are
+// parsed for their edge elements with optional 'delayMs', 'packetLoss' and 'reverseDelayMs' data keys.
+// reverseDelayMs gives the target→source direction its own delay for asymmetric routes. Edges without
+// an explicit delay or loss fall back to the configured random ranges.
+
+// fileEdge is one parsed edge of a topology file.
+type fileEdge struct {
+	source, target  int
+	delay           time.Duration
+	hasDelay        bool
+	reverseDelay    time.Duration
+	hasReverseDelay bool
+	packetLoss      float64
+	hasLoss         bool
+}
+
+func TopologyFromFile(fileName string) PeeringStrategy {
+	return func(network *Network, configuration *Configuration) {
+		var edges []fileEdge
+		if strings.ToLower(filepath.Ext(fileName)) == ".graphml" {
+			edges = parseGraphMLTopology(fileName)
+		} else {
+			edges = parseCSVTopology(fileName)
+		}
+
+		nodeCount := len(network.Peers)
+		for _, edge := range edges {
+			if edge.source < 0 || edge.source >= nodeCount || edge.target < 0 || edge.target >= nodeCount {
+				log.Fatalf("Topology file %s references edge %d--%d outside the node range [0, %d)",
+					fileName, edge.source, edge.target, nodeCount)
+			}
+
+			networkDelay := configuration.RandomNetworkDelay()
+			if edge.hasDelay {
+				networkDelay = edge.delay
+			}
+			reverseNetworkDelay := networkDelay
+			if edge.hasReverseDelay {
+				reverseNetworkDelay = edge.reverseDelay
+			}
+			packetLoss := configuration.RandomPacketLoss()
+			if edge.hasLoss {
+				packetLoss = edge.packetLoss
+			}
+
+			sourceConnection := NewConnection(network.Peers[edge.source], network.Peers[edge.target], networkDelay, packetLoss, configuration)
+			targetConnection := NewConnection(network.Peers[edge.target], network.Peers[edge.source], reverseNetworkDelay, packetLoss, configuration)
+			if edge.hasDelay || edge.hasReverseDelay {
+				sourceConnection.FixDelay()
+				targetConnection.FixDelay()
+			}
+			network.Peers[edge.source].Neighbors[PeerID(edge.target)] = sourceConnection
+			network.Peers[edge.target].Neighbors[PeerID(edge.source)] = targetConnection
+
+			log.Debugf("Connecting %s <-> %s [network delay (%s), packet loss (%0.4f%%)] ... [DONE]",
+				network.Peers[edge.source], network.Peers[edge.target], networkDelay, packetLoss*100)
+		}
+
+		totalNeighborCount := 0
+		for _, peer := range network.Peers {
+			totalNeighborCount += len(peer.Neighbors)
+		}
+		log.Infof("Average number of neighbors: %.1f", float64(totalNeighborCount)/float64(nodeCount))
+	}
+}
+
+// parseCSVTopology parses the 'source,target[,delayMs[,packetLoss]]' rows of a CSV adjacency list.
+func parseCSVTopology(fileName string) (edges []fileEdge) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		log.Fatalf("Failed to load topology from %s: %s", fileName, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		log.Fatalf("Failed to parse topology file %s: %s", fileName, err)
+	}
+
+	for i, record := range records {
+		if len(record) < 2 {
+			log.Fatalf("Topology file %s row %d needs at least source and target", fileName, i+1)
+		}
+		source, sourceErr := strconv.Atoi(strings.TrimSpace(record[0]))
+		target, targetErr := strconv.Atoi(strings.TrimSpace(record[1]))
+		if sourceErr != nil || targetErr != nil {
+			if i == 0 {
+				continue // a header row
+			}
+			log.Fatalf("Topology file %s row %d has non-numeric node IDs", fileName, i+1)
+		}
+
+		edge := fileEdge{source: source, target: target}
+		if len(record) > 2 && strings.TrimSpace(record[2]) != "" {
+			delayMs, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+			if err != nil {
+				log.Fatalf("Topology file %s row %d has an invalid delay", fileName, i+1)
+			}
+			edge.delay = time.Duration(delayMs * float64(time.Millisecond))
+			edge.hasDelay = true
+		}
+		if len(record) > 3 && strings.TrimSpace(record[3]) != "" {
+			packetLoss, err := strconv.ParseFloat(strings.TrimSpace(record[3]), 64)
+			if err != nil {
+				log.Fatalf("Topology file %s row %d has an invalid packet loss", fileName, i+1)
+			}
+			edge.packetLoss = packetLoss
+			edge.hasLoss = true
+		}
+		if len(record) > 4 && strings.TrimSpace(record[4]) != "" {
+			reverseDelayMs, err := strconv.ParseFloat(strings.TrimSpace(record[4]), 64)
+			if err != nil {
+				log.Fatalf("Topology file %s row %d has an invalid reverse delay", fileName, i+1)
+			}
+			edge.reverseDelay = time.Duration(reverseDelayMs * float64(time.Millisecond))
+			edge.hasReverseDelay = true
+		}
+		edges = append(edges, edge)
+	}
+
+	return
+}
+
+// graphMLDocument maps the subset of GraphML needed to read edges and their optional attributes.
+type graphMLDocument struct {
+	Graphs []struct {
+		Edges []struct {
+			Source string `xml:"source,attr"`
+			Target string `xml:"target,attr"`
+			Data   []struct {
+				Key   string `xml:"key,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"data"`
+		} `xml:"edge"`
+	} `xml:"graph"`
+}
+
+// parseGraphMLTopology parses the edge elements of a GraphML file. Node IDs may carry an 'n' prefix.
+func parseGraphMLTopology(fileName string) (edges []fileEdge) {
+	bytes, err := os.ReadFile(fileName)
+	if err != nil {
+		log.Fatalf("Failed to load topology from %s: %s", fileName, err)
+	}
+
+	var document graphMLDocument
+	if err := xml.Unmarshal(bytes, &document); err != nil {
+		log.Fatalf("Failed to parse topology file %s: %s", fileName, err)
+	}
+
+	for _, graph := range document.Graphs {
+		for _, graphEdge := range graph.Edges {
+			source, sourceErr := strconv.Atoi(strings.TrimPrefix(graphEdge.Source, "n"))
+			target, targetErr := strconv.Atoi(strings.TrimPrefix(graphEdge.Target, "n"))
+			if sourceErr != nil || targetErr != nil {
+				log.Fatalf("Topology file %s has non-numeric node IDs %s, %s", fileName, graphEdge.Source, graphEdge.Target)
+			}
+
+			edge := fileEdge{source: source, target: target}
+			for _, data := range graphEdge.Data {
+				value, err := strconv.ParseFloat(strings.TrimSpace(data.Value), 64)
+				if err != nil {
+					continue
+				}
+				switch data.Key {
+				case "delayMs":
+					edge.delay = time.Duration(value * float64(time.Millisecond))
+					edge.hasDelay = true
+				case "reverseDelayMs":
+					edge.reverseDelay = time.Duration(value * float64(time.Millisecond))
+					edge.hasReverseDelay = true
+				case "packetLoss":
+					edge.packetLoss = value
+					edge.hasLoss = true
+				}
+			}
+			edges = append(edges, edge)
+		}
+	}
+
+	return
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
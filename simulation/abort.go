@@ -0,0 +1,126 @@
+package simulation
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/multivers-simulation/adversary"
+	"github.com/iotaledger/multivers-simulation/clock"
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region Attack abort /////////////////////////////////////////////////////////////////////////////////////////////////
+
+// abortCondition is one parsed entry of config.AdversaryAbortConditions: the group calls its attack
+// off once its color holds the given share of the total weight or the given simulated time has
+// passed, whichever is configured.
+type abortCondition struct {
+	weightShare float64       // share of NodesTotalWeight the group's color has to reach, 0 disables
+	after       time.Duration // simulated time after which the group aborts, 0 disables
+}
+
+// parseAbortConditions parses the 'weight:percent' and 'time:seconds' entries of
+// config.AdversaryAbortConditions, one per adversary group in order, '-' for groups that never abort.
+func parseAbortConditions() (conditions map[int]abortCondition) {
+	conditions = make(map[int]abortCondition)
+	for groupID, entry := range config.AdversaryAbortConditions {
+		if entry == "-" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) != 2 {
+			log.Fatalf("Invalid adversary abort condition %q, expected 'weight:percent', 'time:seconds' or '-'", entry)
+		}
+
+		condition := abortCondition{}
+		switch parts[0] {
+		case "weight":
+			percentage, err := strconv.ParseFloat(parts[1], 64)
+			if err != nil || percentage <= 0 || percentage > 100 {
+				log.Fatalf("Invalid percentage in adversary abort condition %q", entry)
+			}
+			condition.weightShare = percentage / 100
+		case "time":
+			seconds, err := strconv.Atoi(parts[1])
+			if err != nil || seconds <= 0 {
+				log.Fatalf("Invalid duration in adversary abort condition %q", entry)
+			}
+			condition.after = time.Duration(seconds) * time.Second
+		default:
+			log.Fatalf("Unknown adversary abort condition type %q", parts[0])
+		}
+		conditions[groupID] = condition
+	}
+
+	return
+}
+
+// startAdversaryAbortWorker checks the configured abort conditions once per consensus monitor tick
+// and calls the attack of every group whose condition is met off: its nodes fall back to honest
+// behavior via adversary.Abortable, modeling an opportunistic attacker that stops once its goal is
+// reached or enough time was wasted.
+func startAdversaryAbortWorker(ctx context.Context, testNetwork *network.Network) {
+	interval := time.Duration(config.ConsensusMonitorTick) * time.Millisecond
+	conditions := parseAbortConditions()
+	aborted := make(map[int]bool)
+
+	check := func() {
+		monitoredWeights := testNetwork.Peer(config.MonitoredAWPeers[0]).Node.(multiverse.NodeInterface).Tangle().OpinionManager.ApprovalWeights()
+		for groupID, condition := range conditions {
+			if aborted[groupID] || groupID >= len(testNetwork.AdversaryGroups) {
+				continue
+			}
+			group := testNetwork.AdversaryGroups[groupID]
+
+			met := condition.after > 0 && sinceStart(simulationStartTime) >= condition.after
+			if !met && condition.weightShare > 0 {
+				color := multiverse.ColorFromStr(group.InitColor)
+				met = float64(monitoredWeights[color]) >= condition.weightShare*float64(config.NodesTotalWeight)
+			}
+			if !met {
+				continue
+			}
+
+			aborted[groupID] = true
+			abortedNodes := 0
+			for _, nodeID := range group.NodeIDs {
+				if abortable, isAbortable := testNetwork.Peer(nodeID).Node.(adversary.Abortable); isAbortable {
+					abortable.AbortAttack()
+					abortedNodes++
+				}
+			}
+			log.Infof("Adversary group %d aborted its attack, %d nodes fell back to honest behavior", groupID, abortedNodes)
+		}
+	}
+
+	if config.DiscreteEvent {
+		var scheduleCheck func()
+		scheduleCheck = func() {
+			clock.Virtual.ExecuteAfter(interval, func() {
+				check()
+				scheduleCheck()
+			})
+		}
+		scheduleCheck()
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(config.SlowdownFactor) * interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				check()
+			}
+		}
+	}()
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,114 @@
+package simulation
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/multivers-simulation/clock"
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region Adaptive PoW /////////////////////////////////////////////////////////////////////////////////
+
+// adaptivePowFactors holds the current difficulty-derived factor of every peer's issuance rate; the
+// issuance loops multiply it into the mana-derived base band on every iteration, so retargeting the
+// difficulty retargets the rates live, like the rate setter factors do.
+var (
+	adaptivePowFactors map[network.PeerID]float64
+	adaptivePowIssued  map[network.PeerID]int64
+	adaptivePowMutex   sync.RWMutex
+)
+
+func init() {
+	resetAdaptivePow()
+}
+
+// adaptivePowFactor returns the current adaptive PoW factor of the peer's issuance rate, 1 while the
+// adaptive PoW is disabled or the peer has not issued above its mana-fair rate yet.
+func adaptivePowFactor(peerID network.PeerID) float64 {
+	adaptivePowMutex.RLock()
+	defer adaptivePowMutex.RUnlock()
+
+	if factor, exists := adaptivePowFactors[peerID]; exists {
+		return factor
+	}
+	return 1
+}
+
+// countAdaptivePowIssuance accounts one issued message of the peer for the current retargeting window.
+func countAdaptivePowIssuance(peerID network.PeerID) {
+	adaptivePowMutex.Lock()
+	defer adaptivePowMutex.Unlock()
+	adaptivePowIssued[peerID]++
+}
+
+// resetAdaptivePow clears the difficulty factors and window counts of a previous run in the same
+// process.
+func resetAdaptivePow() {
+	adaptivePowMutex.Lock()
+	defer adaptivePowMutex.Unlock()
+	adaptivePowFactors = make(map[network.PeerID]float64)
+	adaptivePowIssued = make(map[network.PeerID]int64)
+}
+
+// startAdaptivePowWorker retargets the proof-of-work difficulty of every peer once per
+// AdaptivePowInterval of simulated time: a node issuing at or below its mana-fair rate works at the
+// base difficulty, every multiple of the fair rate it issues above it adds AdaptivePowGamma
+// difficulty units, and every unit halves the effective issuance rate. Unlike the rate setter the
+// difficulty also binds the adversary nodes, throttling their configured speedups.
+func startAdaptivePowWorker(ctx context.Context, testNetwork *network.Network) {
+	interval := time.Duration(config.AdaptivePowInterval) * time.Second
+
+	retarget := func() {
+		adaptivePowMutex.Lock()
+		defer adaptivePowMutex.Unlock()
+
+		for _, peer := range testNetwork.Peers {
+			issued := adaptivePowIssued[peer.ID]
+			adaptivePowIssued[peer.ID] = 0
+
+			fairRate := issuanceBand(peer.ID)
+			if fairRate <= 0 {
+				continue
+			}
+			recentRate := float64(issued) / float64(config.AdaptivePowInterval)
+
+			difficulty := config.AdaptivePowGamma * math.Max(0, recentRate/fairRate-1)
+			factor := math.Pow(2, -difficulty)
+			if factor < config.AdaptivePowMin {
+				factor = config.AdaptivePowMin
+			}
+			adaptivePowFactors[peer.ID] = factor
+		}
+	}
+
+	if config.DiscreteEvent {
+		var scheduleRetargeting func()
+		scheduleRetargeting = func() {
+			clock.Virtual.ExecuteAfter(interval, func() {
+				retarget()
+				scheduleRetargeting()
+			})
+		}
+		scheduleRetargeting()
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(config.SlowdownFactor) * interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				retarget()
+			}
+		}
+	}()
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,147 @@
+package simulation
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region Attack summary ///////////////////////////////////////////////////////////////////////////////////////////////
+
+// AttackSummary holds the headline numbers of an attack run, written to attack-*.json at shutdown:
+// did the initially losing branch of the conflict ever get confirmed by an honest node, how long did
+// the honest nodes disagree at most, how often were confirmations reversed and how many messages the
+// adversary issued.
+type AttackSummary struct {
+	InitialLosingColor      string        `json:"initialLosingColor"`
+	LosingColorConfirmed    bool          `json:"losingColorConfirmed"`
+	MaxHonestDisagreement   time.Duration `json:"maxHonestDisagreementNs"`
+	ConfirmationReversals   int64         `json:"confirmationReversals"`
+	AdversaryMessagesIssued int64         `json:"adversaryMessagesIssued"`
+}
+
+var (
+	attackMutex                 sync.Mutex
+	attackInitialLosingColor    multiverse.Color
+	attackHonestConfirmedColors map[multiverse.Color]bool
+	attackDisagreeing           bool
+	attackDisagreementStart     time.Duration
+	attackMaxDisagreement       time.Duration
+)
+
+// resetAttackSummary zeroes the attack summary state of a previous Run in the same process.
+func resetAttackSummary() {
+	attackMutex.Lock()
+	defer attackMutex.Unlock()
+	attackInitialLosingColor = multiverse.UndefinedColor
+	attackHonestConfirmedColors = make(map[multiverse.Color]bool)
+	attackDisagreeing = false
+	attackDisagreementStart = 0
+	attackMaxDisagreement = 0
+}
+
+// updateAttackSummary samples the honest opinion split once per monitor tick: it fixes the initially
+// losing color the first time more than one branch has honest support after the conflict was issued,
+// and tracks the longest stretch of simulated time during which the honest nodes disagreed.
+func updateAttackSummary() {
+	likes := getLikesPerColor(colorCounters, "opinions")
+	adversaryLikes := getLikesPerColor(adversaryCounters, "opinions")
+
+	coloredOpinions := 0
+	for i := range likes {
+		if likes[i]-adversaryLikes[i] > 0 {
+			coloredOpinions++
+		}
+	}
+
+	attackMutex.Lock()
+	defer attackMutex.Unlock()
+
+	elapsed := sinceStart(simulationStartTime)
+	if coloredOpinions >= 2 {
+		if !attackDisagreeing {
+			attackDisagreeing = true
+			attackDisagreementStart = elapsed
+		}
+		if disagreement := elapsed - attackDisagreementStart; disagreement > attackMaxDisagreement {
+			attackMaxDisagreement = disagreement
+		}
+	} else {
+		attackDisagreeing = false
+	}
+
+	if attackInitialLosingColor == multiverse.UndefinedColor && !dsIssuanceTime.IsZero() {
+		attackInitialLosingColor = losingColor()
+	}
+}
+
+// losingColor returns the conflict branch with the smallest honest approval weight, or
+// UndefinedColor while fewer than two branches have honest support.
+func losingColor() multiverse.Color {
+	losing, smallestWeight := multiverse.UndefinedColor, int64(0)
+	supportedBranches := 0
+	for _, color := range multiverse.ConflictingColors() {
+		honestWeight := colorCounters.Get("likeAccumulatedWeight", color) - adversaryCounters.Get("likeAccumulatedWeight", color)
+		if honestWeight <= 0 {
+			continue
+		}
+		supportedBranches++
+		if losing == multiverse.UndefinedColor || honestWeight < smallestWeight {
+			losing, smallestWeight = color, honestWeight
+		}
+	}
+
+	if supportedBranches < 2 {
+		return multiverse.UndefinedColor
+	}
+	return losing
+}
+
+// recordHonestColorConfirmation remembers every color an honest node ever confirmed, so the summary
+// can tell whether the initially losing branch made it through.
+func recordHonestColorConfirmation(confirmedColor multiverse.Color) {
+	attackMutex.Lock()
+	defer attackMutex.Unlock()
+	attackHonestConfirmedColors[confirmedColor] = true
+}
+
+// writeAttackSummary dumps the attack headline numbers to the given JSON file at shutdown.
+func writeAttackSummary(fileName string) {
+	attackMutex.Lock()
+	if attackDisagreeing {
+		if disagreement := sinceStart(simulationStartTime) - attackDisagreementStart; disagreement > attackMaxDisagreement {
+			attackMaxDisagreement = disagreement
+		}
+	}
+	summary := AttackSummary{
+		InitialLosingColor:      attackInitialLosingColor.String(),
+		LosingColorConfirmed:    attackInitialLosingColor != multiverse.UndefinedColor && attackHonestConfirmedColors[attackInitialLosingColor],
+		MaxHonestDisagreement:   attackMaxDisagreement,
+		AdversaryMessagesIssued: atomicCounters.Get("adversaryIssuedMessages"),
+	}
+	attackMutex.Unlock()
+
+	for i := 0; i < config.NodesCount; i++ {
+		if network.IsAdversary(i) {
+			continue
+		}
+		summary.ConfirmationReversals += nodeCounters[i].Get("unconfirmationCount")
+	}
+
+	bytes, err := json.MarshalIndent(summary, "", " ")
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	if err := ioutil.WriteFile(path.Join(config.ResultDir, fileName), bytes, 0644); err != nil {
+		log.Error(err)
+	}
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
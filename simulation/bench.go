@@ -0,0 +1,87 @@
+package simulation
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"time"
+)
+
+// region Benchmark mode ///////////////////////////////////////////////////////////////////////////////////////////////
+
+// The bench subcommand runs a fixed synthetic workload on the discrete-event scheduler and reports the
+// simulated messages processed per wall-clock second together with the peak heap usage, so performance
+// regressions in the tangle and network layers can be quantified across commits. The workload is pinned
+// (node count, TPS, duration, seed), making the numbers comparable between machines of the same class.
+
+// benchConfig returns the pinned benchmark workload.
+func benchConfig() Config {
+	cfg := CurrentConfig()
+	cfg.NodesCount = 100
+	cfg.TPS = 100
+	cfg.SimulationDuration = 30 * time.Second
+	cfg.DiscreteEvent = true
+	cfg.Seed = 1
+	cfg.SimulationStopThreshold = 1.0
+	cfg.StopConditions = ""
+
+	return cfg
+}
+
+// Bench executes the benchmark workload and logs the throughput and memory report.
+func Bench() {
+	resultDir, err := os.MkdirTemp("", "multiverse-bench-")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(resultDir)
+
+	cfg := benchConfig()
+	cfg.ResultDir = resultDir
+
+	samplerCtx, samplerCancel := context.WithCancel(context.Background())
+	peakHeap := trackPeakHeap(samplerCtx)
+
+	wallStart := time.Now()
+	results, err := Run(cfg)
+	wallElapsed := time.Since(wallStart)
+	samplerCancel()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Infof("Benchmark: %d nodes, %d TPS, %s simulated", cfg.NodesCount, cfg.TPS, cfg.SimulationDuration)
+	log.Infof("Benchmark: %d messages issued in %s wall time (%.0f messages/s)",
+		results.IssuedMessages, wallElapsed.Round(time.Millisecond),
+		float64(results.IssuedMessages)/wallElapsed.Seconds())
+	log.Infof("Benchmark: peak heap %.1f MB ... [DONE]", float64(<-peakHeap)/(1024*1024))
+}
+
+// trackPeakHeap samples the heap usage until the context is cancelled and then delivers the peak value.
+func trackPeakHeap(ctx context.Context) <-chan uint64 {
+	peak := make(chan uint64, 1)
+
+	go func() {
+		var memStats runtime.MemStats
+		peakHeap := uint64(0)
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				peak <- peakHeap
+				return
+			case <-ticker.C:
+				runtime.ReadMemStats(&memStats)
+				if memStats.HeapAlloc > peakHeap {
+					peakHeap = memStats.HeapAlloc
+				}
+			}
+		}
+	}()
+
+	return peak
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
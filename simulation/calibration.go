@@ -0,0 +1,84 @@
+package simulation
+
+import (
+	"math"
+	"runtime"
+	"time"
+
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region Slowdown calibration /////////////////////////////////////////////////////////////////////////////////////////
+
+const (
+	// calibrationProbeDuration is the length of the synthetic storage burst used to probe the host.
+	calibrationProbeDuration = 250 * time.Millisecond
+	// calibrationSafetyFactor leaves headroom for gossip, solidification and the monitoring goroutines
+	// that the storage probe does not exercise.
+	calibrationSafetyFactor = 0.25
+	// calibrationMaxSlowdown is the largest SlowdownFactor the calibration accepts before declaring the
+	// configured load unsustainable on this host.
+	calibrationMaxSlowdown = 1000
+)
+
+// CalibrateSlowdownFactor probes how many messages per second the host can process and raises
+// SlowdownFactor when the configured TPS would overload it, since silently skewed timing invalidates the
+// results. The run is aborted when even the maximum accepted slowdown cannot sustain the load.
+func CalibrateSlowdownFactor() {
+	processingRate := measureProcessingRate()
+	capacity := processingRate * float64(runtime.NumCPU()) * calibrationSafetyFactor
+	// every message is eventually processed by every node, so the host handles TPS * NodesCount
+	// messages per simulated second
+	requiredRate := float64(config.TPS * config.NodesCount)
+
+	minSlowdown := int(math.Ceil(requiredRate / capacity))
+	if minSlowdown < 1 {
+		minSlowdown = 1
+	}
+	log.Infof("Calibration: host sustains ~%.0f msg/s per core, configured load is %.0f msg/s, minimum SlowdownFactor is %d",
+		processingRate, requiredRate, minSlowdown)
+
+	if minSlowdown > calibrationMaxSlowdown {
+		log.Fatalf("Host cannot sustain %d TPS with %d nodes even with SlowdownFactor %d; reduce the TPS or the node count",
+			config.TPS, config.NodesCount, calibrationMaxSlowdown)
+	}
+	if minSlowdown > config.SlowdownFactor {
+		log.Warnf("SlowdownFactor %d cannot sustain the configured load, raising it to %d", config.SlowdownFactor, minSlowdown)
+		config.SlowdownFactor = minSlowdown
+		return
+	}
+	log.Infof("SlowdownFactor %d can sustain the configured load", config.SlowdownFactor)
+}
+
+// measureProcessingRate stores a burst of synthetic messages into a throwaway tangle and returns the
+// achieved rate. The tangle is never set up on a peer, so no network state is touched by the probe.
+func measureProcessingRate() float64 {
+	tangle := multiverse.NewTangle()
+
+	// the probe messages use negative IDs so the message ID counter of the real run stays untouched
+	messageID := multiverse.MessageID(-1)
+	parent := multiverse.Genesis
+	processed := 0
+	start := time.Now()
+	for time.Since(start) < calibrationProbeDuration {
+		message := &multiverse.Message{
+			ID:            messageID,
+			StrongParents: multiverse.NewMessageIDs(parent),
+			WeakParents:   multiverse.NewMessageIDs(),
+			Issuer:        network.PeerID(0),
+			Payload:       multiverse.UndefinedColor,
+			IssuanceTime:  time.Now(),
+		}
+		tangle.Storage.Store(message)
+
+		parent = messageID
+		messageID--
+		processed++
+	}
+
+	return float64(processed) / time.Since(start).Seconds()
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,142 @@
+package simulation
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"os"
+	"path"
+)
+
+// region Run comparison ///////////////////////////////////////////////////////////////////////////////////////////////
+
+// The compare subcommand condenses two or more finished result directories into one delta report, so a
+// protocol parameter change can be evaluated against a baseline without post-processing the raw CSVs.
+// The first directory is the baseline all others are compared against. The report is written as
+// compare-report.json and compare-report.csv into the working directory.
+
+// ComparedRun holds the key outcomes of one result directory together with its deltas to the baseline.
+type ComparedRun struct {
+	Dir                   string           `json:"dir"`
+	ConsensusTimeNs       float64          `json:"consensusTimeNs"`
+	Flips                 float64          `json:"flips"`
+	HonestFlips           float64          `json:"honestFlips"`
+	ConfirmationLatencyNs SummaryStatistic `json:"confirmationLatencyNs"`
+	LatencyP50Ns          float64          `json:"latencyP50Ns"`
+	LatencyP90Ns          float64          `json:"latencyP90Ns"`
+	LatencyP99Ns          float64          `json:"latencyP99Ns"`
+	ConsensusTimeDeltaNs  float64          `json:"consensusTimeDeltaNs"`
+	FlipsDelta            float64          `json:"flipsDelta"`
+	MeanLatencyDeltaNs    float64          `json:"meanLatencyDeltaNs"`
+}
+
+// CompareReport is the document written to compare-report.json.
+type CompareReport struct {
+	Baseline string        `json:"baseline"`
+	Runs     []ComparedRun `json:"runs"`
+}
+
+// Compare builds the delta report of the given result directories, the first one acting as baseline.
+func Compare(resultDirs []string) {
+	if len(resultDirs) < 2 {
+		log.Fatal("The compare subcommand needs at least two result directories")
+	}
+
+	report := CompareReport{Baseline: resultDirs[0]}
+	for _, resultDir := range resultDirs {
+		report.Runs = append(report.Runs, comparedRun(resultDir))
+	}
+	baseline := report.Runs[0]
+	for i := range report.Runs {
+		report.Runs[i].ConsensusTimeDeltaNs = report.Runs[i].ConsensusTimeNs - baseline.ConsensusTimeNs
+		report.Runs[i].FlipsDelta = report.Runs[i].Flips - baseline.Flips
+		report.Runs[i].MeanLatencyDeltaNs = report.Runs[i].ConfirmationLatencyNs.Mean - baseline.ConfirmationLatencyNs.Mean
+	}
+
+	writeCompareJSON(report)
+	writeCompareCSV(report)
+	log.Infof("Compared %d result directories against baseline %s ... [DONE]", len(resultDirs), report.Baseline)
+}
+
+// comparedRun extracts the key outcomes of one result directory.
+func comparedRun(resultDir string) (run ComparedRun) {
+	run.Dir = resultDir
+	run.ConsensusTimeNs, _ = lastColumnValue(resultDir, "cc-", "ns since start")
+	run.Flips, _ = lastColumnValue(resultDir, "cc-", "Flips (Winning color changed)")
+	run.HonestFlips, _ = lastColumnValue(resultDir, "cc-", "Honest nodes Flips")
+
+	latencies := readColumnValues(resultDir, "aw0-", "Confirmation Time (ns)")
+	run.ConfirmationLatencyNs = sanitizeStatistic(summarize(latencies))
+	run.LatencyP50Ns = nanToZero(Percentile(latencies, 50))
+	run.LatencyP90Ns = nanToZero(Percentile(latencies, 90))
+	run.LatencyP99Ns = nanToZero(Percentile(latencies, 99))
+
+	return
+}
+
+// sanitizeStatistic zeroes the NaN fields of underpopulated statistics, as JSON cannot represent NaN.
+func sanitizeStatistic(statistic SummaryStatistic) SummaryStatistic {
+	statistic.Mean = nanToZero(statistic.Mean)
+	statistic.Stddev = nanToZero(statistic.Stddev)
+	statistic.CI95Low = nanToZero(statistic.CI95Low)
+	statistic.CI95High = nanToZero(statistic.CI95High)
+
+	return statistic
+}
+
+func nanToZero(value float64) float64 {
+	if math.IsNaN(value) {
+		return 0
+	}
+	return value
+}
+
+func writeCompareJSON(report CompareReport) {
+	bytes, err := json.MarshalIndent(report, "", " ")
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	if err := os.WriteFile("compare-report.json", bytes, 0644); err != nil {
+		log.Error(err)
+	}
+}
+
+func writeCompareCSV(report CompareReport) {
+	file, err := os.Create("compare-report.csv")
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	header := []string{"Dir", "Consensus Time (ns)", "Flips", "Honest Flips",
+		"Mean Confirmation Latency (ns)", "Latency p50 (ns)", "Latency p90 (ns)", "Latency p99 (ns)",
+		"Consensus Time Delta (ns)", "Flips Delta", "Mean Latency Delta (ns)"}
+	if err := writer.Write(header); err != nil {
+		log.Fatal(err)
+	}
+	for _, run := range report.Runs {
+		record := []string{
+			path.Clean(run.Dir),
+			formatStatistic(run.ConsensusTimeNs),
+			formatStatistic(run.Flips),
+			formatStatistic(run.HonestFlips),
+			formatStatistic(run.ConfirmationLatencyNs.Mean),
+			formatStatistic(run.LatencyP50Ns),
+			formatStatistic(run.LatencyP90Ns),
+			formatStatistic(run.LatencyP99Ns),
+			formatStatistic(run.ConsensusTimeDeltaNs),
+			formatStatistic(run.FlipsDelta),
+			formatStatistic(run.MeanLatencyDeltaNs),
+		}
+		if err := writer.Write(record); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
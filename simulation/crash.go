@@ -0,0 +1,63 @@
+package simulation
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// region Crash handling ///////////////////////////////////////////////////////////////////////////////////////////////
+
+// Long runs killed by a scheduler (SIGTERM), interrupted from the terminal (SIGINT) or crashing with a
+// panic on the simulation goroutine would normally lose everything still buffered in the CSV writers.
+// The crash handler flushes all writers and writes the manifest with a truncated-run marker before the
+// process dies, so the data up to the failure point stays usable.
+
+var (
+	// runTruncated marks the manifest of a run that did not end cleanly.
+	runTruncated bool
+
+	crashFlushOnce sync.Once
+)
+
+// listenForTerminationSignals flushes partial results and exits when the process is asked to terminate,
+// until the run context is cancelled by a clean shutdown.
+func listenForTerminationSignals(ctx context.Context, resultsWriters []*csv.Writer) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			signal.Stop(signals)
+		case receivedSignal := <-signals:
+			log.Warnf("Received %s, flushing partial results ...", receivedSignal)
+			flushPartialResults(resultsWriters)
+			os.Exit(1)
+		}
+	}()
+}
+
+// flushOnPanic is deferred by Run to save the partial results of a crashing run before the panic unwinds.
+func flushOnPanic(resultsWriters []*csv.Writer) {
+	if recovered := recover(); recovered != nil {
+		log.Errorf("Simulation panicked: %v, flushing partial results ...", recovered)
+		flushPartialResults(resultsWriters)
+		panic(recovered)
+	}
+}
+
+// flushPartialResults flushes all CSV writers and writes the manifest with the truncated-run marker set.
+// It runs at most once, so a panic racing a termination signal does not corrupt the manifest.
+func flushPartialResults(resultsWriters []*csv.Writer) {
+	crashFlushOnce.Do(func() {
+		runTruncated = true
+		flushWriters(resultsWriters)
+		writeRunManifest()
+	})
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,123 @@
+package simulation
+
+import (
+	"context"
+	"time"
+
+	"github.com/iotaledger/hive.go/crypto"
+	"github.com/iotaledger/multivers-simulation/clock"
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region FPC voting ///////////////////////////////////////////////////////////////////////////////////
+
+// fpcOpinionState tracks how long a node's opinion of one conflict set has stayed unchanged, the
+// finalization criterion of the FPC rounds.
+type fpcOpinionState struct {
+	opinion multiverse.Color
+	rounds  int
+}
+
+// fpcStates holds the per-node, per-conflict-set voting state across the rounds.
+var fpcStates = make(map[network.PeerID]map[multiverse.ConflictSetID]*fpcOpinionState)
+
+// resetFPC clears the voting state of a previous run in the same process.
+func resetFPC() {
+	fpcStates = make(map[network.PeerID]map[multiverse.ConflictSetID]*fpcOpinionState)
+}
+
+// startFPCWorker runs one FPC voting round per FPCRoundInterval of simulated time: every honest node
+// queries FPCQuerySampleSize random nodes for their opinion of every conflict set and adopts a color
+// once its share among the defined answers exceeds the round's threshold, drawn uniformly from
+// [FPCThreshold, FPCThresholdUpper] like the shared random threshold of the protocol. An opinion that
+// survives FPCFinalizationRounds consecutive rounds unchanged is finalized. The adversary nodes keep
+// their own voting behaviors but are queried like everybody else.
+func startFPCWorker(ctx context.Context, testNetwork *network.Network) {
+	interval := time.Duration(config.FPCRoundInterval) * time.Second
+
+	round := func() {
+		threshold := config.FPCThreshold + crypto.Randomness.Float64()*(config.FPCThresholdUpper-config.FPCThreshold)
+
+		for _, peer := range testNetwork.Peers {
+			if network.IsAdversary(int(peer.ID)) {
+				continue
+			}
+			opinionManager := peer.Node.(multiverse.NodeInterface).Tangle().OpinionManager
+
+			for _, set := range multiverse.ConflictSets() {
+				votes := make(map[multiverse.Color]int)
+				answers := 0
+				for i := 0; i < config.FPCQuerySampleSize; i++ {
+					queried := testNetwork.Peers[int(crypto.Randomness.Float64()*float64(len(testNetwork.Peers)))]
+					if queried.ID == peer.ID {
+						continue
+					}
+					if opinion := queried.Node.(multiverse.NodeInterface).Tangle().OpinionManager.OpinionOf(set); opinion != multiverse.UndefinedColor {
+						votes[opinion]++
+						answers++
+					}
+				}
+				if answers == 0 {
+					continue
+				}
+
+				opinion := opinionManager.OpinionOf(set)
+				for color, count := range votes {
+					if float64(count)/float64(answers) > threshold {
+						opinion = color
+					}
+				}
+				if opinion != opinionManager.OpinionOf(set) {
+					opinionManager.SetOpinion(opinion)
+				}
+
+				if opinion == multiverse.UndefinedColor {
+					continue
+				}
+				states, exists := fpcStates[peer.ID]
+				if !exists {
+					states = make(map[multiverse.ConflictSetID]*fpcOpinionState)
+					fpcStates[peer.ID] = states
+				}
+				state, exists := states[set]
+				if !exists || state.opinion != opinion {
+					states[set] = &fpcOpinionState{opinion: opinion, rounds: 1}
+					continue
+				}
+				state.rounds++
+				if state.rounds == config.FPCFinalizationRounds {
+					opinionManager.FinalizeColor(opinion)
+				}
+			}
+		}
+	}
+
+	if config.DiscreteEvent {
+		var scheduleRound func()
+		scheduleRound = func() {
+			clock.Virtual.ExecuteAfter(interval, func() {
+				round()
+				scheduleRound()
+			})
+		}
+		scheduleRound()
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(config.SlowdownFactor) * interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				round()
+			}
+		}
+	}()
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////
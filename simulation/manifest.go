@@ -0,0 +1,134 @@
+package simulation
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/multivers-simulation/config"
+)
+
+// region Run manifest /////////////////////////////////////////////////////////////////////////////////////////////////
+
+// The manifest.json written at the end of every run indexes all produced output files together with the
+// metadata needed to validate them (config hash, git commit, seed, start and end times), so downstream
+// analysis pipelines can discover and sanity-check results without parsing log output.
+
+// RunManifest is the document written to manifest.json.
+type RunManifest struct {
+	ConfigHash string         `json:"configHash"`
+	GitCommit  string         `json:"gitCommit"`
+	Seed       int64          `json:"seed"`
+	StartTime  time.Time      `json:"startTime"`
+	EndTime    time.Time      `json:"endTime"`
+	Truncated  bool           `json:"truncated"`
+	Files      []ManifestFile `json:"files"`
+}
+
+// ManifestFile describes one output file of the run. Rows counts the CSV data rows (excluding the header)
+// and is -1 for non-CSV files.
+type ManifestFile struct {
+	Name      string `json:"name"`
+	SizeBytes int64  `json:"sizeBytes"`
+	Rows      int    `json:"rows"`
+}
+
+// writeRunManifest indexes all output files of the current run and writes manifest.json.
+func writeRunManifest() {
+	manifest := RunManifest{
+		ConfigHash: configHash(),
+		GitCommit:  vcsRevision(),
+		Seed:       config.Seed,
+		StartTime:  simulationStartTime,
+		EndTime:    time.Now(),
+		Truncated:  runTruncated,
+	}
+
+	entries, err := os.ReadDir(config.ResultDir)
+	if err != nil {
+		log.Warnf("Failed to index result directory for the manifest: %s", err)
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.Contains(entry.Name(), simulationStartTimeStr) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		rows := -1
+		if path.Ext(entry.Name()) == ".csv" {
+			rows = csvRowCount(path.Join(config.ResultDir, entry.Name()))
+		}
+		manifest.Files = append(manifest.Files, ManifestFile{
+			Name:      entry.Name(),
+			SizeBytes: info.Size(),
+			Rows:      rows,
+		})
+	}
+
+	bytes, err := json.MarshalIndent(manifest, "", " ")
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	if err := os.WriteFile(path.Join(config.ResultDir, "manifest.json"), bytes, 0644); err != nil {
+		log.Error(err)
+	}
+}
+
+// configHash returns the SHA-256 of the dumped configuration file, identifying runs with identical settings.
+func configHash() string {
+	bytes, err := os.ReadFile(path.Join(config.ResultDir, "aw-"+simulationStartTimeStr+".config"))
+	if err != nil {
+		return ""
+	}
+	hash := sha256.Sum256(bytes)
+
+	return hex.EncodeToString(hash[:])
+}
+
+// vcsRevision returns the git commit the binary was built from, if it was stamped into the build info.
+func vcsRevision() string {
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, setting := range buildInfo.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+
+	return ""
+}
+
+// csvRowCount counts the data rows of the CSV file, excluding the header.
+func csvRowCount(fileName string) int {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return -1
+	}
+	defer file.Close()
+
+	rows := 0
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 1024*1024)
+	for scanner.Scan() {
+		rows++
+	}
+	if rows > 0 {
+		rows-- // the header
+	}
+
+	return rows
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
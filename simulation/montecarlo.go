@@ -0,0 +1,139 @@
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path"
+
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+)
+
+// region Monte Carlo summary //////////////////////////////////////////////////////////////////////////////////////////
+
+// The repetitions of a Monte Carlo run (config.Runs > 1) are condensed into runs-summary.json so that the
+// basic statistics of a paper (mean and 95% confidence interval of the key outcomes) are available without
+// post-processing the raw CSVs. The interval uses the normal approximation mean ± 1.96 * stddev / sqrt(n).
+
+// SummaryStatistic describes the distribution of one outcome across all repetitions.
+type SummaryStatistic struct {
+	Samples  int     `json:"samples"`
+	Mean     float64 `json:"mean"`
+	Stddev   float64 `json:"stddev"`
+	CI95Low  float64 `json:"ci95Low"`
+	CI95High float64 `json:"ci95High"`
+}
+
+// RunsSummary is the document written to runs-summary.json.
+type RunsSummary struct {
+	Runs    int                         `json:"runs"`
+	Seed    int64                       `json:"seed"`
+	Metrics map[string]SummaryStatistic `json:"metrics"`
+}
+
+// confirmedColumns returns the cc result columns that count honest confirmations of the given color
+// (total minus adversary nodes).
+func confirmedColumns(color multiverse.Color) [2]string {
+	return [2]string{
+		fmt.Sprintf("%s (Confirmed)", color.Name()),
+		fmt.Sprintf("%s (Adversary Confirmed)", color.Name()),
+	}
+}
+
+// writeRunsSummary collects per-run outcomes from the repetition directories and writes the summary JSON.
+func writeRunsSummary(runDirs []string) {
+	confirmationTimes := make([]float64, 0)
+	flipCounts := make([]float64, 0)
+	consensusTimes := make([]float64, 0)
+	adversarySuccesses := make([]float64, 0)
+
+	for _, runDir := range runDirs {
+		if runDir == "" {
+			continue
+		}
+		confirmationTimes = append(confirmationTimes, readColumnValues(runDir, "aw0-", "Confirmation Time (ns)")...)
+		if flips, ok := lastColumnValue(runDir, "cc-", "Flips (Winning color changed)"); ok {
+			flipCounts = append(flipCounts, flips)
+		}
+		if consensusTime, ok := lastColumnValue(runDir, "cc-", "ns since start"); ok {
+			consensusTimes = append(consensusTimes, consensusTime)
+		}
+		if success, ok := adversarySuccess(runDir); ok {
+			adversarySuccesses = append(adversarySuccesses, success)
+		}
+	}
+
+	summary := RunsSummary{
+		Runs: config.Runs,
+		Seed: config.Seed,
+		Metrics: map[string]SummaryStatistic{
+			"confirmationTimeNs": summarize(confirmationTimes),
+			"flips":              summarize(flipCounts),
+			"consensusTimeNs":    summarize(consensusTimes),
+		},
+	}
+	if len(adversarySuccesses) > 0 {
+		summary.Metrics["adversarySuccessRate"] = summarize(adversarySuccesses)
+	}
+
+	bytes, err := json.MarshalIndent(summary, "", " ")
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	if err := os.WriteFile(path.Join(config.ResultDir, "runs-summary.json"), bytes, 0644); err != nil {
+		log.Error(err)
+	}
+}
+
+// adversarySuccess reports whether the first adversary group's initial color ended up as the color with the
+// most honest confirmations in the given run (1 for success, 0 for failure).
+func adversarySuccess(runDir string) (success float64, ok bool) {
+	if len(config.AdversaryInitColors) == 0 {
+		return
+	}
+	adversaryColor := multiverse.ColorFromStr(config.AdversaryInitColors[0])
+	if adversaryColor == multiverse.UndefinedColor {
+		return
+	}
+
+	honestConfirmed := func(color multiverse.Color) float64 {
+		columns := confirmedColumns(color)
+		total, totalOk := lastColumnValue(runDir, "cc-", columns[0])
+		adversaryConfirmed, adversaryOk := lastColumnValue(runDir, "cc-", columns[1])
+		if !totalOk || !adversaryOk {
+			return math.NaN()
+		}
+		return total - adversaryConfirmed
+	}
+
+	adversaryColorConfirmed := honestConfirmed(adversaryColor)
+	if math.IsNaN(adversaryColorConfirmed) {
+		return
+	}
+	for _, color := range multiverse.ConflictingColors() {
+		if confirmed := honestConfirmed(color); !math.IsNaN(confirmed) && confirmed > adversaryColorConfirmed {
+			return 0, true
+		}
+	}
+
+	return 1, true
+}
+
+func summarize(samples []float64) SummaryStatistic {
+	mean := Mean(samples)
+	stddev := Stddev(samples)
+	margin := 1.96 * stddev / math.Sqrt(float64(len(samples)))
+
+	return SummaryStatistic{
+		Samples:  len(samples),
+		Mean:     mean,
+		Stddev:   stddev,
+		CI95Low:  mean - margin,
+		CI95High: mean + margin,
+	}
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -15,6 +15,46 @@ var log = logger.New("Simulation")
 func ParseFlags() {
 
 	// Define the configuration flags
+	runsPtr :=
+		flag.Int("runs", config.Runs, "Number of independent repetitions executed in parallel child processes with distinct seeds")
+	seedPtr :=
+		flag.Int64("seed", config.Seed, "Base seed for the pseudo random sources, 0 keeps the time-based seeding")
+	sweepPtr :=
+		flag.String("sweep", config.Sweep, "Parameter sweep specification, e.g. 'zipfParameter=0.5,0.9;packetLoss=0:0.2:0.05'; every combination runs in its own subdirectory of resultDir")
+	discreteEventPtr :=
+		flag.Bool("discreteEvent", config.DiscreteEvent, "Run on the discrete-event scheduler with a virtual clock; deterministic and ignores the slowdown factor")
+	recordTracePtr :=
+		flag.String("recordTrace", config.RecordTrace, "Path of a trace file to record every message issuance and delivery event to")
+	replayTracePtr :=
+		flag.String("replayTrace", config.ReplayTrace, "Path of a recorded trace file to replay through the tangle logic instead of running the network layer")
+	snapshotExportFilePtr :=
+		flag.String("snapshotExportFile", config.SnapshotExportFile, "Path to write the confirmed state of the observed peer to at the end of the run")
+	snapshotImportFilePtr :=
+		flag.String("snapshotImportFile", config.SnapshotImportFile, "Path of a previously exported snapshot to warm-start every node's tangle from")
+	genesisMessagesPtr :=
+		flag.String("genesisMessages", "", "Preassigned genesis messages as 'color:weight' entries, e.g. 'R:40 B:30', so a run begins mid-conflict instead of from the single uncolored genesis")
+	metricsWarmupPtr :=
+		flag.Int("metricsWarmup", config.MetricsWarmup, "Warm-up period in simulated seconds during which no metrics are recorded")
+	stopConditionsPtr :=
+		flag.String("stopConditions", config.StopConditions, "Comma-separated stop conditions ending the run when any of them is met: consensus, adversarySuccess, wallClock, metastability, maxMessages")
+	wallClockLimitPtr :=
+		flag.Int("wallClockLimit", config.WallClockLimit, "Real-time limit in seconds for the wallClock stop condition, 0 disables it")
+	metastabilityFlipsPtr :=
+		flag.Int("metastabilityFlips", config.MetastabilityFlips, "Number of honest winning-color flips after which the metastability stop condition triggers")
+	maxIssuedMessagesPtr :=
+		flag.Int("maxIssuedMessages", config.MaxIssuedMessages, "Number of issued messages after which the maxMessages stop condition ends the run, 0 disables it")
+	scenarioPtr :=
+		flag.String("scenario", config.Scenario, "Path of a YAML scenario file scripting a timeline of events against the running network")
+	calibrateSlowdownPtr :=
+		flag.Bool("calibrateSlowdown", config.CalibrateSlowdown, "Probe the host before a realtime run and raise the slowdown factor automatically when it cannot sustain the configured TPS")
+	progressJSONPtr :=
+		flag.Bool("progressJSON", config.ProgressJSON, "Emit one machine-readable progress JSON object per monitor tick on stderr")
+	validatePtr :=
+		flag.Bool("validate", config.Validate, "Check the configuration invariants and print the effective configuration without running the simulation")
+	listStrategiesPtr :=
+		flag.Bool("listStrategies", config.ListStrategies, "Print the registered adversary strategies with their type codes and exit")
+	maxMemoryMBPtr :=
+		flag.Int("maxMemoryMB", config.MaxMemoryMB, "Memory ceiling in MB above which the run is ended gracefully with final dumps, 0 disables it")
 	nodesCountPtr :=
 		flag.Int("nodesCount", config.NodesCount, "The number of nodes")
 	nodesTotalWeightPtr :=
@@ -25,12 +65,44 @@ func ParseFlags() {
 		flag.Float64("confirmationThreshold", config.ConfirmationThreshold, "The confirmationThreshold of confirmed messages/color")
 	confirmationThresholdAbsolutePtr :=
 		flag.Bool("confirmationThresholdAbsolute", config.ConfirmationThresholdAbsolute, "If set to false, the weight is counted by subtracting AW of the two largest conflicting branches.")
+	confirmationRulePtr :=
+		flag.String("confirmationRule", config.ConfirmationRule, "The message confirmation rule: WW (witness weight fraction), AW (absolute witness weight), Milestone (only coordinator milestones confirm) or Hybrid (witness weight fraction plus a minimum age)")
+	confirmationWWFractionPtr :=
+		flag.Float64("confirmationWWFraction", config.ConfirmationWWFraction, "The fraction of the witness weight scale at which the WW and Hybrid rules confirm a message")
+	confirmationAbsoluteWeightPtr :=
+		flag.Int("confirmationAbsoluteWeight", config.ConfirmationAbsoluteWeight, "The witness weight at which the AW rule confirms a message")
+	confirmationMinAgePtr :=
+		flag.Int("confirmationMinAge", config.ConfirmationMinAge, "The minimum age in seconds a message additionally needs under the Hybrid rule")
+	markerBasedAWPtr :=
+		flag.Bool("markerBasedAW", config.MarkerBasedAW, "Approval weight hops only between marker messages instead of walking the full past cone of every solid message")
+	markerSpacingPtr :=
+		flag.Int("markerSpacing", config.MarkerSpacing, "Every message whose height is a multiple of markerSpacing is a marker")
+	confirmationGradesPtr :=
+		flag.String("confirmationGrades", "", "Ascending witness weight fractions at which a message reaches confirmation grade 1, 2, ...")
 	parentsCountPtr :=
 		flag.Int("parentsCount", config.ParentsCount, "The parents count for a message")
 	weakTipsRatioPtr :=
 		flag.Float64("weakTipsRatio", config.WeakTipsRatio, "The ratio of weak tips")
+	likeReferencesEnabledPtr :=
+		flag.Bool("likeReferencesEnabled", config.LikeReferencesEnabled, "Messages carry shallow like references expressing the issuer's opinion on every live conflict explicitly")
 	tsaPtr :=
-		flag.String("tsa", config.TSA, "The tip selection algorithm")
+		flag.String("tsa", config.TSA, "The tip selection algorithm: POW, URTS, RURTS or MRTS")
+	parentsCountPolicyPtr :=
+		flag.String("parentsCountPolicy", config.ParentsCountPolicy, "How parentsCount is applied: upto, exact or adaptive")
+	adaptiveParentsTipsPerParentPtr :=
+		flag.Int("adaptiveParentsTipsPerParent", config.AdaptiveParentsTipsPerParent, "Adaptive parents policy: one strong parent per this many tips in the pool")
+	pastConeCheckEnabledPtr :=
+		flag.Bool("pastConeCheckEnabled", config.PastConeCheckEnabled, "Refuse strong parents whose past cone approves a branch the node dislikes")
+	orphanageReportingPtr :=
+		flag.Bool("orphanageReporting", config.OrphanageReporting, "Dump the per-issuer and aggregate orphan rates to or-*.csv unconditionally")
+	orphanageHorizonPtr :=
+		flag.Int("orphanageHorizon", config.OrphanageHorizon, "Age in simulated seconds after which a still-unreferenced message counts as orphaned")
+	reattachmentEnabledPtr :=
+		flag.Bool("reattachmentEnabled", config.ReattachmentEnabled, "Honest nodes reissue the payloads of own messages that stayed unreferenced past the deadline")
+	reattachmentDeadlinePtr :=
+		flag.Int("reattachmentDeadline", config.ReattachmentDeadline, "Age in seconds after which an own unreferenced message is reattached")
+	reattachmentIntervalPtr :=
+		flag.Int("reattachmentInterval", config.ReattachmentInterval, "The interval between two reattachment checks in seconds")
 	tpsPtr :=
 		flag.Int("tps", config.TPS, "the tips per seconds")
 	slowdownFactorPtr :=
@@ -39,14 +111,118 @@ func ParseFlags() {
 		flag.Int("consensusMonitorTick", config.ConsensusMonitorTick, "The tick to monitor the consensus, in milliseconds")
 	doubleSpendDelayPtr :=
 		flag.Int("doubleSpendDelay", config.DoubleSpendDelay, "Delay for issuing double spend transactions. (Seconds)")
+	doubleSpendSchedulePtr :=
+		flag.String("doubleSpendSchedule", "", "Schedule of conflict issuances as 'delaySeconds:issuer:color' entries, e.g. '20:max:R 40:random:G'. Overrides doubleSpendDelay")
+	conflictColorCountPtr :=
+		flag.Int("conflictColorCount", config.ConflictColorCount, "Number of conflicting colors a double spend can split the network into")
+	conflictSetCountPtr :=
+		flag.Int("conflictSetCount", config.ConflictSetCount, "Number of independent conflict sets that can be live simultaneously")
+	ledgerEnabledPtr :=
+		flag.Bool("ledgerEnabled", config.LedgerEnabled, "Messages carry UTXO transactions and conflicts arise from actual double spends instead of injected colors")
 	relevantValidatorWeightPtr :=
 		flag.Int("releventValidatorWeight", config.RelevantValidatorWeight, "The node whose weight * RelevantValidatorWeight <= largestWeight will not issue messages")
+	rateSetterEnabledPtr :=
+		flag.Bool("rateSetterEnabled", config.RateSetterEnabled, "Honest nodes adapt their issuance rate to the observed congestion with an AIMD rule")
+	rateSetterIntervalPtr :=
+		flag.Int("rateSetterInterval", config.RateSetterInterval, "The interval between two AIMD adjustments of the rate setter in seconds")
+	rateSetterIncreasePtr :=
+		flag.Float64("rateSetterIncrease", config.RateSetterIncrease, "The additive increase of the rate factor per interval without congestion")
+	rateSetterDecreasePtr :=
+		flag.Float64("rateSetterDecrease", config.RateSetterDecrease, "The multiplicative decrease of the rate factor on congestion")
+	rateSetterCongestionThresholdPtr :=
+		flag.Int("rateSetterCongestionThreshold", config.RateSetterCongestionThreshold, "The tip pool size above which a node considers the network congested")
+	rateSetterMinPtr :=
+		flag.Float64("rateSetterMin", config.RateSetterMin, "The lower bound of the rate factor")
+	adaptivePowEnabledPtr :=
+		flag.Bool("adaptivePowEnabled", config.AdaptivePowEnabled, "Per-node issuance cost scales with the recent issuance rate relative to the mana-fair rate")
+	adaptivePowIntervalPtr :=
+		flag.Int("adaptivePowInterval", config.AdaptivePowInterval, "The length of the window over which the recent issuance rate is measured in seconds")
+	adaptivePowGammaPtr :=
+		flag.Float64("adaptivePowGamma", config.AdaptivePowGamma, "The difficulty units added per multiple of the mana-fair rate a node issues above it")
+	adaptivePowMinPtr :=
+		flag.Float64("adaptivePowMin", config.AdaptivePowMin, "The lower bound of the adaptive PoW rate factor")
+	requesterBaseIntervalPtr :=
+		flag.Int("requesterBaseInterval", config.RequesterBaseInterval, "The wait in seconds before the first retry of a missing-message request, doubling with every unanswered retry")
+	requesterTimeoutPtr :=
+		flag.Int("requesterTimeout", config.RequesterTimeout, "The time in seconds after which a missing-message request is given up")
+	schedulerEnabledPtr :=
+		flag.Bool("schedulerEnabled", config.SchedulerEnabled, "A deficit-round-robin scheduler with mana-proportional quanta sits between booking and gossip")
+	schedulerRatePtr :=
+		flag.Int("schedulerRate", config.SchedulerRate, "The number of messages every node's scheduler releases per second")
+	schedulerIntervalPtr :=
+		flag.Int("schedulerInterval", config.SchedulerInterval, "The interval between two drain rounds of the scheduler in milliseconds")
+	pruningEnabledPtr :=
+		flag.Bool("pruningEnabled", config.PruningEnabled, "Evict messages confirmed more than pruningAge ago from the in-memory tangles")
+	pruningAgePtr :=
+		flag.Int("pruningAge", config.PruningAge, "The age since confirmation above which a message is evicted in seconds")
+	pruningIntervalPtr :=
+		flag.Int("pruningInterval", config.PruningInterval, "The interval between two pruning passes in seconds")
+	epochCommitmentsPtr :=
+		flag.Bool("epochCommitments", config.EpochCommitments, "Nodes compute one commitment per epoch over their confirmed messages and the divergence across nodes is dumped to ep-*.csv")
+	epochDurationPtr :=
+		flag.Int("epochDuration", config.EpochDuration, "The duration of one epoch in seconds")
+	dynamicWeightsEnabledPtr :=
+		flag.Bool("dynamicWeightsEnabled", config.DynamicWeightsEnabled, "Node weights decay and are re-earned through confirmed messages every epoch, with consensus reading the weights sealed at the last epoch boundary")
+	manaDecayPerEpochPtr :=
+		flag.Float64("manaDecayPerEpoch", config.ManaDecayPerEpoch, "The fraction of every node's weight that decays per epoch and is redistributed along the confirmed messages")
+	coordinatorEnabledPtr :=
+		flag.Bool("coordinatorEnabled", config.CoordinatorEnabled, "The largest-weight node acts as coordinator and confirmation is milestone based instead of approval weight based")
+	fpcVotingEnabledPtr :=
+		flag.Bool("fpcVotingEnabled", config.FPCVotingEnabled, "Conflicting colors are resolved by FPC-style query rounds instead of approval weight")
+	fpcRoundIntervalPtr :=
+		flag.Int("fpcRoundInterval", config.FPCRoundInterval, "The interval between two FPC voting rounds in seconds")
+	fpcQuerySampleSizePtr :=
+		flag.Int("fpcQuerySampleSize", config.FPCQuerySampleSize, "The number of random nodes every node queries per round")
+	fpcThresholdPtr :=
+		flag.Float64("fpcThreshold", config.FPCThreshold, "The lower bound of the per-round adoption threshold")
+	fpcThresholdUpperPtr :=
+		flag.Float64("fpcThresholdUpper", config.FPCThresholdUpper, "The upper bound of the per-round adoption threshold")
+	fpcFinalizationRoundsPtr :=
+		flag.Int("fpcFinalizationRounds", config.FPCFinalizationRounds, "The number of consecutive unchanged rounds after which a node finalizes its opinion")
+	milestoneIntervalPtr :=
+		flag.Int("milestoneInterval", config.MilestoneInterval, "The interval between two milestones of the coordinator in seconds")
 	packetLoss :=
 		flag.Float64("packetLoss", config.PacketLoss, "The packet loss percentage")
 	minDelay :=
 		flag.Int("minDelay", config.MinDelay, "The minimum network delay in ms")
 	maxDelay :=
 		flag.Int("maxDelay", config.MaxDelay, "The maximum network delay in ms")
+	asymmetricDelaysPtr :=
+		flag.Bool("asymmetricDelays", config.AsymmetricDelays, "Give each direction of a link its own fixed delay drawn from the min/maxDelay range")
+	delayDistributionPtr :=
+		flag.String("delayDistribution", config.DelayDistribution, "Distribution the link delays are drawn from: uniform, lognormal:mu:sigma, pareto:scaleMs:alpha or bimodal:p:lowMs:highMs")
+	natFractionPtr :=
+		flag.Float64("natFraction", config.NatFraction, "Fraction of the nodes behind a NAT blocking inbound dial-in")
+	lossDistributionPtr :=
+		flag.String("lossDistribution", config.LossDistribution, "Distribution the per-link loss rates are drawn from: uniform, exponential:mean or bimodal:p:low:high")
+	burstLossPtr :=
+		flag.Bool("burstLoss", config.BurstLoss, "Correlate the losses of every link with the Gilbert-Elliott two-state chain instead of dropping messages independently")
+	burstLossPGoodToBadPtr :=
+		flag.Float64("burstLossPGoodToBad", config.BurstLossPGoodToBad, "Per-message probability of a link entering the lossy burst state")
+	burstLossPBadToGoodPtr :=
+		flag.Float64("burstLossPBadToGood", config.BurstLossPBadToGood, "Per-message probability of a link leaving the lossy burst state")
+	burstLossBadPtr :=
+		flag.Float64("burstLossBad", config.BurstLossBad, "Loss rate of a link in the burst state")
+	jitterMsPtr :=
+		flag.Float64("jitterMs", config.JitterMs, "Standard deviation in ms of the zero-mean normal jitter added to every delivery, resampled per message")
+	bandwidthMsgPerSecPtr :=
+		flag.Int("bandwidthMsgPerSec", config.BandwidthMsgPerSec, "Outbound capacity of every connection in messages per second, 0 models unlimited bandwidth")
+	gossipModePtr :=
+		flag.String("gossipMode", config.GossipMode, "Gossip protocol: flood, fanout or pull")
+	gossipFanoutPtr :=
+		flag.Int("gossipFanout", config.GossipFanout, "Number of random neighbors a booked message is pushed to in the fanout and pull gossip modes")
+	gossipPullIntervalPtr :=
+		flag.Int("gossipPullInterval", config.GossipPullInterval, "Interval in ms between the IHAVE announcements of the pull gossip mode")
+	peerScoringIntervalPtr :=
+		flag.Int("peerScoringInterval", config.PeerScoringInterval, "Interval in simulated seconds between neighbor score evaluations dropping misbehaving neighbors, 0 disables peer scoring")
+	peerScoringMaxInvalidRatioPtr :=
+		flag.Float64("peerScoringMaxInvalidRatio", config.PeerScoringMaxInvalidRatio, "Share of duplicate or conflicting deliveries above which a neighbor is dropped")
+	peerScoringMinDeliveriesPtr :=
+		flag.Int("peerScoringMinDeliveries", config.PeerScoringMinDeliveries, "Number of deliveries after which a neighbor's score is judged")
+	detectionMetricsPtr :=
+		flag.Bool("detectionMetrics", config.DetectionMetrics, "Track per-node detection counters and dump them per node to dt-*.csv")
+	detectionOverRateTolerancePtr :=
+		flag.Float64("detectionOverRateTolerance", config.DetectionOverRateTolerance, "Factor above an issuer's mana-allowed message rate from which its messages count as over-rate")
 	deltaURTS :=
 		flag.Float64("deltaURTS", config.DeltaURTS, "in seconds, reference: https://iota.cafe/t/orphanage-with-restricted-urts/1199")
 	simulationStopThreshold :=
@@ -61,10 +237,58 @@ func ParseFlags() {
 		flag.Float64("WattsStrogatzRandomness", config.RandomnessWS, "WattsStrogatz randomness parameter")
 	neighbourCountWS :=
 		flag.Int("WattsStrogatzNeighborCount", config.NeighbourCountWS, "Number of neighbors node is connected to in WattsStrogatz network topology")
+	topologyPtr :=
+		flag.String("topology", config.Topology, "Network topology: WS (WattsStrogatz), ER (ErdosRenyi), BA (BarabasiAlbert) or CM (configuration model from -degreeSequence)")
+	degreeSequencePtr :=
+		flag.String("degreeSequence", "", "Target degree of every node for the CM topology, e.g. '8 8 4 4', one entry per node with an even sum")
+	clusterCountPtr :=
+		flag.Int("clusterCount", config.ClusterCount, "Number of equally sized regions of the MR topology")
+	clusterSizesPtr :=
+		flag.String("clusterSizes", "", "Explicit region sizes for the MR topology, e.g. '40 30 30'. Must sum to -nodesCount, overrides -clusterCount")
+	clusterIntraNeighborsPtr :=
+		flag.Int("clusterIntraNeighbors", config.ClusterIntraNeighbors, "Ring neighbors of every node inside its region in the MR topology")
+	clusterInterLinksPtr :=
+		flag.Int("clusterInterLinks", config.ClusterInterLinks, "Random links between every pair of regions in the MR topology")
+	clusterIntraDelayPtr :=
+		flag.Int("clusterIntraDelay", config.ClusterIntraDelayMs, "Fixed delay in ms of the links inside a region in the MR topology")
+	clusterInterDelayPtr :=
+		flag.Int("clusterInterDelay", config.ClusterInterDelayMs, "Fixed delay in ms of the links between regions in the MR topology")
+	clusterInterPacketLossPtr :=
+		flag.Float64("clusterInterPacketLoss", config.ClusterInterPacketLoss, "Packet loss of the links between regions in the MR topology")
+	erdosRenyiPPtr :=
+		flag.Float64("erdosRenyiP", config.ErdosRenyiP, "Edge probability of the ErdosRenyi topology")
+	barabasiAlbertMPtr :=
+		flag.Int("barabasiAlbertM", config.BarabasiAlbertM, "Number of edges each new node attaches with in the BarabasiAlbert topology")
+	topologyFilePtr :=
+		flag.String("topologyFile", config.TopologyFile, "Path of an explicit topology file (CSV 'source,target[,delayMs[,packetLoss]]' or GraphML), overrides -topology")
+	geoLatencyPtr :=
+		flag.Bool("geoLatency", config.GeoLatency, "Derive link delays from great-circle distance between regional peer positions instead of the min/maxDelay range")
+	geoBaseLatencyPtr :=
+		flag.Int("geoBaseLatency", config.GeoBaseLatencyMs, "Regional base latency in ms added on top of the propagation time in the geographic latency model")
+	latencyMatrixFilePtr :=
+		flag.String("latencyMatrixFile", config.LatencyMatrixFile, "Path of a CSV inter-city RTT matrix (ms) driving the link delays, overrides -geoLatency and the delay range")
+	latencyMatrixAssignmentPtr :=
+		flag.String("latencyMatrixAssignment", config.LatencyMatrixAssignment, "How nodes are assigned to the matrix locations: uniform (round-robin) or random")
+	rewireIntervalPtr :=
+		flag.Int("rewireInterval", config.RewireInterval, "Interval in simulated seconds between topology rewiring rounds, 0 disables neighbor churn")
+	rewireFractionPtr :=
+		flag.Float64("rewireFraction", config.RewireFraction, "Fraction of the connections dropped and re-established per rewiring round")
+	partitionStartPtr :=
+		flag.Int("partitionStart", config.PartitionStart, "Simulated second at which the network is split into -partitionCount components, 0 disables partitioning")
+	partitionDurationPtr :=
+		flag.Int("partitionDuration", config.PartitionDuration, "Duration of the partition in simulated seconds")
+	partitionCountPtr :=
+		flag.Int("partitionCount", config.PartitionCount, "Number of components the network is split into")
+	churnIntervalPtr :=
+		flag.Int("churnInterval", config.ChurnInterval, "Interval in simulated seconds between node churn rounds taking honest nodes offline, 0 disables node churn")
+	churnFractionPtr :=
+		flag.Float64("churnFraction", config.ChurnFraction, "Fraction of the nodes taken offline per churn round")
+	churnDowntimePtr :=
+		flag.Int("churnDowntime", config.ChurnDowntime, "Downtime in simulated seconds after which a churned node comes back online")
 	adversaryDelays :=
 		flag.String("adversaryDelays", "", "Delays in ms of adversary nodes, eg '50 100 200'")
 	adversaryTypes :=
-		flag.String("adversaryType", "", "Defines group attack strategy, one of the following: 0 - honest node behavior, 1 - shifts opinion, 2 - keeps the same opinion. SimulationTarget must be 'DS'")
+		flag.String("adversaryType", "", "Defines group attack strategy, one of the following: 0 - honest node behavior, 1 - shifts opinion, 2 - keeps the same opinion, 3 - no gossiping, 4 - balances conflicting colors, 5 - censors issuers, 6 - withholds own messages, 7 - oldest-tip orphanage, 8 - nothing-at-stake voting for every branch. SimulationTarget must be 'DS'")
 	adversaryNodeCounts :=
 		flag.String("adversaryNodeCounts", "", "Defines number of adversary nodes in the group. Leave empty for default value: 1. SimulationTarget must be 'DS'")
 	adversaryInitColors :=
@@ -79,27 +303,195 @@ func ParseFlags() {
 		flag.String("adversarySpeedup", "", "Adversary issuing speed relative to their mana, e.g. '10 10' means that nodes in each group will issue 10 times messages than would be allowed by their mana. SimulationTarget must be 'DS'")
 	adversaryPeeringAll :=
 		flag.Bool("adversaryPeeringAll", config.AdversaryPeeringAll, "Flag indicating whether adversary nodes should be able to gossip messages to all nodes in the network directly, or should follow the peering algorithm.")
+	adversaryPeeringTopKPtr :=
+		flag.Int("adversaryPeeringTopK", config.AdversaryPeeringTopK, "Peer every adversary node with the top-k mana holders on top of its regular peering, 0 disables it")
+	adversaryPeeringNodesPtr :=
+		flag.String("adversaryPeeringNodes", "", "Explicit list of nodes every adversary node is peered with, e.g. '0 3 7'. Overrides -adversaryPeeringTopK")
+	adversaryInternalDelayPtr :=
+		flag.Int("adversaryInternalDelay", config.AdversaryInternalDelay, "Delay in ms on the private links among the members of each adversary group, created on top of the regular peering, -1 disables it")
+	adversaryAdaptIntervalPtr :=
+		flag.Int("adversaryAdaptInterval", config.AdversaryAdaptInterval, "Interval in ms between the adaptation ticks handed to adversary nodes implementing the adaptive hook, 0 disables it")
+	adversaryOmniscientPtr :=
+		flag.Bool("adversaryOmniscient", config.AdversaryOmniscient, "Hand the adversary decision logic the freshest tangle state seen anywhere in the network instead of only the node's own")
+	adversaryAbortConditionsPtr :=
+		flag.String("adversaryAbortConditions", "", "Per-group conditions calling the attack off, e.g. 'weight:50 time:60': 'weight:p' aborts once the group's color holds p% of the total weight, 'time:s' after s seconds, '-' keeps the group attacking")
+	adversaryDropColorPtr :=
+		flag.String("adversaryDropColor", config.AdversaryDropColor, "Color whose messages the no-gossip adversary drops while forwarding the rest, one of following: 'R', 'G', 'B'. Empty keeps the strategy dropping everything")
+	adversaryDropIssuersPtr :=
+		flag.String("adversaryDropIssuers", "", "Issuers whose messages the no-gossip adversary drops while forwarding the rest, e.g. '0 3 7'")
+	adversaryDropRatePtr :=
+		flag.Float64("adversaryDropRate", config.AdversaryDropRate, "Probability with which the no-gossip adversary drops a selected message; with no color and no issuers selected it applies to the whole traffic")
+	adversaryCensoredIssuersPtr :=
+		flag.String("adversaryCensoredIssuers", "", "Nodes whose messages the censorship adversary never picks as parents or forwards, e.g. '0 3 7'")
+	adversaryVictimNodesPtr :=
+		flag.String("adversaryVictimNodes", "", "Victim peers the targeting adversary directs its own messages at, e.g. '0 3 7'")
+	adversaryVictimBiasPtr :=
+		flag.Float64("adversaryVictimBias", config.AdversaryVictimBias, "Probability that the targeting adversary skips a non-victim neighbor when gossiping its own messages, 1 targets the victims exclusively")
+	sybilCountPtr :=
+		flag.Int("sybilCount", config.SybilCount, "Number of additional adversary identities injected mid-run, 0 disables the injection")
+	sybilStartPtr :=
+		flag.Int("sybilStart", config.SybilStart, "Seconds into the run at which the sybil identities come online")
+	sybilManaPtr :=
+		flag.Float64("sybilMana", config.SybilMana, "Mana in % of the total weight granted to the sybil group at injection")
+	sybilTypePtr :=
+		flag.Int("sybilType", config.SybilType, "Attack strategy of the sybil identities, same encoding as -adversaryType")
+	sybilInitColorPtr :=
+		flag.String("sybilInitColor", config.SybilInitColor, "Color the sybil identities support in the ongoing conflict")
+	adversaryWithholdDelayPtr :=
+		flag.Int("adversaryWithholdDelay", config.AdversaryWithholdDelay, "Seconds after which the withholding adversary releases its private side tangle, 0 never releases it")
+	adversarySpamIntervalPtr :=
+		flag.Int("adversarySpamInterval", config.AdversarySpamInterval, "Interval in seconds between the spam bursts of the adversary nodes, 0 disables bursting")
+	adversarySpamBurstPtr :=
+		flag.Int("adversarySpamBurst", config.AdversarySpamBurst, "Number of messages every adversary node issues per spam burst")
+	adversaryControlAddress :=
+		flag.String("adversaryControlAddress", config.AdversaryControlAddress, "TCP address (host:port) on which the external adversary controller interface listens, empty to disable")
+	eclipseVictimPtr :=
+		flag.Int("eclipseVictim", config.EclipseVictim, "Node whose connections are handed to the adversary for an eclipse attack, -1 disables it")
+	eclipseConnectionsPtr :=
+		flag.Int("eclipseConnections", config.EclipseConnections, "Number of the victim's honest connections replaced with adversary connections, 0 eclipses the victim completely")
+	adversaryManaGrowthRate :=
+		flag.String("adversaryManaGrowthRate", "", "Mana acquired by each adversary group per simulated minute, in % of the total weight, e.g. '1 0.5'. SimulationTarget must be 'DS'")
+	adversaryManaSchedule :=
+		flag.String("adversaryManaSchedule", "", "Checkpoints of every adversary group's mana share as 'second:percent' entries, e.g. '0:5 120:20', linearly interpolated in between. SimulationTarget must be 'DS'")
+	adversaryActivationTimes :=
+		flag.String("adversaryActivationTimes", "", "Seconds into the run at which each adversary group becomes active, e.g. '0 30'. Inactive groups stay completely silent. Leave empty to activate all groups at the start")
+	adversaryCompositions :=
+		flag.String("adversaryCompositions", "", "Extra behaviors layered on top of each group's -adversaryType entry as '+'-joined strategy codes, e.g. '3+7 0'. '0' layers nothing")
+	adversaryStrategyFile :=
+		flag.String("adversaryStrategyFile", config.AdversaryStrategyFile, "Path to a YAML file with declarative rules for the scripted adversary, pairing conditions on the observed approval weights with issue/assign/silence/resume actions")
+	adversaryTimestampSkewPtr :=
+		flag.Int("adversaryTimestampSkew", config.AdversaryTimestampSkew, "Skew in ms the timestamp forger adds to the issuance time of its own messages, negative values backdate them")
+	adversaryParasiteIntervalPtr :=
+		flag.Int("adversaryParasiteInterval", config.AdversaryParasiteInterval, "Seconds between the attachments of the parasitic chain to the main tangle, 0 never attaches it")
+	adversaryReplayIntervalPtr :=
+		flag.Int("adversaryReplayInterval", config.AdversaryReplayInterval, "Seconds between the replay rounds of the replay adversary, 0 disables replaying")
+	adversaryReplayBatchPtr :=
+		flag.Int("adversaryReplayBatch", config.AdversaryReplayBatch, "Number of stale messages every replay node re-gossips and re-requests per replay round")
+	adversaryBudgetPtr :=
+		flag.Float64("adversaryBudget", config.AdversaryBudget, "Total action budget of the adversary, 0 disables the cost model")
+	adversaryCostMessagePtr :=
+		flag.Float64("adversaryCostMessage", config.AdversaryCostMessage, "Budget cost of each extra message the adversary issues")
+	adversaryCostReleasePtr :=
+		flag.Float64("adversaryCostRelease", config.AdversaryCostRelease, "Budget cost of each withheld message released late")
+	adversaryCostConnectionPtr :=
+		flag.Float64("adversaryCostConnection", config.AdversaryCostConnection, "Budget cost of each connection the adversary peering options add on top of the topology")
+	timestampWindowPtr :=
+		flag.Float64("timestampWindow", config.TimestampWindow, "Window in seconds around the local clock within which nodes accept the issuance time of a received message, 0 disables the validation")
 
 	// Parse the flags
 	flag.Parse()
 
 	// Update the configuration parameters
+	config.Runs = *runsPtr
+	config.Seed = *seedPtr
+	config.Sweep = *sweepPtr
+	config.DiscreteEvent = *discreteEventPtr
+	config.RecordTrace = *recordTracePtr
+	config.ReplayTrace = *replayTracePtr
+	config.SnapshotExportFile = *snapshotExportFilePtr
+	config.SnapshotImportFile = *snapshotImportFilePtr
+	if *genesisMessagesPtr != "" {
+		config.GenesisMessages = parseStr(*genesisMessagesPtr)
+	}
+	config.MetricsWarmup = *metricsWarmupPtr
+	config.StopConditions = *stopConditionsPtr
+	config.WallClockLimit = *wallClockLimitPtr
+	config.MetastabilityFlips = *metastabilityFlipsPtr
+	config.MaxIssuedMessages = *maxIssuedMessagesPtr
+	config.Scenario = *scenarioPtr
+	config.CalibrateSlowdown = *calibrateSlowdownPtr
+	config.ProgressJSON = *progressJSONPtr
+	config.Validate = *validatePtr
+	config.ListStrategies = *listStrategiesPtr
+	config.MaxMemoryMB = *maxMemoryMBPtr
 	config.NodesCount = *nodesCountPtr
 	config.NodesTotalWeight = *nodesTotalWeightPtr
 	config.ZipfParameter = *zipfParameterPtr
 	config.ConfirmationThreshold = *confirmationThresholdPtr
 	config.ConfirmationThresholdAbsolute = *confirmationThresholdAbsolutePtr
+	config.ConfirmationRule = *confirmationRulePtr
+	config.ConfirmationWWFraction = *confirmationWWFractionPtr
+	config.ConfirmationAbsoluteWeight = *confirmationAbsoluteWeightPtr
+	config.ConfirmationMinAge = *confirmationMinAgePtr
+	config.MarkerBasedAW = *markerBasedAWPtr
+	config.MarkerSpacing = *markerSpacingPtr
+	if *confirmationGradesPtr != "" {
+		config.ConfirmationGrades = parseStrToFloat64(*confirmationGradesPtr)
+	}
 	config.ParentsCount = *parentsCountPtr
 	config.WeakTipsRatio = *weakTipsRatioPtr
+	config.LikeReferencesEnabled = *likeReferencesEnabledPtr
 	config.TSA = *tsaPtr
+	config.ParentsCountPolicy = *parentsCountPolicyPtr
+	config.AdaptiveParentsTipsPerParent = *adaptiveParentsTipsPerParentPtr
+	config.PastConeCheckEnabled = *pastConeCheckEnabledPtr
+	config.OrphanageReporting = *orphanageReportingPtr
+	config.OrphanageHorizon = *orphanageHorizonPtr
+	config.ReattachmentEnabled = *reattachmentEnabledPtr
+	config.ReattachmentDeadline = *reattachmentDeadlinePtr
+	config.ReattachmentInterval = *reattachmentIntervalPtr
 	config.TPS = *tpsPtr
 	config.SlowdownFactor = *slowdownFactorPtr
 	config.ConsensusMonitorTick = *consensusMonitorTickPtr
 	config.RelevantValidatorWeight = *relevantValidatorWeightPtr
+	config.RateSetterEnabled = *rateSetterEnabledPtr
+	config.RateSetterInterval = *rateSetterIntervalPtr
+	config.RateSetterIncrease = *rateSetterIncreasePtr
+	config.RateSetterDecrease = *rateSetterDecreasePtr
+	config.RateSetterCongestionThreshold = *rateSetterCongestionThresholdPtr
+	config.RateSetterMin = *rateSetterMinPtr
+	config.AdaptivePowEnabled = *adaptivePowEnabledPtr
+	config.AdaptivePowInterval = *adaptivePowIntervalPtr
+	config.AdaptivePowGamma = *adaptivePowGammaPtr
+	config.AdaptivePowMin = *adaptivePowMinPtr
+	config.RequesterBaseInterval = *requesterBaseIntervalPtr
+	config.RequesterTimeout = *requesterTimeoutPtr
+	config.SchedulerEnabled = *schedulerEnabledPtr
+	config.SchedulerRate = *schedulerRatePtr
+	config.SchedulerInterval = *schedulerIntervalPtr
+	config.PruningEnabled = *pruningEnabledPtr
+	config.PruningAge = *pruningAgePtr
+	config.PruningInterval = *pruningIntervalPtr
+	config.EpochCommitments = *epochCommitmentsPtr
+	config.EpochDuration = *epochDurationPtr
+	config.DynamicWeightsEnabled = *dynamicWeightsEnabledPtr
+	config.ManaDecayPerEpoch = *manaDecayPerEpochPtr
+	config.CoordinatorEnabled = *coordinatorEnabledPtr
+	config.FPCVotingEnabled = *fpcVotingEnabledPtr
+	config.FPCRoundInterval = *fpcRoundIntervalPtr
+	config.FPCQuerySampleSize = *fpcQuerySampleSizePtr
+	config.FPCThreshold = *fpcThresholdPtr
+	config.FPCThresholdUpper = *fpcThresholdUpperPtr
+	config.FPCFinalizationRounds = *fpcFinalizationRoundsPtr
+	config.MilestoneInterval = *milestoneIntervalPtr
 	config.DoubleSpendDelay = *doubleSpendDelayPtr
+	if *doubleSpendSchedulePtr != "" {
+		config.DoubleSpendSchedule = parseStr(*doubleSpendSchedulePtr)
+	}
+	config.ConflictColorCount = *conflictColorCountPtr
+	config.ConflictSetCount = *conflictSetCountPtr
+	config.LedgerEnabled = *ledgerEnabledPtr
 	config.PacketLoss = *packetLoss
 	config.MinDelay = *minDelay
 	config.MaxDelay = *maxDelay
+	config.AsymmetricDelays = *asymmetricDelaysPtr
+	config.DelayDistribution = *delayDistributionPtr
+	config.NatFraction = *natFractionPtr
+	config.LossDistribution = *lossDistributionPtr
+	config.BurstLoss = *burstLossPtr
+	config.BurstLossPGoodToBad = *burstLossPGoodToBadPtr
+	config.BurstLossPBadToGood = *burstLossPBadToGoodPtr
+	config.BurstLossBad = *burstLossBadPtr
+	config.JitterMs = *jitterMsPtr
+	config.BandwidthMsgPerSec = *bandwidthMsgPerSecPtr
+	config.GossipMode = *gossipModePtr
+	config.GossipFanout = *gossipFanoutPtr
+	config.GossipPullInterval = *gossipPullIntervalPtr
+	config.PeerScoringInterval = *peerScoringIntervalPtr
+	config.PeerScoringMaxInvalidRatio = *peerScoringMaxInvalidRatioPtr
+	config.PeerScoringMinDeliveries = *peerScoringMinDeliveriesPtr
+	config.DetectionMetrics = *detectionMetricsPtr
+	config.DetectionOverRateTolerance = *detectionOverRateTolerancePtr
 	config.DeltaURTS = *deltaURTS
 	config.SimulationStopThreshold = *simulationStopThreshold
 	config.SimulationTarget = *simulationTarget
@@ -107,10 +499,87 @@ func ParseFlags() {
 	config.IMIF = *imif
 	config.RandomnessWS = *randomnessWS
 	config.NeighbourCountWS = *neighbourCountWS
+	config.Topology = *topologyPtr
+	if *degreeSequencePtr != "" {
+		config.DegreeSequence = parseStrToInt(*degreeSequencePtr)
+	}
+	config.ClusterCount = *clusterCountPtr
+	if *clusterSizesPtr != "" {
+		config.ClusterSizes = parseStrToInt(*clusterSizesPtr)
+	}
+	config.ClusterIntraNeighbors = *clusterIntraNeighborsPtr
+	config.ClusterInterLinks = *clusterInterLinksPtr
+	config.ClusterIntraDelayMs = *clusterIntraDelayPtr
+	config.ClusterInterDelayMs = *clusterInterDelayPtr
+	config.ClusterInterPacketLoss = *clusterInterPacketLossPtr
+	config.ErdosRenyiP = *erdosRenyiPPtr
+	config.BarabasiAlbertM = *barabasiAlbertMPtr
+	config.TopologyFile = *topologyFilePtr
+	config.GeoLatency = *geoLatencyPtr
+	config.GeoBaseLatencyMs = *geoBaseLatencyPtr
+	config.LatencyMatrixFile = *latencyMatrixFilePtr
+	config.LatencyMatrixAssignment = *latencyMatrixAssignmentPtr
+	config.RewireInterval = *rewireIntervalPtr
+	config.RewireFraction = *rewireFractionPtr
+	config.PartitionStart = *partitionStartPtr
+	config.PartitionDuration = *partitionDurationPtr
+	config.PartitionCount = *partitionCountPtr
+	config.ChurnInterval = *churnIntervalPtr
+	config.ChurnFraction = *churnFractionPtr
+	config.ChurnDowntime = *churnDowntimePtr
 	config.SimulationMode = *simulationMode
+	config.AdversaryPeeringTopK = *adversaryPeeringTopKPtr
+	if *adversaryPeeringNodesPtr != "" {
+		config.AdversaryPeeringNodes = parseStrToInt(*adversaryPeeringNodesPtr)
+	}
+	config.AdversaryInternalDelay = *adversaryInternalDelayPtr
+	config.AdversaryAdaptInterval = *adversaryAdaptIntervalPtr
+	config.AdversaryOmniscient = *adversaryOmniscientPtr
+	if *adversaryAbortConditionsPtr != "" {
+		config.AdversaryAbortConditions = parseStr(*adversaryAbortConditionsPtr)
+	}
+	config.AdversaryDropColor = *adversaryDropColorPtr
+	if *adversaryDropIssuersPtr != "" {
+		config.AdversaryDropIssuers = parseStrToInt(*adversaryDropIssuersPtr)
+	}
+	config.AdversaryDropRate = *adversaryDropRatePtr
+	if *adversaryCensoredIssuersPtr != "" {
+		config.AdversaryCensoredIssuers = parseStrToInt(*adversaryCensoredIssuersPtr)
+	}
+	if *adversaryVictimNodesPtr != "" {
+		config.AdversaryVictimNodes = parseStrToInt(*adversaryVictimNodesPtr)
+	}
+	config.AdversaryVictimBias = *adversaryVictimBiasPtr
+	config.SybilCount = *sybilCountPtr
+	config.SybilStart = *sybilStartPtr
+	config.SybilMana = *sybilManaPtr
+	config.SybilType = *sybilTypePtr
+	config.SybilInitColor = *sybilInitColorPtr
+	config.AdversaryWithholdDelay = *adversaryWithholdDelayPtr
+	config.AdversaryStrategyFile = *adversaryStrategyFile
+	config.AdversaryTimestampSkew = *adversaryTimestampSkewPtr
+	config.AdversaryParasiteInterval = *adversaryParasiteIntervalPtr
+	config.AdversaryReplayInterval = *adversaryReplayIntervalPtr
+	config.AdversaryReplayBatch = *adversaryReplayBatchPtr
+	config.AdversaryBudget = *adversaryBudgetPtr
+	config.AdversaryCostMessage = *adversaryCostMessagePtr
+	config.AdversaryCostRelease = *adversaryCostReleasePtr
+	config.AdversaryCostConnection = *adversaryCostConnectionPtr
+	config.TimestampWindow = *timestampWindowPtr
+	config.AdversarySpamInterval = *adversarySpamIntervalPtr
+	config.AdversarySpamBurst = *adversarySpamBurstPtr
+	config.AdversaryControlAddress = *adversaryControlAddress
+	config.EclipseVictim = *eclipseVictimPtr
+	config.EclipseConnections = *eclipseConnectionsPtr
 	parseAccidentalConfig(accidentalMana)
-	parseAdversaryConfig(adversaryDelays, adversaryTypes, adversaryMana, adversaryNodeCounts, adversaryInitColors, adversaryPeeringAll, adversarySpeedup)
+	parseAdversaryConfig(adversaryDelays, adversaryTypes, adversaryMana, adversaryNodeCounts, adversaryInitColors, adversaryPeeringAll, adversarySpeedup, adversaryManaGrowthRate, adversaryActivationTimes, adversaryCompositions, adversaryManaSchedule)
 	log.Info("Current configuration:")
+	log.Info("DiscreteEvent: ", config.DiscreteEvent)
+	log.Info("RecordTrace: ", config.RecordTrace)
+	log.Info("ReplayTrace: ", config.ReplayTrace)
+	log.Info("MetricsWarmup: ", config.MetricsWarmup)
+	log.Info("StopConditions: ", config.StopConditions)
+	log.Info("Scenario: ", config.Scenario)
 	log.Info("NodesCount: ", config.NodesCount)
 	log.Info("NodesTotalWeight: ", config.NodesTotalWeight)
 	log.Info("ZipfParameter: ", config.ZipfParameter)
@@ -143,10 +612,15 @@ func ParseFlags() {
 	log.Info("AccidentalMana: ", config.AccidentalMana)
 	log.Info("AdversaryPeeringAll: ", config.AdversaryPeeringAll)
 	log.Info("AdversarySpeedup: ", config.AdversarySpeedup)
+	log.Info("AdversaryControlAddress: ", config.AdversaryControlAddress)
+	log.Info("AdversaryManaGrowthRate: ", config.AdversaryManaGrowthRate)
+	log.Info("AdversaryActivationTimes: ", config.AdversaryActivationTimes)
+	log.Info("AdversaryCompositions: ", config.AdversaryCompositions)
+	log.Info("AdversaryManaSchedule: ", config.AdversaryManaSchedule)
 
 }
 
-func parseAdversaryConfig(adversaryDelays, adversaryTypes, adversaryMana, adversaryNodeCounts, adversaryInitColors *string, adversaryPeeringAll *bool, adversarySpeedup *string) {
+func parseAdversaryConfig(adversaryDelays, adversaryTypes, adversaryMana, adversaryNodeCounts, adversaryInitColors *string, adversaryPeeringAll *bool, adversarySpeedup, adversaryManaGrowthRate, adversaryActivationTimes, adversaryCompositions, adversaryManaSchedule *string) {
 	if config.SimulationMode != "Adversary" {
 		config.AdversaryTypes = []int{}
 		config.AdversaryNodeCounts = []int{}
@@ -154,6 +628,10 @@ func parseAdversaryConfig(adversaryDelays, adversaryTypes, adversaryMana, advers
 		config.AdversaryDelays = []int{}
 		config.AdversaryInitColors = []string{}
 		config.AdversarySpeedup = []float64{}
+		config.AdversaryManaGrowthRate = []float64{}
+		config.AdversaryActivationTimes = []int{}
+		config.AdversaryCompositions = []string{}
+		config.AdversaryManaSchedule = []string{}
 
 		return
 	}
@@ -178,6 +656,18 @@ func parseAdversaryConfig(adversaryDelays, adversaryTypes, adversaryMana, advers
 	if *adversarySpeedup != "" {
 		config.AdversarySpeedup = parseStrToFloat64(*adversarySpeedup)
 	}
+	if *adversaryManaGrowthRate != "" {
+		config.AdversaryManaGrowthRate = parseStrToFloat64(*adversaryManaGrowthRate)
+	}
+	if *adversaryActivationTimes != "" {
+		config.AdversaryActivationTimes = parseStrToInt(*adversaryActivationTimes)
+	}
+	if *adversaryCompositions != "" {
+		config.AdversaryCompositions = parseStr(*adversaryCompositions)
+	}
+	if *adversaryManaSchedule != "" {
+		config.AdversaryManaSchedule = parseStr(*adversaryManaSchedule)
+	}
 	// no adversary if colors are not provided
 	if len(config.AdversaryInitColors) != len(config.AdversaryTypes) {
 		config.AdversaryTypes = []int{}
@@ -196,6 +686,18 @@ func parseAdversaryConfig(adversaryDelays, adversaryTypes, adversaryMana, advers
 		log.Warnf("The AdversaryNodeCounts count is not equal to the AdversaryTypes count!")
 		config.AdversaryNodeCounts = []int{}
 	}
+	if len(config.AdversaryManaGrowthRate) != 0 && len(config.AdversaryManaGrowthRate) != len(config.AdversaryTypes) {
+		log.Warnf("The AdversaryManaGrowthRate count is not equal to the AdversaryTypes count!")
+		config.AdversaryManaGrowthRate = []float64{}
+	}
+	if len(config.AdversaryActivationTimes) != 0 && len(config.AdversaryActivationTimes) != len(config.AdversaryTypes) {
+		log.Warnf("The AdversaryActivationTimes count is not equal to the AdversaryTypes count!")
+		config.AdversaryActivationTimes = []int{}
+	}
+	if len(config.AdversaryCompositions) != 0 && len(config.AdversaryCompositions) != len(config.AdversaryTypes) {
+		log.Warnf("The AdversaryCompositions count is not equal to the AdversaryTypes count!")
+		config.AdversaryCompositions = []string{}
+	}
 }
 
 func parseAccidentalConfig(accidentalMana *string) {
@@ -0,0 +1,90 @@
+package simulation
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region Pause control ////////////////////////////////////////////////////////////////////////////////////////////////
+
+// Pausing blocks the issuance workers, the network deliveries and the monitoring tickers at their next
+// checkpoint until the simulation is resumed, so a debugger can be attached or the intermediate state of a
+// large run inspected. Sending SIGUSR1 to the process toggles between paused and running.
+
+var (
+	pauseMutex sync.Mutex
+	pauseCond  = sync.NewCond(&pauseMutex)
+	paused     bool
+)
+
+// Pause halts the simulation at the next checkpoint of every worker.
+func Pause() {
+	pauseMutex.Lock()
+	defer pauseMutex.Unlock()
+
+	if !paused {
+		paused = true
+		log.Info("Pausing simulation (SIGUSR1 resumes it)")
+	}
+}
+
+// Resume lets all paused workers continue.
+func Resume() {
+	pauseMutex.Lock()
+	defer pauseMutex.Unlock()
+
+	if paused {
+		paused = false
+		pauseCond.Broadcast()
+		log.Info("Resuming simulation")
+	}
+}
+
+// TogglePause switches between the paused and the running state.
+func TogglePause() {
+	pauseMutex.Lock()
+	pausedNow := !paused
+	pauseMutex.Unlock()
+
+	if pausedNow {
+		Pause()
+	} else {
+		Resume()
+	}
+}
+
+// waitWhilePaused blocks the calling worker until the simulation is resumed.
+func waitWhilePaused() {
+	pauseMutex.Lock()
+	for paused {
+		pauseCond.Wait()
+	}
+	pauseMutex.Unlock()
+}
+
+// listenForPauseSignals toggles the pause state on every SIGUSR1 until the run context is cancelled.
+func listenForPauseSignals(ctx context.Context) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				signal.Stop(signals)
+				return
+			case <-signals:
+				TogglePause()
+			}
+		}
+	}()
+
+	network.PauseHook = waitWhilePaused
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
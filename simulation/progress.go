@@ -0,0 +1,85 @@
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/iotaledger/multivers-simulation/config"
+)
+
+// region Progress reporting ///////////////////////////////////////////////////////////////////////////////////////////
+
+// progressReport is the machine-readable progress format emitted on stderr when ProgressJSON is enabled,
+// one JSON object per line, so orchestration tools can track large runs without parsing the human logs.
+type progressReport struct {
+	Progress           float64 `json:"progress"`
+	ConfirmedFraction  float64 `json:"confirmedFraction"`
+	IssuedMessages     int64   `json:"issuedMessages"`
+	ExpectedMessages   int64   `json:"expectedMessages"`
+	ElapsedSeconds     float64 `json:"elapsedSeconds"`
+	EtaSeconds         float64 `json:"etaSeconds"`
+	SimulatedTimeRatio float64 `json:"simulatedTimeRatio"`
+}
+
+// reportProgress logs the completion state of the run and an estimate of the remaining simulated time,
+// derived from both the elapsed fraction of the maximum duration and the confirmation progress.
+func reportProgress(honestNodesCount int) {
+	elapsed := sinceStart(simulationStartTime)
+	timeProgress := float64(elapsed) / float64(maxSimulationDuration)
+
+	confirmed := getLikesPerColor(colorCounters, "confirmedNodes")
+	adversaryConfirmed := getLikesPerColor(adversaryCounters, "confirmedNodes")
+	maxHonestConfirmed := int64(0)
+	for i := range confirmed {
+		maxHonestConfirmed = Max(maxHonestConfirmed, confirmed[i]-adversaryConfirmed[i])
+	}
+	confirmedFraction := 0.0
+	if honestNodesCount > 0 {
+		confirmedFraction = float64(maxHonestConfirmed) / float64(honestNodesCount)
+	}
+	confirmationProgress := 0.0
+	if config.SimulationStopThreshold > 0 {
+		confirmationProgress = confirmedFraction / config.SimulationStopThreshold
+	}
+
+	// the run ends at whichever comes first, so overall progress is the faster of the two
+	progress := timeProgress
+	if confirmationProgress > progress {
+		progress = confirmationProgress
+	}
+	if progress > 1 {
+		progress = 1
+	}
+
+	eta := time.Duration(0)
+	if progress > 0 && progress < 1 {
+		eta = time.Duration(float64(elapsed) * (1 - progress) / progress)
+	}
+
+	issued := atomicCounters.Get("issuedMessages")
+	expected := int64(float64(config.TPS) * elapsed.Seconds())
+
+	log.Infof("Progress: %5.1f%% :: %5.1f%% honest nodes confirmed :: %d/%d messages issued :: ETA %s",
+		100*progress, 100*confirmedFraction, issued, expected, eta.Round(time.Second))
+
+	if config.ProgressJSON {
+		bytes, err := json.Marshal(progressReport{
+			Progress:           progress,
+			ConfirmedFraction:  confirmedFraction,
+			IssuedMessages:     issued,
+			ExpectedMessages:   expected,
+			ElapsedSeconds:     elapsed.Seconds(),
+			EtaSeconds:         eta.Seconds(),
+			SimulatedTimeRatio: timeProgress,
+		})
+		if err != nil {
+			log.Error(err)
+			return
+		}
+		fmt.Fprintln(os.Stderr, string(bytes))
+	}
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,106 @@
+package simulation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/multivers-simulation/clock"
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region Rate setter //////////////////////////////////////////////////////////////////////////////////
+
+// rateSetterFactors holds the current AIMD factor of every peer's issuance rate; the issuance loops
+// multiply it into the mana-derived base band on every iteration, so adjustments retarget the rates
+// live, like the adversary speedups do.
+var (
+	rateSetterFactors     = make(map[network.PeerID]float64)
+	rateSetterFactorMutex sync.RWMutex
+)
+
+// rateSetterFactor returns the current AIMD factor of the peer's issuance rate, 1 while the rate
+// setter is disabled or has not adjusted the peer yet.
+func rateSetterFactor(peerID network.PeerID) float64 {
+	rateSetterFactorMutex.RLock()
+	defer rateSetterFactorMutex.RUnlock()
+
+	if factor, exists := rateSetterFactors[peerID]; exists {
+		return factor
+	}
+	return 1
+}
+
+func setRateSetterFactor(peerID network.PeerID, factor float64) {
+	rateSetterFactorMutex.Lock()
+	defer rateSetterFactorMutex.Unlock()
+	rateSetterFactors[peerID] = factor
+}
+
+// resetRateSetter clears the rate factors of a previous run in the same process.
+func resetRateSetter() {
+	rateSetterFactorMutex.Lock()
+	defer rateSetterFactorMutex.Unlock()
+	rateSetterFactors = make(map[network.PeerID]float64)
+}
+
+// startRateSetterWorker adjusts the issuance rate of every honest peer with an AIMD rule once per
+// RateSetterInterval of simulated time: while the peer's own tip pool stays below the congestion
+// threshold the rate factor grows additively back towards the full mana-proportional band, once the
+// tip pool inflates beyond it the factor shrinks multiplicatively. Adversary peers keep their own
+// speedups and are left alone.
+func startRateSetterWorker(ctx context.Context, testNetwork *network.Network) {
+	interval := time.Duration(config.RateSetterInterval) * time.Second
+
+	adjust := func() {
+		for _, peer := range testNetwork.Peers {
+			if network.IsAdversary(int(peer.ID)) {
+				continue
+			}
+
+			factor := rateSetterFactor(peer.ID)
+			if peer.Node.(multiverse.NodeInterface).Tangle().TipManager.TipPoolSize() > config.RateSetterCongestionThreshold {
+				factor *= config.RateSetterDecrease
+			} else {
+				factor += config.RateSetterIncrease
+			}
+
+			if factor > 1 {
+				factor = 1
+			}
+			if factor < config.RateSetterMin {
+				factor = config.RateSetterMin
+			}
+			setRateSetterFactor(peer.ID, factor)
+		}
+	}
+
+	if config.DiscreteEvent {
+		var scheduleAdjustment func()
+		scheduleAdjustment = func() {
+			clock.Virtual.ExecuteAfter(interval, func() {
+				adjust()
+				scheduleAdjustment()
+			})
+		}
+		scheduleAdjustment()
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(config.SlowdownFactor) * interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				adjust()
+			}
+		}
+	}()
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,93 @@
+package simulation
+
+import (
+	"context"
+	"encoding/csv"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/iotaledger/hive.go/types"
+	"github.com/iotaledger/multivers-simulation/clock"
+	"github.com/iotaledger/multivers-simulation/config"
+)
+
+// region Resource watchdog ////////////////////////////////////////////////////////////////////////////////////////////
+
+// The resource watchdog samples the memory statistics of the process into rs-*.csv and gracefully ends
+// the run before the configured memory ceiling is hit, so large node counts degrade into a truncated but
+// fully dumped result set instead of being OOM-killed with everything still buffered.
+
+// resourceSampleInterval is the wall-clock interval between memory samples. The host's memory consumption
+// is independent of the virtual clock, so the watchdog always runs on real time.
+const resourceSampleInterval = time.Second
+
+// startResourceWatchdog samples runtime.MemStats into the rs-*.csv writer until the run context is
+// cancelled and requests a graceful shutdown when the configured memory ceiling is exceeded.
+func startResourceWatchdog(ctx context.Context, rsResultsWriter *csv.Writer) {
+	go func() {
+		ticker := time.NewTicker(resourceSampleInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if sampleResources(rsResultsWriter) {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// sampleResources dumps one memory sample and reports whether the memory ceiling was exceeded and the
+// shutdown of the run has been requested.
+func sampleResources(rsResultsWriter *csv.Writer) (ceilingExceeded bool) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	record := []string{
+		strconv.FormatInt(time.Since(wallClockStart).Nanoseconds(), 10),
+		strconv.FormatUint(memStats.HeapAlloc, 10),
+		strconv.FormatUint(memStats.Sys, 10),
+		strconv.FormatUint(uint64(memStats.NumGC), 10),
+		strconv.Itoa(runtime.NumGoroutine()),
+	}
+	if err := rsResultsWriter.Write(record); err != nil {
+		log.Fatal(err)
+	}
+
+	if config.MaxMemoryMB == 0 {
+		return false
+	}
+	usedMB := memStats.Sys / (1024 * 1024)
+	if usedMB < uint64(config.MaxMemoryMB) {
+		return false
+	}
+
+	log.Warnf("Memory ceiling exceeded (%d MB used, %d MB allowed), shutting down gracefully ...",
+		usedMB, config.MaxMemoryMB)
+	runTruncated = true
+	if config.DiscreteEvent {
+		clock.Virtual.Stop()
+	} else {
+		shutdownSignal <- types.Void
+	}
+
+	return true
+}
+
+// rsHeader returns the header of the rs-*.csv file.
+func rsHeader() []string {
+	return []string{
+		"Elapsed Time (ns)",
+		"Heap Alloc (bytes)",
+		"Sys (bytes)",
+		"GC Cycles",
+		"Goroutines",
+	}
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,3481 @@
+package simulation
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/crypto"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/types"
+	"github.com/iotaledger/hive.go/typeutils"
+	"github.com/iotaledger/multivers-simulation/adversary"
+	"github.com/iotaledger/multivers-simulation/clock"
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region Config and Results ///////////////////////////////////////////////////////////////////////////////////////////
+
+// Config collects the tunable parameters of a single simulation run so that other Go programs and tests can
+// run experiments programmatically instead of spawning the binary. Run applies it to the global config
+// package before starting, so a Config is usually derived from CurrentConfig with a few fields overridden.
+type Config struct {
+	NodesCount                    int
+	NodesTotalWeight              int
+	ZipfParameter                 float64
+	ConfirmationThreshold         float64
+	ConfirmationThresholdAbsolute bool
+	ParentsCount                  int
+	WeakTipsRatio                 float64
+	TSA                           string
+	TPS                           int
+	SlowdownFactor                int
+	ConsensusMonitorTick          int
+	RelevantValidatorWeight       int
+	DoubleSpendDelay              int
+	DoubleSpendSchedule           []string
+	PacketLoss                    float64
+	MinDelay                      int
+	MaxDelay                      int
+	DeltaURTS                     float64
+	SimulationStopThreshold       float64
+	SimulationTarget              string
+	SimulationMode                string
+	SimulationDuration            time.Duration
+	ResultDir                     string
+	IMIF                          string
+	Topology                      string
+	DegreeSequence                []int
+	ClusterCount                  int
+	ClusterSizes                  []int
+	ClusterIntraNeighbors         int
+	ClusterInterLinks             int
+	ClusterIntraDelayMs           int
+	ClusterInterDelayMs           int
+	ClusterInterPacketLoss        float64
+	RandomnessWS                  float64
+	NeighbourCountWS              int
+	ErdosRenyiP                   float64
+	BarabasiAlbertM               int
+	TopologyFile                  string
+	AsymmetricDelays              bool
+	DelayDistribution             string
+	LossDistribution              string
+	BurstLoss                     bool
+	BurstLossPGoodToBad           float64
+	BurstLossPBadToGood           float64
+	BurstLossBad                  float64
+	NatFraction                   float64
+	JitterMs                      float64
+	BandwidthMsgPerSec            int
+	GossipMode                    string
+	GossipFanout                  int
+	GossipPullInterval            int
+	PeerScoringInterval           int
+	PeerScoringMaxInvalidRatio    float64
+	PeerScoringMinDeliveries      int
+	GeoLatency                    bool
+	GeoBaseLatencyMs              int
+	LatencyMatrixFile             string
+	LatencyMatrixAssignment       string
+	RewireInterval                int
+	RewireFraction                float64
+	PartitionStart                int
+	PartitionDuration             int
+	PartitionCount                int
+	ChurnInterval                 int
+	ChurnFraction                 float64
+	ChurnDowntime                 int
+	Seed                          int64
+	DiscreteEvent                 bool
+	RecordTrace                   string
+	ReplayTrace                   string
+	MetricsWarmup                 int
+	StopConditions                string
+	WallClockLimit                int
+	MetastabilityFlips            int
+	MaxIssuedMessages             int
+	Scenario                      string
+	CalibrateSlowdown             bool
+	ProgressJSON                  bool
+	MaxMemoryMB                   int
+	MonitoredWitnessWeightPeer    int
+
+	AdversaryTypes           []int
+	AdversaryDelays          []int
+	AdversaryMana            []float64
+	AdversaryNodeCounts      []int
+	AdversaryInitColors      []string
+	AdversaryPeeringAll      bool
+	AdversarySpeedup         []float64
+	AdversaryManaGrowthRate  []float64
+	AdversaryManaSchedule    []string
+	AdversaryActivationTimes []int
+	AdversaryCompositions    []string
+	AccidentalMana           []string
+	AdversaryControlAddress  string
+	AdversaryInternalDelay   int
+	AdversaryAdaptInterval   int
+	AdversaryOmniscient      bool
+	AdversaryAbortConditions []string
+	AdversaryCensoredIssuers []int
+	AdversaryVictimNodes     []int
+	AdversaryVictimBias      float64
+	AdversarySpamInterval    int
+	AdversarySpamBurst       int
+	AdversaryWithholdDelay   int
+	SybilCount               int
+	SybilStart               int
+	SybilMana                float64
+	SybilType                int
+	SybilInitColor           string
+	AdversaryPeeringTopK     int
+	AdversaryPeeringNodes    []int
+	EclipseVictim            int
+	EclipseConnections       int
+}
+
+// CurrentConfig returns a Config populated from the global configuration (defaults or parsed flags).
+func CurrentConfig() Config {
+	return Config{
+		NodesCount:                    config.NodesCount,
+		NodesTotalWeight:              config.NodesTotalWeight,
+		ZipfParameter:                 config.ZipfParameter,
+		ConfirmationThreshold:         config.ConfirmationThreshold,
+		ConfirmationThresholdAbsolute: config.ConfirmationThresholdAbsolute,
+		ParentsCount:                  config.ParentsCount,
+		WeakTipsRatio:                 config.WeakTipsRatio,
+		TSA:                           config.TSA,
+		TPS:                           config.TPS,
+		SlowdownFactor:                config.SlowdownFactor,
+		ConsensusMonitorTick:          config.ConsensusMonitorTick,
+		RelevantValidatorWeight:       config.RelevantValidatorWeight,
+		DoubleSpendDelay:              config.DoubleSpendDelay,
+		DoubleSpendSchedule:           config.DoubleSpendSchedule,
+		PacketLoss:                    config.PacketLoss,
+		MinDelay:                      config.MinDelay,
+		MaxDelay:                      config.MaxDelay,
+		DeltaURTS:                     config.DeltaURTS,
+		SimulationStopThreshold:       config.SimulationStopThreshold,
+		SimulationTarget:              config.SimulationTarget,
+		SimulationMode:                config.SimulationMode,
+		SimulationDuration:            time.Minute,
+		ResultDir:                     config.ResultDir,
+		IMIF:                          config.IMIF,
+		Topology:                      config.Topology,
+		DegreeSequence:                config.DegreeSequence,
+		ClusterCount:                  config.ClusterCount,
+		ClusterSizes:                  config.ClusterSizes,
+		ClusterIntraNeighbors:         config.ClusterIntraNeighbors,
+		ClusterInterLinks:             config.ClusterInterLinks,
+		ClusterIntraDelayMs:           config.ClusterIntraDelayMs,
+		ClusterInterDelayMs:           config.ClusterInterDelayMs,
+		ClusterInterPacketLoss:        config.ClusterInterPacketLoss,
+		RandomnessWS:                  config.RandomnessWS,
+		NeighbourCountWS:              config.NeighbourCountWS,
+		ErdosRenyiP:                   config.ErdosRenyiP,
+		BarabasiAlbertM:               config.BarabasiAlbertM,
+		TopologyFile:                  config.TopologyFile,
+		AsymmetricDelays:              config.AsymmetricDelays,
+		DelayDistribution:             config.DelayDistribution,
+		LossDistribution:              config.LossDistribution,
+		BurstLoss:                     config.BurstLoss,
+		BurstLossPGoodToBad:           config.BurstLossPGoodToBad,
+		BurstLossPBadToGood:           config.BurstLossPBadToGood,
+		BurstLossBad:                  config.BurstLossBad,
+		NatFraction:                   config.NatFraction,
+		JitterMs:                      config.JitterMs,
+		BandwidthMsgPerSec:            config.BandwidthMsgPerSec,
+		GossipMode:                    config.GossipMode,
+		GossipFanout:                  config.GossipFanout,
+		GossipPullInterval:            config.GossipPullInterval,
+		PeerScoringInterval:           config.PeerScoringInterval,
+		PeerScoringMaxInvalidRatio:    config.PeerScoringMaxInvalidRatio,
+		PeerScoringMinDeliveries:      config.PeerScoringMinDeliveries,
+		GeoLatency:                    config.GeoLatency,
+		GeoBaseLatencyMs:              config.GeoBaseLatencyMs,
+		LatencyMatrixFile:             config.LatencyMatrixFile,
+		LatencyMatrixAssignment:       config.LatencyMatrixAssignment,
+		RewireInterval:                config.RewireInterval,
+		RewireFraction:                config.RewireFraction,
+		PartitionStart:                config.PartitionStart,
+		PartitionDuration:             config.PartitionDuration,
+		PartitionCount:                config.PartitionCount,
+		ChurnInterval:                 config.ChurnInterval,
+		ChurnFraction:                 config.ChurnFraction,
+		ChurnDowntime:                 config.ChurnDowntime,
+		Seed:                          config.Seed,
+		DiscreteEvent:                 config.DiscreteEvent,
+		RecordTrace:                   config.RecordTrace,
+		ReplayTrace:                   config.ReplayTrace,
+		MetricsWarmup:                 config.MetricsWarmup,
+		StopConditions:                config.StopConditions,
+		WallClockLimit:                config.WallClockLimit,
+		MetastabilityFlips:            config.MetastabilityFlips,
+		MaxIssuedMessages:             config.MaxIssuedMessages,
+		Scenario:                      config.Scenario,
+		CalibrateSlowdown:             config.CalibrateSlowdown,
+		ProgressJSON:                  config.ProgressJSON,
+		MaxMemoryMB:                   config.MaxMemoryMB,
+		MonitoredWitnessWeightPeer:    config.MonitoredWitnessWeightPeer,
+		AdversaryTypes:                config.AdversaryTypes,
+		AdversaryDelays:               config.AdversaryDelays,
+		AdversaryMana:                 config.AdversaryMana,
+		AdversaryNodeCounts:           config.AdversaryNodeCounts,
+		AdversaryInitColors:           config.AdversaryInitColors,
+		AdversaryPeeringAll:           config.AdversaryPeeringAll,
+		AdversarySpeedup:              config.AdversarySpeedup,
+		AdversaryManaGrowthRate:       config.AdversaryManaGrowthRate,
+		AdversaryManaSchedule:         config.AdversaryManaSchedule,
+		AdversaryActivationTimes:      config.AdversaryActivationTimes,
+		AdversaryCompositions:         config.AdversaryCompositions,
+		AccidentalMana:                config.AccidentalMana,
+		AdversaryControlAddress:       config.AdversaryControlAddress,
+		AdversaryInternalDelay:        config.AdversaryInternalDelay,
+		AdversaryAdaptInterval:        config.AdversaryAdaptInterval,
+		AdversaryOmniscient:           config.AdversaryOmniscient,
+		AdversaryAbortConditions:      config.AdversaryAbortConditions,
+		AdversaryCensoredIssuers:      config.AdversaryCensoredIssuers,
+		AdversaryVictimNodes:          config.AdversaryVictimNodes,
+		AdversaryVictimBias:           config.AdversaryVictimBias,
+		AdversarySpamInterval:         config.AdversarySpamInterval,
+		AdversarySpamBurst:            config.AdversarySpamBurst,
+		AdversaryWithholdDelay:        config.AdversaryWithholdDelay,
+		SybilCount:                    config.SybilCount,
+		SybilStart:                    config.SybilStart,
+		SybilMana:                     config.SybilMana,
+		SybilType:                     config.SybilType,
+		SybilInitColor:                config.SybilInitColor,
+		AdversaryPeeringTopK:          config.AdversaryPeeringTopK,
+		AdversaryPeeringNodes:         config.AdversaryPeeringNodes,
+		EclipseVictim:                 config.EclipseVictim,
+		EclipseConnections:            config.EclipseConnections,
+	}
+}
+
+// apply copies the Config back onto the global configuration that the simulator components read.
+func (c Config) apply() {
+	config.NodesCount = c.NodesCount
+	config.NodesTotalWeight = c.NodesTotalWeight
+	config.ZipfParameter = c.ZipfParameter
+	config.ConfirmationThreshold = c.ConfirmationThreshold
+	config.ConfirmationThresholdAbsolute = c.ConfirmationThresholdAbsolute
+	config.ParentsCount = c.ParentsCount
+	config.WeakTipsRatio = c.WeakTipsRatio
+	config.TSA = c.TSA
+	config.TPS = c.TPS
+	config.SlowdownFactor = c.SlowdownFactor
+	config.ConsensusMonitorTick = c.ConsensusMonitorTick
+	config.RelevantValidatorWeight = c.RelevantValidatorWeight
+	config.DoubleSpendDelay = c.DoubleSpendDelay
+	config.DoubleSpendSchedule = c.DoubleSpendSchedule
+	config.PacketLoss = c.PacketLoss
+	config.MinDelay = c.MinDelay
+	config.MaxDelay = c.MaxDelay
+	config.DeltaURTS = c.DeltaURTS
+	config.SimulationStopThreshold = c.SimulationStopThreshold
+	config.SimulationTarget = c.SimulationTarget
+	config.SimulationMode = c.SimulationMode
+	config.ResultDir = c.ResultDir
+	config.IMIF = c.IMIF
+	config.Topology = c.Topology
+	config.DegreeSequence = c.DegreeSequence
+	config.ClusterCount = c.ClusterCount
+	config.ClusterSizes = c.ClusterSizes
+	config.ClusterIntraNeighbors = c.ClusterIntraNeighbors
+	config.ClusterInterLinks = c.ClusterInterLinks
+	config.ClusterIntraDelayMs = c.ClusterIntraDelayMs
+	config.ClusterInterDelayMs = c.ClusterInterDelayMs
+	config.ClusterInterPacketLoss = c.ClusterInterPacketLoss
+	config.RandomnessWS = c.RandomnessWS
+	config.NeighbourCountWS = c.NeighbourCountWS
+	config.ErdosRenyiP = c.ErdosRenyiP
+	config.BarabasiAlbertM = c.BarabasiAlbertM
+	config.TopologyFile = c.TopologyFile
+	config.AsymmetricDelays = c.AsymmetricDelays
+	config.DelayDistribution = c.DelayDistribution
+	config.LossDistribution = c.LossDistribution
+	config.BurstLoss = c.BurstLoss
+	config.BurstLossPGoodToBad = c.BurstLossPGoodToBad
+	config.BurstLossPBadToGood = c.BurstLossPBadToGood
+	config.BurstLossBad = c.BurstLossBad
+	config.NatFraction = c.NatFraction
+	config.JitterMs = c.JitterMs
+	config.BandwidthMsgPerSec = c.BandwidthMsgPerSec
+	config.GossipMode = c.GossipMode
+	config.GossipFanout = c.GossipFanout
+	config.GossipPullInterval = c.GossipPullInterval
+	config.PeerScoringInterval = c.PeerScoringInterval
+	config.PeerScoringMaxInvalidRatio = c.PeerScoringMaxInvalidRatio
+	config.PeerScoringMinDeliveries = c.PeerScoringMinDeliveries
+	config.GeoLatency = c.GeoLatency
+	config.GeoBaseLatencyMs = c.GeoBaseLatencyMs
+	config.LatencyMatrixFile = c.LatencyMatrixFile
+	config.LatencyMatrixAssignment = c.LatencyMatrixAssignment
+	config.RewireInterval = c.RewireInterval
+	config.RewireFraction = c.RewireFraction
+	config.PartitionStart = c.PartitionStart
+	config.PartitionDuration = c.PartitionDuration
+	config.PartitionCount = c.PartitionCount
+	config.ChurnInterval = c.ChurnInterval
+	config.ChurnFraction = c.ChurnFraction
+	config.ChurnDowntime = c.ChurnDowntime
+	config.Seed = c.Seed
+	config.DiscreteEvent = c.DiscreteEvent
+	config.RecordTrace = c.RecordTrace
+	config.ReplayTrace = c.ReplayTrace
+	config.MetricsWarmup = c.MetricsWarmup
+	config.StopConditions = c.StopConditions
+	config.WallClockLimit = c.WallClockLimit
+	config.MetastabilityFlips = c.MetastabilityFlips
+	config.MaxIssuedMessages = c.MaxIssuedMessages
+	config.Scenario = c.Scenario
+	config.CalibrateSlowdown = c.CalibrateSlowdown
+	config.ProgressJSON = c.ProgressJSON
+	config.MaxMemoryMB = c.MaxMemoryMB
+	config.MonitoredWitnessWeightPeer = c.MonitoredWitnessWeightPeer
+	config.AdversaryTypes = c.AdversaryTypes
+	config.AdversaryDelays = c.AdversaryDelays
+	config.AdversaryMana = c.AdversaryMana
+	config.AdversaryNodeCounts = c.AdversaryNodeCounts
+	config.AdversaryInitColors = c.AdversaryInitColors
+	config.AdversaryPeeringAll = c.AdversaryPeeringAll
+	config.AdversarySpeedup = c.AdversarySpeedup
+	config.AdversaryManaGrowthRate = c.AdversaryManaGrowthRate
+	config.AdversaryManaSchedule = c.AdversaryManaSchedule
+	config.AdversaryActivationTimes = c.AdversaryActivationTimes
+	config.AdversaryCompositions = c.AdversaryCompositions
+	config.AccidentalMana = c.AccidentalMana
+	config.AdversaryControlAddress = c.AdversaryControlAddress
+	config.AdversaryInternalDelay = c.AdversaryInternalDelay
+	config.AdversaryAdaptInterval = c.AdversaryAdaptInterval
+	config.AdversaryOmniscient = c.AdversaryOmniscient
+	config.AdversaryAbortConditions = c.AdversaryAbortConditions
+	config.AdversaryCensoredIssuers = c.AdversaryCensoredIssuers
+	config.AdversaryVictimNodes = c.AdversaryVictimNodes
+	config.AdversaryVictimBias = c.AdversaryVictimBias
+	config.AdversarySpamInterval = c.AdversarySpamInterval
+	config.AdversarySpamBurst = c.AdversarySpamBurst
+	config.AdversaryWithholdDelay = c.AdversaryWithholdDelay
+	config.SybilCount = c.SybilCount
+	config.SybilStart = c.SybilStart
+	config.SybilMana = c.SybilMana
+	config.SybilType = c.SybilType
+	config.SybilInitColor = c.SybilInitColor
+	config.AdversaryPeeringTopK = c.AdversaryPeeringTopK
+	config.AdversaryPeeringNodes = c.AdversaryPeeringNodes
+	config.EclipseVictim = c.EclipseVictim
+	config.EclipseConnections = c.EclipseConnections
+}
+
+// Results summarizes a finished run in addition to the CSV files written to the result directory.
+type Results struct {
+	StartTime             time.Time
+	ResultDir             string
+	SimulatedDuration     time.Duration
+	ConsensusReached      bool
+	Flips                 int64
+	HonestFlips           int64
+	IssuedMessages        int64
+	ConfirmedMessages     map[network.PeerID]int64
+	ConfirmedNodes        map[multiverse.Color]int64
+	LikeAccumulatedWeight map[multiverse.Color]int64
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// region Run //////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+var (
+
+	// csv
+	awHeader = []string{"Message ID", "Issuance Time (unix)", "Grade", "Confirmation Time (ns)", "ParentID", "# of Confirmed Messages",
+		"# of Issued Messages", "ns since start"}
+	wwHeader = []string{"Witness Weight", "Time (ns)"}
+	mmHeader = []string{"Number of Requested Messages", "ns since start"}
+	adHeader = []string{"AdversaryGroupID", "Strategy", "AdversaryCount", "q", "ns since issuance"}
+	ndHeader = []string{"Node ID", "Adversary", "Min Confirmed Accumulated Weight", "Unconfirmation Count"}
+	ptHeader = []string{"Node ID", "Partition"}
+	ntHeader = []string{"Node ID", "NATed", "Inbound Degree", "Outbound Degree"}
+	qlHeader = []string{"Total Queue Length", "Max Queue Length", "ns since start"}
+	gsHeader = []string{"Received Messages", "Duplicate Messages", "Rejected Timestamps", "Missing Requests", "Retried Requests", "Replayed Requests", "Failed Requests", "Reattachments", "ns since start"}
+	csHeader = []string{"Issuer", "Issued Messages", "Orphaned Messages", "ns since start"}
+	orHeader = []string{"Issuer", "Class", "Mature Messages", "Orphaned Messages", "Orphan Rate", "Aged-Out Tips", "ns since start"}
+	pcHeader = []string{"Parasitic Messages", "Honest Approvers", "Captured Weight", "ns since start"}
+	ecHeader = []string{"Victim Opinion", "Honest Majority Opinion", "Victim Confirmed Messages",
+		"Monitored Peer Confirmed Messages", "ns since start"}
+	abHeader = []string{"Spent Messages", "Spent Releases", "Spent Connections", "Spent Total",
+		"Remaining Budget", "ns since start"}
+	dtHeader = []string{"Node ID", "Equivocations Seen", "Over-Rate Messages", "Conflicting Timestamps",
+		"ns since start"}
+	epHeader = []string{"Epoch", "Distinct Commitments", "Majority Share", "ns since start"}
+	rtHeader = []string{"Node ID", "Rate Factor", "Messages Per Second", "Tip Pool Size", "ns since start"}
+	scHeader = []string{"Node ID", "Queued Messages", "Longest Queue", "Scheduled Messages",
+		"Mean Scheduling Delay (ms)", "ns since start"}
+
+	csvMutex sync.Mutex
+
+	// simulation variables
+	dumpingTicker         *time.Ticker
+	simulationWg          = sync.WaitGroup{}
+	maxSimulationDuration = time.Minute
+	shutdownSignal        chan types.Empty
+	consensusReached      bool
+
+	// global declarations
+	dsIssuanceTime           time.Time
+	mostLikedColor           multiverse.Color
+	honestOnlyMostLikedColor multiverse.Color
+	simulationStartTime      time.Time
+	wallClockStart           time.Time
+
+	// counters
+	colorCounters     = NewColorCounters()
+	adversaryCounters = NewColorCounters()
+	nodeCounters      = []AtomicCounters{}
+	atomicCounters    = NewAtomicCounters()
+
+	confirmedMessageCounter = make(map[network.PeerID]int64)
+	confirmedMessageMutex   sync.RWMutex
+
+	// simulationCtx cancels all issuance workers and monitoring tickers on shutdown
+	simulationCtx    context.Context
+	simulationCancel context.CancelFunc
+
+	// simulation start time string in the result file name
+	simulationStartTimeStr string
+)
+
+// perColorColumns expands the format (one %s verb for the color name) into one column per conflicting
+// color. The result headers are built this way instead of being hardcoded so that they follow
+// config.ConflictColorCount.
+func perColorColumns(format string) (columns []string) {
+	for _, color := range multiverse.ConflictingColors() {
+		columns = append(columns, fmt.Sprintf(format, color.Name()))
+	}
+	return
+}
+
+// dsHeader returns the header of the ds file: one opinion column per color including UndefinedColor.
+func dsHeader() []string {
+	return append(append([]string{"UndefinedColor"}, perColorColumns("%s")...),
+		"ns since start", "ns since issuance")
+}
+
+// tpHeader returns the header of the tp file: tip pool sizes and processed messages per color.
+func tpHeader() []string {
+	columns := append([]string{"UndefinedColor (Tip Pool Size)"}, perColorColumns("%s (Tip Pool Size)")...)
+	columns = append(columns, "UndefinedColor (Processed)")
+	columns = append(columns, perColorColumns("%s (Processed)")...)
+	return append(columns, "# of Issued Messages", "# of Weak References", "ns since start")
+}
+
+// ccHeader returns the header of the cc file: the per-color confirmation, opinion and weight counters.
+func ccHeader() []string {
+	var columns []string
+	for _, format := range []string{
+		"%s (Confirmed)",
+		"%s (Adversary Confirmed)",
+		"%s (Confirmed Accumulated Weight)",
+		"%s (Confirmed Adversary Weight)",
+		"%s (Like)",
+		"%s (Like Accumulated Weight)",
+		"%s (Adversary Like Accumulated Weight)",
+		"Unconfirmed %s",
+		"Unconfirmed %s Accumulated Weight",
+	} {
+		columns = append(columns, perColorColumns(format)...)
+	}
+	return append(columns, "Flips (Winning color changed)", "Honest nodes Flips", "ns since start", "ns since issuance")
+}
+
+// Run executes one simulation with the given configuration and returns the structured results. All result
+// files are still written to cfg.ResultDir. Note that the underlying network package keeps global node
+// counters, so at most one Run may be active per process at a time.
+func Run(cfg Config) (results Results, err error) {
+	if cfg.NodesCount <= 0 {
+		return results, fmt.Errorf("invalid configuration: NodesCount must be positive, got %d", cfg.NodesCount)
+	}
+	if cfg.SimulationDuration <= 0 {
+		return results, fmt.Errorf("invalid configuration: SimulationDuration must be positive, got %s", cfg.SimulationDuration)
+	}
+	cfg.apply()
+	resetRunState()
+	maxSimulationDuration = cfg.SimulationDuration
+
+	if config.Seed != 0 {
+		rand.Seed(config.Seed)
+	}
+
+	nodeFactories := adversary.NodeFactories()
+	// the discrete-event scheduler never falls behind, so calibration only applies to realtime runs
+	if config.CalibrateSlowdown && !config.DiscreteEvent {
+		CalibrateSlowdownFactor()
+	}
+
+	// virtual time does not need to be slowed down for the hosts to keep up
+	slowdown := config.SlowdownFactor
+	if config.DiscreteEvent {
+		slowdown = 1
+		multiverse.TimeSource = clock.Virtual
+	} else {
+		multiverse.TimeSource = clock.WallClock{}
+	}
+	testNetwork := network.New(
+		network.Nodes(config.NodesCount, nodeFactories, network.ZIPFDistribution(
+			config.ZipfParameter)),
+		network.Delay(time.Duration(slowdown)*time.Duration(config.MinDelay)*time.Millisecond,
+			time.Duration(slowdown)*time.Duration(config.MaxDelay)*time.Millisecond),
+		network.PacketLoss(config.PacketLoss, config.PacketLoss),
+		network.DelayDistribution(config.DelayDistribution, slowdown),
+		network.LossDistribution(config.LossDistribution),
+		network.Jitter(time.Duration(float64(slowdown)*config.JitterMs*float64(time.Millisecond))),
+		network.Topology(topologyStrategy()),
+		network.AdversaryPeeringAll(config.AdversaryPeeringAll),
+		network.AdversaryPeeringTargets(adversaryPeeringTargets()),
+		network.AdversaryInternalDelay(scaledAdversaryInternalDelay(slowdown)),
+		network.AdversarySpeedup(config.AdversarySpeedup),
+		network.CompositeNodes(adversary.NewCompositeNode),
+	)
+	// replace the uniform delay range with measured or distance-derived link delays when enabled
+	if config.LatencyMatrixFile != "" {
+		network.ApplyLatencyMatrix(testNetwork, config.LatencyMatrixFile, config.LatencyMatrixAssignment, slowdown)
+	} else if config.GeoLatency {
+		network.ApplyGeoLatency(testNetwork, time.Duration(config.GeoBaseLatencyMs)*time.Millisecond, slowdown)
+	}
+
+	// The connections the adversary peering added on top of the topology consume action budget
+	adversary.ChargeConnections(network.ExtraAdversaryLinks())
+
+	// A configured speedup would give the stealth nodes away, so it is reset to the honest rate
+	adversary.EnforceStealthTraffic(testNetwork)
+
+	// Put a fraction of the nodes behind a NAT, leaving some links unidirectional
+	if config.NatFraction > 0 {
+		network.ApplyNat(testNetwork, config.NatFraction)
+	}
+
+	// Hand the victim's connections to the adversary for eclipse-attack experiments
+	if config.EclipseVictim >= 0 {
+		network.ApplyEclipse(testNetwork, config.EclipseVictim, config.EclipseConnections)
+	}
+
+	// The sybil identities stay offline and without mana until their injection
+	if config.SybilCount > 0 {
+		for _, group := range testNetwork.AdversaryGroups {
+			if group.Sybil {
+				for _, nodeID := range group.NodeIDs {
+					testNetwork.Peer(nodeID).SetOffline(true)
+				}
+			}
+		}
+		scheduleSybilInjection(testNetwork)
+	}
+
+	// Groups with a delayed start keep their members silent until the configured activation time
+	if len(config.AdversaryActivationTimes) > 0 {
+		scheduleAdversaryActivations(testNetwork)
+	}
+
+	testNetwork.Start()
+	defer testNetwork.Shutdown()
+
+	// SIGUSR1 toggles between paused and running, see pause.go
+	listenForPauseSignals(simulationCtx)
+
+	resultsWriters := monitorNetworkState(testNetwork)
+	// the resource watchdog samples memory usage into rs-*.csv and ends the run before the host kills it
+	rsResultsWriter := createWriter(fmt.Sprintf("rs-%s.csv", simulationStartTimeStr), rsHeader(), &resultsWriters)
+	startResourceWatchdog(simulationCtx, rsResultsWriter)
+	// the partition membership dump, created up front so the deferred flush below covers it
+	var ptResultsWriter *csv.Writer
+	if config.PartitionStart > 0 {
+		ptResultsWriter = createWriter(fmt.Sprintf("pt-%s.csv", simulationStartTimeStr), ptHeader, &resultsWriters)
+	}
+	// the NAT connectivity dump, written once since the NAT assignment is static
+	if config.NatFraction > 0 {
+		ntResultsWriter := createWriter(fmt.Sprintf("nt-%s.csv", simulationStartTimeStr), ntHeader, &resultsWriters)
+		dumpResultsNT(ntResultsWriter, testNetwork)
+	}
+	// the manifest is written after the deferred flush below, so the row counts cover all records
+	defer writeRunManifest()
+	defer flushWriters(resultsWriters)
+	// a killed or panicking run still flushes what it has, marked as truncated in the manifest
+	listenForTerminationSignals(simulationCtx, resultsWriters)
+	defer flushOnPanic(resultsWriters)
+
+	// Record all issuance and delivery events so the exact run can be re-analyzed later
+	if config.RecordTrace != "" {
+		StartTraceRecording(config.RecordTrace)
+		defer StopTraceRecording()
+	}
+
+	// Write the confirmed state of the observed peer at the end of the run for later warm starts
+	if config.SnapshotExportFile != "" {
+		defer exportSnapshot(testNetwork)
+	}
+
+	// A replay feeds the recorded events straight into the tangles instead of running the network layer
+	if config.ReplayTrace != "" {
+		ReplayTraceFile(config.ReplayTrace, testNetwork)
+		shutdownSimulation()
+		log.Info("Shutting down simulation (trace replay finished) ... [DONE]")
+		return collectResults(), nil
+	}
+
+	// Warm-start every node's tangle from a previously exported snapshot
+	if config.SnapshotImportFile != "" {
+		importSnapshot(testNetwork)
+	}
+
+	// Start the tangles from the preassigned colored genesis messages instead of the bare genesis
+	if len(config.GenesisMessages) > 0 {
+		applyGenesisSet(testNetwork)
+	}
+
+	secureNetwork(simulationCtx, testNetwork)
+
+	// A scenario scripts a timeline of events (multi-stage attacks etc.) against the network
+	if config.Scenario != "" {
+		scenario, err := LoadScenario(config.Scenario)
+		if err != nil {
+			return results, err
+		}
+		scenario.Schedule(testNetwork)
+	}
+
+	// Expose the adversary nodes to an external controller process (e.g. an RL attack search)
+	if config.AdversaryControlAddress != "" {
+		adversary.NewController(testNetwork).Listen(config.AdversaryControlAddress)
+	}
+
+	// Let adversary groups acquire mana over the run according to the configured schedule
+	if len(config.AdversaryManaGrowthRate) > 0 {
+		go startManaGrowthWorker(simulationCtx, testNetwork)
+	}
+
+	// Ramp the adversary mana shares along the configured checkpoints
+	if len(config.AdversaryManaSchedule) > 0 {
+		go startManaScheduleWorker(simulationCtx, testNetwork)
+	}
+
+	// Periodically rewire a fraction of the connections to model neighbor churn
+	if config.RewireInterval > 0 {
+		network.Events.ConnectionsRewired.Attach(events.NewClosure(func(rewired int) {
+			log.Infof("Rewired %d connections", rewired)
+		}))
+		startRewiringWorker(simulationCtx, testNetwork)
+	}
+
+	// Periodically take honest nodes offline and bring them back to model node churn
+	if config.ChurnInterval > 0 {
+		startChurnWorker(simulationCtx, testNetwork)
+	}
+
+	// Split the network into components at PartitionStart and heal it PartitionDuration later
+	if config.PartitionStart > 0 {
+		schedulePartition(testNetwork, ptResultsWriter)
+	}
+
+	// Let the largest-weight node issue milestones that confirm their past cone
+	if config.CoordinatorEnabled {
+		startCoordinatorWorker(simulationCtx, testNetwork)
+	}
+
+	// Let honest nodes adapt their issuance rate to the observed congestion
+	if config.RateSetterEnabled {
+		startRateSetterWorker(simulationCtx, testNetwork)
+	}
+
+	// Retarget the adaptive proof-of-work difficulty of all nodes from their recent issuance rates
+	if config.AdaptivePowEnabled {
+		startAdaptivePowWorker(simulationCtx, testNetwork)
+	}
+
+	// Decay and redistribute the consensus weights at the epoch boundaries
+	if config.DynamicWeightsEnabled {
+		startWeightEvolutionWorker(simulationCtx, testNetwork)
+	}
+
+	// Resolve conflicting colors through FPC query rounds instead of approval weight
+	if config.FPCVotingEnabled {
+		startFPCWorker(simulationCtx, testNetwork)
+	}
+
+	// Drain the outbox queues of the nodes' schedulers in deficit round robin order
+	if config.SchedulerEnabled {
+		startSchedulerWorker(simulationCtx, testNetwork)
+	}
+
+	// Evict long-confirmed messages from the in-memory tangles of the nodes
+	if config.PruningEnabled {
+		startPruningWorker(simulationCtx, testNetwork)
+	}
+
+	// Let honest nodes reissue the payloads of their own orphaned messages
+	if config.ReattachmentEnabled {
+		startReattachmentWorker(simulationCtx, testNetwork)
+	}
+
+	// Periodically announce the booked messages so the neighbors can pull what the limited push missed
+	if config.GossipMode == "pull" {
+		startGossipPullWorker(simulationCtx, testNetwork)
+	}
+
+	// Periodically evaluate the neighbor scores and drop misbehaving neighbors
+	if config.PeerScoringInterval > 0 {
+		startPeerScoringWorker(simulationCtx, testNetwork)
+	}
+
+	// Periodically feed the adaptive adversary nodes an observation of their own tangle
+	if config.AdversaryAdaptInterval > 0 {
+		startAdversaryAdaptationWorker(simulationCtx, testNetwork)
+	}
+
+	// Periodically let the adversary nodes flood the tip pool with a burst of messages
+	if config.AdversarySpamInterval > 0 {
+		startSpamBurstWorker(simulationCtx, testNetwork)
+	}
+
+	// Release the private side tangles of the withholding adversary nodes all at once
+	if config.AdversaryWithholdDelay > 0 {
+		scheduleWithholdingRelease(testNetwork)
+	}
+
+	// Periodically splice the private parasitic chains into the main tangle
+	if config.AdversaryParasiteInterval > 0 {
+		startParasiteAttachWorker(simulationCtx, testNetwork)
+	}
+
+	// Call the attacks of opportunistic adversary groups off once their abort condition is met
+	if len(config.AdversaryAbortConditions) > 0 {
+		startAdversaryAbortWorker(simulationCtx, testNetwork)
+	}
+
+	// Periodically let the replay adversary nodes re-inject stale traffic
+	if config.AdversaryReplayInterval > 0 {
+		startReplayWorker(simulationCtx, testNetwork)
+	}
+
+	// To simulate the confirmation time w/o any double spending, the colored msgs are not to be sent
+	if config.SimulationTarget == "DS" {
+		if len(config.DoubleSpendSchedule) > 0 {
+			scheduleDoubleSpends(testNetwork)
+		} else if config.DiscreteEvent {
+			clock.Virtual.ExecuteAfter(time.Duration(config.DoubleSpendDelay)*time.Second, func() {
+				issueDoubleSpends(testNetwork)
+			})
+		} else {
+			simulateDoubleSpent(testNetwork)
+		}
+	}
+
+	if config.DiscreteEvent {
+		clock.Virtual.Run(maxSimulationDuration)
+		shutdownSimulation()
+		log.Info("Shutting down simulation (discrete-event run finished) ... [DONE]")
+		return collectResults(), nil
+	}
+
+	select {
+	case <-shutdownSignal:
+		shutdownSimulation()
+		log.Info("Shutting down simulation (consensus reached) ... [DONE]")
+	case <-time.After(time.Duration(config.SlowdownFactor) * maxSimulationDuration):
+		shutdownSimulation()
+		log.Info("Shutting down simulation (simulation timed out) ... [DONE]")
+	}
+
+	return collectResults(), nil
+}
+
+// resetRunState re-initializes the per-run package state, so that the counters of a previous Run in the
+// same process do not leak into the next one.
+func resetRunState() {
+	colorCounters = NewColorCounters()
+	adversaryCounters = NewColorCounters()
+	nodeCounters = []AtomicCounters{}
+	atomicCounters = NewAtomicCounters()
+	confirmedMessageCounter = make(map[network.PeerID]int64)
+	multiverse.ResetGossipStatistics()
+	multiverse.ResetTimestampStatistics()
+	multiverse.ResetRequestStatistics()
+	multiverse.ResetWeakReferenceStatistics()
+	multiverse.ResetAgedOutTipStatistics()
+	multiverse.ResetReattachmentStatistics()
+	resetAttackSummary()
+	adversary.ResetBudget()
+	multiverse.ResetLedger()
+	multiverse.ResetEpochs()
+	shutdownSignal = make(chan types.Empty)
+	consensusReached = false
+	dsIssuanceTime = time.Time{}
+	issuanceBands = make(map[network.PeerID]float64)
+	resetRateSetter()
+	resetAdaptivePow()
+	resetWeightEvolution()
+	resetFPC()
+	wallClockStart = time.Now()
+	dumpingTicker = time.NewTicker(time.Duration(config.SlowdownFactor*config.ConsensusMonitorTick) * time.Millisecond)
+	simulationCtx, simulationCancel = context.WithCancel(context.Background())
+	runTruncated = false
+	crashFlushOnce = sync.Once{}
+}
+
+// collectResults snapshots the counters of the finished run into a Results value.
+func collectResults() (results Results) {
+	results = Results{
+		StartTime:             simulationStartTime,
+		ResultDir:             config.ResultDir,
+		SimulatedDuration:     sinceStart(simulationStartTime),
+		ConsensusReached:      consensusReached,
+		Flips:                 atomicCounters.Get("flips"),
+		HonestFlips:           atomicCounters.Get("honestFlips"),
+		IssuedMessages:        atomicCounters.Get("issuedMessages"),
+		ConfirmedMessages:     make(map[network.PeerID]int64),
+		ConfirmedNodes:        make(map[multiverse.Color]int64),
+		LikeAccumulatedWeight: make(map[multiverse.Color]int64),
+	}
+
+	confirmedMessageMutex.RLock()
+	for peerID, confirmedCount := range confirmedMessageCounter {
+		results.ConfirmedMessages[peerID] = confirmedCount
+	}
+	confirmedMessageMutex.RUnlock()
+
+	for _, color := range multiverse.ConflictingColors() {
+		results.ConfirmedNodes[color] = colorCounters.Get("confirmedNodes", color)
+		results.LikeAccumulatedWeight[color] = colorCounters.Get("likeAccumulatedWeight", color)
+	}
+
+	return
+}
+
+func simulateDoubleSpent(testNetwork *network.Network) {
+	time.Sleep(time.Duration(config.DoubleSpendDelay*config.SlowdownFactor) * time.Second)
+	issueDoubleSpends(testNetwork)
+}
+
+// doubleSpendStage is one parsed entry of config.DoubleSpendSchedule.
+type doubleSpendStage struct {
+	delay  time.Duration
+	issuer string
+	color  multiverse.Color
+}
+
+// parseDoubleSpendSchedule parses the 'delaySeconds:issuer:color' entries of config.DoubleSpendSchedule.
+func parseDoubleSpendSchedule() (stages []doubleSpendStage) {
+	for _, entry := range config.DoubleSpendSchedule {
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 {
+			log.Fatalf("Invalid double spend schedule entry %q, expected delaySeconds:issuer:color", entry)
+		}
+		delaySeconds, err := strconv.Atoi(parts[0])
+		if err != nil || delaySeconds < 0 {
+			log.Fatalf("Invalid delay in double spend schedule entry %q", entry)
+		}
+		color := multiverse.ColorFromStr(parts[2])
+		if color == multiverse.UndefinedColor {
+			log.Fatalf("Invalid color in double spend schedule entry %q", entry)
+		}
+		stages = append(stages, doubleSpendStage{
+			delay:  time.Duration(delaySeconds) * time.Second,
+			issuer: parts[1],
+			color:  color,
+		})
+	}
+
+	return
+}
+
+// scheduleDoubleSpends issues one conflict per entry of the configured schedule, at the entry's time and
+// from the entry's issuer, replacing the single conflict after DoubleSpendDelay.
+func scheduleDoubleSpends(testNetwork *network.Network) {
+	stages := parseDoubleSpendSchedule()
+
+	// in ledger mode all scheduled conflicts spend the same output; the stage transactions are created
+	// up front so their colors are allocated before the first issuance, the configured colors are ignored
+	stageTransactions := make([]*multiverse.Transaction, len(stages))
+	if config.LedgerEnabled {
+		scheduledOutput := multiverse.NewOutput()
+		for i := range stages {
+			stageTransactions[i] = multiverse.NewTransaction(scheduledOutput)
+		}
+	}
+
+	for stageIndex, stage := range stages {
+		stage := stage
+		stageTx := stageTransactions[stageIndex]
+		issue := func() {
+			peer := network.GetIssuer(testNetwork, stage.issuer)
+			if peer == nil {
+				return
+			}
+			if dsIssuanceTime.IsZero() {
+				dsIssuanceTime = now()
+			}
+			if stageTx != nil {
+				sendTransaction(peer, stageTx)
+				log.Infof("Peer %d sent scheduled double spend msg: %v", peer.ID, stageTx.Color)
+				return
+			}
+			sendMessage(peer, stage.color)
+			log.Infof("Peer %d sent scheduled double spend msg: %v", peer.ID, stage.color)
+		}
+		if config.DiscreteEvent {
+			clock.Virtual.ExecuteAfter(stage.delay, issue)
+		} else {
+			time.AfterFunc(time.Duration(config.SlowdownFactor)*stage.delay, issue)
+		}
+	}
+}
+
+func issueDoubleSpends(testNetwork *network.Network) {
+	// Here we simulate the double spending
+	dsIssuanceTime = now()
+
+	// in ledger mode all conflicting issuers spend the same output; the colors emerge from the spend
+	// registry instead of being injected, so the configured colors are ignored
+	var doubleSpentOutput multiverse.OutputID
+	if config.LedgerEnabled {
+		doubleSpentOutput = multiverse.NewOutput()
+	}
+
+	switch config.SimulationMode {
+	case "Accidental":
+		issuers := network.GetAccidentalIssuers(testNetwork)
+		// all conflicting transactions are created before the first one is issued, so every message
+		// already carries the color of its conflict branch
+		transactions := make([]*multiverse.Transaction, len(issuers))
+		if config.LedgerEnabled {
+			for i := range issuers {
+				transactions[i] = multiverse.NewTransaction(doubleSpentOutput)
+			}
+		}
+		for i, node := range issuers {
+			color := multiverse.ColorFromInt(i + 1)
+			issue := func() { sendMessage(node, color) }
+			if config.LedgerEnabled {
+				tx := transactions[i]
+				color = tx.Color
+				issue = func() { sendTransaction(node, tx) }
+			}
+			if config.DiscreteEvent {
+				issue()
+			} else {
+				go issue()
+			}
+			log.Infof("Peer %d sent double spend msg: %v", node.ID, color)
+		}
+	case "Adversary":
+		// see the Accidental case: the conflicting group transactions are created up front
+		groupTransactions := make([]*multiverse.Transaction, len(testNetwork.AdversaryGroups))
+		if config.LedgerEnabled {
+			for i := range testNetwork.AdversaryGroups {
+				groupTransactions[i] = multiverse.NewTransaction(doubleSpentOutput)
+			}
+		}
+		for groupID, group := range testNetwork.AdversaryGroups {
+			color := multiverse.ColorFromStr(group.InitColor)
+			groupTx := groupTransactions[groupID]
+			if groupTx != nil {
+				color = groupTx.Color
+			}
+
+			for _, nodeID := range group.NodeIDs {
+				peer := testNetwork.Peer(nodeID)
+				// not yet activated groups (sybils, delayed starts) join the conflict at their activation instead
+				if peer.IsOffline() {
+					continue
+				}
+				// honest node does not implement adversary behavior interface
+				if group.AdversaryType != network.HonestNode {
+					node := adversary.CastAdversary(peer.Node)
+					node.AssignColor(color)
+				}
+				issue := func() { sendMessage(peer, color) }
+				if groupTx != nil {
+					issue = func() { sendTransaction(peer, groupTx) }
+				}
+				if config.DiscreteEvent {
+					issue()
+				} else {
+					go issue()
+				}
+				log.Infof("Peer %d sent double spend msg: %v", peer.ID, color)
+			}
+		}
+	}
+}
+
+func shutdownSimulation() {
+	simulationCancel()
+	dumpingTicker.Stop()
+	dumpFinalRecorder()
+	if config.SimulationTarget == "DS" {
+		writeAttackSummary(fmt.Sprint("attack-", simulationStartTimeStr, ".json"))
+	}
+	simulationWg.Wait()
+}
+
+func dumpFinalRecorder() {
+	fileName := fmt.Sprint("nd-", simulationStartTimeStr, ".csv")
+	file, err := os.Create(path.Join(config.ResultDir, fileName))
+	if err != nil {
+		panic(err)
+	}
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(ndHeader); err != nil {
+		panic(err)
+	}
+
+	for i := 0; i < config.NodesCount; i++ {
+		record := []string{
+			strconv.FormatInt(int64(i), 10),
+			strconv.FormatBool(network.IsAdversary(int(i))),
+			strconv.FormatInt(int64(nodeCounters[i].Get("minConfirmedAccumulatedWeight")), 10),
+			strconv.FormatInt(int64(nodeCounters[i].Get("unconfirmationCount")), 10),
+		}
+		writeLine(writer, record)
+
+		// Flush the writers, or the data will be truncated for high node count
+		writer.Flush()
+	}
+}
+
+func flushWriters(writers []*csv.Writer) {
+	for _, writer := range writers {
+		writer.Flush()
+		err := writer.Error()
+		if err != nil {
+			log.Error(err)
+		}
+	}
+}
+
+func dumpConfig(fileName string) {
+	type Configuration struct {
+		NodesCount, NodesTotalWeight, ParentsCount, TPS, ConsensusMonitorTick, RelevantValidatorWeight, MinDelay, MaxDelay, SlowdownFactor, DoubleSpendDelay, NeighbourCountWS int
+		ZipfParameter, WeakTipsRatio, PacketLoss, DeltaURTS, SimulationStopThreshold, RandomnessWS                                                                             float64
+		ConfirmationThreshold, TSA, ResultDir, IMIF, SimulationTarget, SimulationMode                                                                                          string
+		AdversaryDelays, AdversaryTypes, AdversaryNodeCounts                                                                                                                   []int
+		AdversarySpeedup, AdversaryMana                                                                                                                                        []float64
+		AdversaryInitColor, AccidentalMana                                                                                                                                     []string
+		AdversaryPeeringAll                                                                                                                                                    bool
+	}
+	data := Configuration{
+		NodesCount:              config.NodesCount,
+		NodesTotalWeight:        config.NodesTotalWeight,
+		ZipfParameter:           config.ZipfParameter,
+		ConfirmationThreshold:   fmt.Sprintf("%.2f-%v", config.ConfirmationThreshold, config.ConfirmationThresholdAbsolute),
+		ParentsCount:            config.ParentsCount,
+		WeakTipsRatio:           config.WeakTipsRatio,
+		TSA:                     config.TSA,
+		TPS:                     config.TPS,
+		SlowdownFactor:          config.SlowdownFactor,
+		ConsensusMonitorTick:    config.ConsensusMonitorTick,
+		RelevantValidatorWeight: config.RelevantValidatorWeight,
+		DoubleSpendDelay:        config.DoubleSpendDelay,
+		PacketLoss:              config.PacketLoss,
+		MinDelay:                config.MinDelay,
+		MaxDelay:                config.MaxDelay,
+		DeltaURTS:               config.DeltaURTS,
+		SimulationStopThreshold: config.SimulationStopThreshold,
+		SimulationTarget:        config.SimulationTarget,
+		ResultDir:               config.ResultDir,
+		IMIF:                    config.IMIF,
+		RandomnessWS:            config.RandomnessWS,
+		NeighbourCountWS:        config.NeighbourCountWS,
+		AdversaryTypes:          config.AdversaryTypes,
+		AdversaryDelays:         config.AdversaryDelays,
+		AdversaryMana:           config.AdversaryMana,
+		AdversaryNodeCounts:     config.AdversaryNodeCounts,
+		AdversaryInitColor:      config.AdversaryInitColors,
+		SimulationMode:          config.SimulationMode,
+		AccidentalMana:          config.AccidentalMana,
+		AdversaryPeeringAll:     config.AdversaryPeeringAll,
+		AdversarySpeedup:        config.AdversarySpeedup,
+	}
+
+	bytes, err := json.MarshalIndent(data, "", " ")
+	if err != nil {
+		log.Error(err)
+	}
+	if _, err = os.Stat(config.ResultDir); os.IsNotExist(err) {
+		err = os.Mkdir(config.ResultDir, 0700)
+		if err != nil {
+			log.Error(err)
+		}
+	}
+	if ioutil.WriteFile(path.Join(config.ResultDir, fileName), bytes, 0644) != nil {
+		log.Error(err)
+	}
+}
+
+func dumpNetwork(net *network.Network, fileName string) {
+	nwHeader := []string{"Peer ID", "Neighbor ID", "Network Delay (ns)", "Packet Loss (%)", "Weight"}
+
+	file, err := os.Create(path.Join(config.ResultDir, fileName))
+	if err != nil {
+		panic(err)
+	}
+	writer := csv.NewWriter(file)
+	if err := writer.Write(nwHeader); err != nil {
+		panic(err)
+	}
+
+	for _, peer := range net.Peers {
+		for neighbor, connection := range peer.Neighbors {
+			record := []string{
+				strconv.FormatInt(int64(peer.ID), 10),
+				strconv.FormatInt(int64(neighbor), 10),
+				strconv.FormatInt(connection.NetworkDelay().Nanoseconds(), 10),
+				strconv.FormatInt(int64(connection.PacketLoss()*100), 10),
+				strconv.FormatInt(int64(net.WeightDistribution.Weight(peer.ID)), 10),
+			}
+			writeLine(writer, record)
+		}
+		// Flush the writers, or the data will be truncated for high node count
+		writer.Flush()
+	}
+}
+
+// dumpTopologyStatistics writes the graph statistics of the generated topology to a JSON file, see
+// network.ComputeTopologyStatistics.
+func dumpTopologyStatistics(net *network.Network, fileName string) {
+	statistics := net.ComputeTopologyStatistics()
+
+	bytes, err := json.MarshalIndent(statistics, "", " ")
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	if err := ioutil.WriteFile(path.Join(config.ResultDir, fileName), bytes, 0644); err != nil {
+		log.Error(err)
+	}
+}
+
+// exportsEdge reports whether the peer→neighbor direction represents the link in the topology exports:
+// the lower-ID endpoint does, unless only this direction exists (unidirectional NATed links).
+func exportsEdge(net *network.Network, peerID network.PeerID, neighborID network.PeerID) bool {
+	if peerID < neighborID {
+		return true
+	}
+	_, reverseExists := net.Peers[neighborID].Neighbors[peerID]
+	return !reverseExists
+}
+
+// dumpNetworkGraphML exports the topology as GraphML with the node weights and adversary flags as
+// attributes, so the generated graphs open directly in Gephi. The edge keys match the ones
+// network.TopologyFromFile reads back.
+func dumpNetworkGraphML(net *network.Network, fileName string) {
+	file, err := os.Create(path.Join(config.ResultDir, fileName))
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	fmt.Fprintln(file, `<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Fprintln(file, `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`)
+	fmt.Fprintln(file, ` <key id="weight" for="node" attr.name="weight" attr.type="long"/>`)
+	fmt.Fprintln(file, ` <key id="adversary" for="node" attr.name="adversary" attr.type="boolean"/>`)
+	fmt.Fprintln(file, ` <key id="delayMs" for="edge" attr.name="delayMs" attr.type="double"/>`)
+	fmt.Fprintln(file, ` <key id="packetLoss" for="edge" attr.name="packetLoss" attr.type="double"/>`)
+	fmt.Fprintln(file, ` <graph id="topology" edgedefault="undirected">`)
+	for _, peer := range net.Peers {
+		fmt.Fprintf(file, "  <node id=\"n%d\"><data key=\"weight\">%d</data><data key=\"adversary\">%t</data></node>\n",
+			peer.ID, net.WeightDistribution.Weight(peer.ID), network.IsAdversary(int(peer.ID)))
+	}
+	for _, peer := range net.Peers {
+		for neighborID, connection := range peer.Neighbors {
+			if !exportsEdge(net, peer.ID, neighborID) {
+				continue
+			}
+			fmt.Fprintf(file, "  <edge source=\"n%d\" target=\"n%d\"><data key=\"delayMs\">%.3f</data><data key=\"packetLoss\">%.4f</data></edge>\n",
+				peer.ID, neighborID, float64(connection.NetworkDelay().Nanoseconds())/float64(time.Millisecond), connection.PacketLoss())
+		}
+	}
+	fmt.Fprintln(file, ` </graph>`)
+	fmt.Fprintln(file, `</graphml>`)
+}
+
+// dumpNetworkDOT exports the topology in the Graphviz DOT format, mirroring the GraphML export.
+func dumpNetworkDOT(net *network.Network, fileName string) {
+	file, err := os.Create(path.Join(config.ResultDir, fileName))
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	fmt.Fprintln(file, "graph topology {")
+	for _, peer := range net.Peers {
+		fmt.Fprintf(file, "  n%d [weight=%d, adversary=%t];\n",
+			peer.ID, net.WeightDistribution.Weight(peer.ID), network.IsAdversary(int(peer.ID)))
+	}
+	for _, peer := range net.Peers {
+		for neighborID, connection := range peer.Neighbors {
+			if !exportsEdge(net, peer.ID, neighborID) {
+				continue
+			}
+			fmt.Fprintf(file, "  n%d -- n%d [delayMs=%.3f, packetLoss=%.4f];\n",
+				peer.ID, neighborID, float64(connection.NetworkDelay().Nanoseconds())/float64(time.Millisecond), connection.PacketLoss())
+		}
+	}
+	fmt.Fprintln(file, "}")
+}
+
+func monitorNetworkState(testNetwork *network.Network) (resultsWriters []*csv.Writer) {
+	adversaryNodesCount := len(network.AdversaryNodeIDToGroupIDMap)
+	honestNodesCount := config.NodesCount - adversaryNodesCount
+
+	allColors := append([]multiverse.Color{multiverse.UndefinedColor}, multiverse.ConflictingColors()...)
+	zeroes := make([]int64, len(allColors))
+
+	// all opinions start out at UndefinedColor
+	opinionInitValues := make([]int64, len(allColors))
+	opinionInitValues[0] = int64(config.NodesCount)
+	adversaryOpinionInitValues := make([]int64, len(allColors))
+	adversaryOpinionInitValues[0] = int64(adversaryNodesCount)
+
+	colorCounters.CreateCounter("opinions", allColors, opinionInitValues)
+	colorCounters.CreateCounter("confirmedNodes", allColors, zeroes)
+	colorCounters.CreateCounter("opinionsWeights", allColors, zeroes)
+	colorCounters.CreateCounter("likeAccumulatedWeight", allColors, zeroes)
+	colorCounters.CreateCounter("processedMessages", allColors, zeroes)
+	colorCounters.CreateCounter("requestedMissingMessages", allColors, zeroes)
+	colorCounters.CreateCounter("tipPoolSizes", allColors, zeroes)
+	for _, peer := range testNetwork.Peers {
+		peerID := peer.ID
+		tipCounterName := fmt.Sprint("tipPoolSizes-", peerID)
+		processedCounterName := fmt.Sprint("processedMessages-", peerID)
+		colorCounters.CreateCounter(tipCounterName, allColors, zeroes)
+		colorCounters.CreateCounter(processedCounterName, allColors, zeroes)
+	}
+	colorCounters.CreateCounter("colorUnconfirmed", allColors[1:], zeroes[1:])
+	colorCounters.CreateCounter("confirmedAccumulatedWeight", allColors[1:], zeroes[1:])
+	colorCounters.CreateCounter("unconfirmedAccumulatedWeight", allColors[1:], zeroes[1:])
+
+	adversaryCounters.CreateCounter("likeAccumulatedWeight", allColors[1:], zeroes[1:])
+	adversaryCounters.CreateCounter("opinions", allColors, adversaryOpinionInitValues)
+	adversaryCounters.CreateCounter("confirmedNodes", allColors, zeroes)
+	adversaryCounters.CreateCounter("confirmedAccumulatedWeight", allColors, zeroes)
+
+	// Initialize the minConfirmedWeight to be the max value (i.e., the total weight)
+	for i := 0; i < config.NodesCount; i++ {
+		nodeCounters = append(nodeCounters, *NewAtomicCounters())
+		nodeCounters[i].CreateAtomicCounter("minConfirmedAccumulatedWeight", int64(config.NodesTotalWeight))
+		nodeCounters[i].CreateAtomicCounter("unconfirmationCount", 0)
+	}
+
+	atomicCounters.CreateAtomicCounter("flips", 0)
+	atomicCounters.CreateAtomicCounter("honestFlips", 0)
+	atomicCounters.CreateAtomicCounter("tps", 0)
+	atomicCounters.CreateAtomicCounter("relevantValidators", 0)
+	atomicCounters.CreateAtomicCounter("issuedMessages", 0)
+	atomicCounters.CreateAtomicCounter("adversaryIssuedMessages", 0)
+	atomicCounters.CreateAtomicCounter("eclipseVictimConfirmed", 0)
+	atomicCounters.CreateAtomicCounter("ledgerDoubleSpends", 0)
+	atomicCounters.CreateAtomicCounter("ledgerConfirmedTransactions", 0)
+	for _, peer := range testNetwork.Peers {
+		peerID := peer.ID
+		issuedCounterName := fmt.Sprint("issuedMessages-", peerID)
+		atomicCounters.CreateAtomicCounter(issuedCounterName, 0)
+	}
+
+	mostLikedColor = multiverse.UndefinedColor
+	honestOnlyMostLikedColor = multiverse.UndefinedColor
+
+	// The simulation start time
+	simulationStartTime = now()
+	simulationStartTimeStr = simulationStartTime.UTC().Format(time.RFC3339)
+
+	// Dump the configuration of this simulation
+	print("dumping to file")
+	dumpConfig(fmt.Sprint("aw-", simulationStartTimeStr, ".config"))
+
+	// Dump the network information
+	dumpNetwork(testNetwork, fmt.Sprint("nw-", simulationStartTimeStr, ".csv"))
+
+	// Export the topology for Gephi/Graphviz figures
+	dumpNetworkGraphML(testNetwork, fmt.Sprint("nw-", simulationStartTimeStr, ".graphml"))
+	dumpNetworkDOT(testNetwork, fmt.Sprint("nw-", simulationStartTimeStr, ".dot"))
+
+	// Dump the graph statistics of the generated topology
+	dumpTopologyStatistics(testNetwork, fmt.Sprint("topo-", simulationStartTimeStr, ".json"))
+
+	// Dump the info about adversary nodes
+	adResultsWriter := createWriter(fmt.Sprintf("ad-%s.csv", simulationStartTimeStr), adHeader, &resultsWriters)
+	dumpResultsAD(adResultsWriter, testNetwork)
+
+	// Dump the double spending result
+	dsResultsWriter := createWriter(fmt.Sprintf("ds-%s.csv", simulationStartTimeStr), dsHeader(), &resultsWriters)
+
+	// Dump the tip pool and processed message (throughput) results
+	tpResultsWriter := createWriter(fmt.Sprintf("tp-%s.csv", simulationStartTimeStr), tpHeader(), &resultsWriters)
+
+	// Dump the requested missing message result
+	mmResultsWriter := createWriter(fmt.Sprintf("mm-%s.csv", simulationStartTimeStr), mmHeader, &resultsWriters)
+
+	// Dump the duplicate statistics of the configured gossip mode
+	gsResultsWriter := createWriter(fmt.Sprintf("gs-%s.csv", simulationStartTimeStr), gsHeader, &resultsWriters)
+
+	// Dump the duplicate message copies received per peer, the per-node redundancy cost of the gossip
+	dupHeader := make([]string, 0, config.NodesCount+1)
+	for i := 0; i < config.NodesCount; i++ {
+		dupHeader = append(dupHeader, fmt.Sprintf("Node %d", i))
+	}
+	dupHeader = append(dupHeader, "ns since start")
+	dupResultsWriter := createWriter(fmt.Sprintf("dup-%s.csv", simulationStartTimeStr), dupHeader, &resultsWriters)
+
+	// Dump the outbound queue lengths of the bandwidth-limited connections
+	var qlResultsWriter *csv.Writer
+	if config.BandwidthMsgPerSec > 0 {
+		qlResultsWriter = createWriter(fmt.Sprintf("ql-%s.csv", simulationStartTimeStr), qlHeader, &resultsWriters)
+	}
+
+	// Dump the per-issuer orphanage quantifying the success of the censorship adversary
+	var csResultsWriter *csv.Writer
+	if len(config.AdversaryCensoredIssuers) > 0 {
+		csResultsWriter = createWriter(fmt.Sprintf("cs-%s.csv", simulationStartTimeStr), csHeader, &resultsWriters)
+	}
+
+	// Dump the orphan rates when an orphanage attack group is configured, the RURTS age restriction
+	// can orphan old tips or the reporting is requested explicitly
+	var orResultsWriter *csv.Writer
+	if adversaryTypeConfigured(network.Orphanage) || config.TSA == "RURTS" || config.OrphanageReporting {
+		orResultsWriter = createWriter(fmt.Sprintf("or-%s.csv", simulationStartTimeStr), orHeader, &resultsWriters)
+	}
+
+	// Dump the honest approval weight captured by the parasitic chains
+	var pcResultsWriter *csv.Writer
+	if adversaryTypeConfigured(network.Parasite) {
+		pcResultsWriter = createWriter(fmt.Sprintf("pc-%s.csv", simulationStartTimeStr), pcHeader, &resultsWriters)
+	}
+
+	// Dump the budget the adversary actions have consumed
+	var abResultsWriter *csv.Writer
+	if config.AdversaryBudget > 0 {
+		abResultsWriter = createWriter(fmt.Sprintf("ab-%s.csv", simulationStartTimeStr), abHeader, &resultsWriters)
+	}
+
+	// Dump the per-node detection counters of the attacker-detection heuristics
+	var dtResultsWriter *csv.Writer
+	if config.DetectionMetrics {
+		dtResultsWriter = createWriter(fmt.Sprintf("dt-%s.csv", simulationStartTimeStr), dtHeader, &resultsWriters)
+	}
+
+	// Dump the outbox queue lengths and queueing delays of the nodes' schedulers
+	var scResultsWriter *csv.Writer
+	if config.SchedulerEnabled {
+		scResultsWriter = createWriter(fmt.Sprintf("sc-%s.csv", simulationStartTimeStr), scHeader, &resultsWriters)
+	}
+
+	// Dump the per-node issuance rates of the AIMD rate setter
+	var rtResultsWriter *csv.Writer
+	if config.RateSetterEnabled {
+		rtResultsWriter = createWriter(fmt.Sprintf("rt-%s.csv", simulationStartTimeStr), rtHeader, &resultsWriters)
+	}
+
+	// Dump the divergence of the per-epoch commitments across the nodes
+	var epResultsWriter *csv.Writer
+	if config.EpochCommitments {
+		epResultsWriter = createWriter(fmt.Sprintf("ep-%s.csv", simulationStartTimeStr), epHeader, &resultsWriters)
+	}
+
+	// Dump the eclipse victim's opinion and confirmation divergence
+	var ecResultsWriter *csv.Writer
+	if config.EclipseVictim >= 0 {
+		ecResultsWriter = createWriter(fmt.Sprintf("ec-%s.csv", simulationStartTimeStr), ecHeader, &resultsWriters)
+		victimPeer := testNetwork.Peers[config.EclipseVictim]
+		victimPeer.Node.(multiverse.NodeInterface).Tangle().ApprovalManager.Events.MessageConfirmed.Attach(
+			events.NewClosure(func(message *multiverse.Message, messageMetadata *multiverse.MessageMetadata, weight uint64, messageIDCounter int64) {
+				atomicCounters.Add("eclipseVictimConfirmed", 1)
+			}))
+	}
+
+	tpAllHeader := make([]string, 0, config.NodesCount+1)
+
+	for i := 0; i < config.NodesCount; i++ {
+		header := []string{fmt.Sprintf("Node %d", i)}
+		// fmt.Sprintf("Blue (Tip Pool Size) %d", i),
+		// fmt.Sprintf("Red (Tip Pool Size) %d", i),
+		// fmt.Sprintf("Green (Tip Pool Size) %d", i),
+		// fmt.Sprintf("UndefinedColor (Processed) %d", i),
+		// fmt.Sprintf("Blue (Processed) %d", i),
+		// fmt.Sprintf("Red (Processed) %d", i),
+		// fmt.Sprintf("Green (Processed) %d", i),
+		// fmt.Sprintf("# of Issued Messages %d", i)}
+		tpAllHeader = append(tpAllHeader, header...)
+	}
+	header := []string{fmt.Sprintf("ns since start")}
+	tpAllHeader = append(tpAllHeader, header...)
+
+	// Dump the tip pool and processed message (throughput) results
+	tpAllResultsWriter := createWriter(fmt.Sprintf("all-tp-%s.csv", simulationStartTimeStr), tpAllHeader, &resultsWriters)
+
+	// Dump the info about how many nodes have confirmed and liked a certain color
+	ccResultsWriter := createWriter(fmt.Sprintf("cc-%s.csv", simulationStartTimeStr), ccHeader(), &resultsWriters)
+
+	// Define the file name of the ww results
+	wwResultsWriter := createWriter(fmt.Sprintf("ww-%s.csv", simulationStartTimeStr), wwHeader, &resultsWriters)
+
+	// Dump the Witness Weight
+	wwPeer := testNetwork.Peers[config.MonitoredWitnessWeightPeer]
+	previousWitnessWeight := uint64(config.NodesTotalWeight)
+	wwPeer.Node.(multiverse.NodeInterface).Tangle().ApprovalManager.Events.MessageWitnessWeightUpdated.Attach(
+		events.NewClosure(func(message *multiverse.Message, weight uint64) {
+			if !metricsWarmupElapsed() {
+				return
+			}
+			if uint64(previousWitnessWeight) == weight {
+				return
+			}
+			previousWitnessWeight = weight
+			record := []string{
+				strconv.FormatUint(weight, 10),
+				strconv.FormatInt(time.Since(message.IssuanceTime).Nanoseconds(), 10),
+			}
+			csvMutex.Lock()
+			if err := wwResultsWriter.Write(record); err != nil {
+				log.Fatal("error writing record to csv:", err)
+			}
+
+			if err := wwResultsWriter.Error(); err != nil {
+				log.Fatal(err)
+			}
+			csvMutex.Unlock()
+		}))
+
+	for _, id := range config.MonitoredAWPeers {
+		awPeer := testNetwork.Peers[id]
+		if typeutils.IsInterfaceNil(awPeer) {
+			panic(fmt.Sprintf("unknowm peer with id %d", id))
+		}
+		// Define the file name of the aw results
+		awResultsWriter := createWriter(fmt.Sprintf("aw%d-%s.csv", id, simulationStartTimeStr), awHeader, &resultsWriters)
+
+		awPeer.Node.(multiverse.NodeInterface).Tangle().ApprovalManager.Events.MessageConfirmed.Attach(
+			events.NewClosure(func(message *multiverse.Message, messageMetadata *multiverse.MessageMetadata, weight uint64, messageIDCounter int64) {
+				if !metricsWarmupElapsed() {
+					return
+				}
+				confirmedMessageMutex.Lock()
+				confirmedMessageCounter[awPeer.ID]++
+				confirmedMessageMutex.Unlock()
+			}))
+
+		// one aw row per grade crossing, so the per-grade confirmation latencies of a message appear as
+		// consecutive rows with the same message ID
+		awPeer.Node.(multiverse.NodeInterface).Tangle().ApprovalManager.Events.MessageGraded.Attach(
+			events.NewClosure(func(message *multiverse.Message, messageMetadata *multiverse.MessageMetadata, grade int, weight uint64, messageIDCounter int64) {
+				if !metricsWarmupElapsed() {
+					return
+				}
+				var p uint64
+				for s := range message.StrongParents {
+					p = uint64(s)
+				}
+
+				confirmedMessageMutex.RLock()
+				record := []string{
+					strconv.FormatInt(int64(message.ID), 10),
+					strconv.FormatInt(message.IssuanceTime.Unix(), 10),
+					strconv.Itoa(grade),
+					strconv.FormatInt(int64(messageMetadata.GradeTime(grade).Sub(message.IssuanceTime)), 10),
+					strconv.FormatUint(p, 10),
+					strconv.FormatInt(confirmedMessageCounter[awPeer.ID], 10),
+					strconv.FormatInt(messageIDCounter, 10),
+					strconv.FormatInt(sinceStart(simulationStartTime).Nanoseconds(), 10),
+				}
+				confirmedMessageMutex.RUnlock()
+
+				csvMutex.Lock()
+				if err := awResultsWriter.Write(record); err != nil {
+					log.Fatal("error writing record to csv:", err)
+				}
+
+				if err := awResultsWriter.Error(); err != nil {
+					log.Fatal(err)
+				}
+				csvMutex.Unlock()
+			}))
+	}
+
+	for _, peer := range testNetwork.Peers {
+		peerID := peer.ID
+
+		peer.Node.(multiverse.NodeInterface).Tangle().OpinionManager.Events().OpinionChanged.Attach(events.NewClosure(func(oldOpinion multiverse.Color, newOpinion multiverse.Color, weight int64) {
+			colorCounters.Add("opinions", -1, oldOpinion)
+			colorCounters.Add("opinions", 1, newOpinion)
+
+			colorCounters.Add("likeAccumulatedWeight", -weight, oldOpinion)
+			colorCounters.Add("likeAccumulatedWeight", weight, newOpinion)
+
+			likes := getLikesPerColor(colorCounters, "opinions")
+			if mostLikedColorChanged(likes, &mostLikedColor) {
+				atomicCounters.Add("flips", 1)
+			}
+			if network.IsAdversary(int(peerID)) {
+				adversaryCounters.Add("likeAccumulatedWeight", -weight, oldOpinion)
+				adversaryCounters.Add("likeAccumulatedWeight", weight, newOpinion)
+				adversaryCounters.Add("opinions", -1, oldOpinion)
+				adversaryCounters.Add("opinions", 1, newOpinion)
+			}
+
+			adversaryLikes := getLikesPerColor(adversaryCounters, "opinions")
+			honestLikes := make([]int64, len(likes))
+			for i := range likes {
+				honestLikes[i] = likes[i] - adversaryLikes[i]
+			}
+			// honest nodes likes status only, flips
+			if mostLikedColorChanged(honestLikes, &honestOnlyMostLikedColor) {
+				atomicCounters.Add("honestFlips", 1)
+			}
+		}))
+		peer.Node.(multiverse.NodeInterface).Tangle().OpinionManager.Events().ColorConfirmed.Attach(events.NewClosure(func(confirmedColor multiverse.Color, weight int64) {
+			colorCounters.Add("confirmedNodes", 1, confirmedColor)
+			colorCounters.Add("confirmedAccumulatedWeight", weight, confirmedColor)
+			if network.IsAdversary(int(peerID)) {
+				adversaryCounters.Add("confirmedNodes", 1, confirmedColor)
+				adversaryCounters.Add("confirmedAccumulatedWeight", weight, confirmedColor)
+			} else {
+				recordHonestColorConfirmation(confirmedColor)
+			}
+		}))
+
+		if config.LedgerEnabled {
+			peer.Node.(multiverse.NodeInterface).Tangle().Ledger.Events.DoubleSpendDetected.Attach(events.NewClosure(func(output multiverse.OutputID, spender multiverse.TransactionID) {
+				atomicCounters.Add("ledgerDoubleSpends", 1)
+			}))
+			peer.Node.(multiverse.NodeInterface).Tangle().Ledger.Events.TransactionConfirmed.Attach(events.NewClosure(func(transactionID multiverse.TransactionID) {
+				atomicCounters.Add("ledgerConfirmedTransactions", 1)
+			}))
+		}
+
+		peer.Node.(multiverse.NodeInterface).Tangle().OpinionManager.Events().ColorUnconfirmed.Attach(events.NewClosure(func(unconfirmedColor multiverse.Color, unconfirmedSupport int64, weight int64) {
+			colorCounters.Add("colorUnconfirmed", 1, unconfirmedColor)
+			colorCounters.Add("confirmedNodes", -1, unconfirmedColor)
+
+			colorCounters.Add("unconfirmedAccumulatedWeight", weight, unconfirmedColor)
+			colorCounters.Add("confirmedAccumulatedWeight", -weight, unconfirmedColor)
+
+			// When the color is unconfirmed, the min confirmed accumulated weight should be reset
+			nodeCounters[int(peerID)].Set("minConfirmedAccumulatedWeight", int64(config.NodesTotalWeight))
+
+			// Accumulate the unconfirmed count for each node
+			nodeCounters[int(peerID)].Add("unconfirmationCount", 1)
+		}))
+
+		// We want to know how deep the support for our once confirmed color could fall
+		peer.Node.(multiverse.NodeInterface).Tangle().OpinionManager.Events().MinConfirmedWeightUpdated.Attach(events.NewClosure(func(opinion multiverse.Color, confirmedWeight int64) {
+			if nodeCounters[int(peerID)].Get("minConfirmedAccumulatedWeight") > confirmedWeight {
+				nodeCounters[int(peerID)].Set("minConfirmedAccumulatedWeight", confirmedWeight)
+			}
+		}))
+	}
+
+	// Here we only monitor the opinion weight of node w/ the highest weight
+	dsPeer := testNetwork.Peers[0]
+	dsPeer.Node.(multiverse.NodeInterface).Tangle().OpinionManager.Events().ApprovalWeightUpdated.Attach(events.NewClosure(func(opinion multiverse.Color, deltaWeight int64) {
+		colorCounters.Add("opinionsWeights", deltaWeight, opinion)
+	}))
+
+	// Here we only monitor the tip pool size of node w/ the highest weight
+	peer := testNetwork.Peers[0]
+	peer.Node.(multiverse.NodeInterface).Tangle().TipManager.Events.MessageProcessed.Attach(events.NewClosure(
+		func(opinion multiverse.Color, tipPoolSize int, processedMessages uint64, issuedMessages int64) {
+			colorCounters.Set("tipPoolSizes", int64(tipPoolSize), opinion)
+			colorCounters.Set("processedMessages", int64(processedMessages), opinion)
+
+			atomicCounters.Set("issuedMessages", issuedMessages)
+		}))
+	peer.Node.(multiverse.NodeInterface).Tangle().Requester.Events.Request.Attach(events.NewClosure(
+		func(messageID multiverse.MessageID) {
+			colorCounters.Add("requestedMissingMessages", int64(1), multiverse.UndefinedColor)
+		}))
+
+	for _, peer := range testNetwork.Peers {
+		peerID := peer.ID
+		tipCounterName := fmt.Sprint("tipPoolSizes-", peerID)
+		processedCounterName := fmt.Sprint("processedMessages-", peerID)
+		issuedCounterName := fmt.Sprint("issuedMessages-", peerID)
+		peer.Node.(multiverse.NodeInterface).Tangle().TipManager.Events.MessageProcessed.Attach(events.NewClosure(
+			func(opinion multiverse.Color, tipPoolSize int, processedMessages uint64, issuedMessages int64) {
+				colorCounters.Set(tipCounterName, int64(tipPoolSize), opinion)
+				colorCounters.Set(processedCounterName, int64(processedMessages), opinion)
+				atomicCounters.Set(issuedCounterName, issuedMessages)
+			}))
+	}
+
+	if config.DiscreteEvent {
+		monitorTick := time.Duration(config.ConsensusMonitorTick) * time.Millisecond
+		var scheduleDump func()
+		scheduleDump = func() {
+			clock.Virtual.ExecuteAfter(monitorTick, func() {
+				dumpRecords(dsResultsWriter, tpResultsWriter, ccResultsWriter, adResultsWriter, tpAllResultsWriter, mmResultsWriter, honestNodesCount, adversaryNodesCount)
+				updateAttackSummary()
+				dumpResultsGS(gsResultsWriter)
+				dumpResultsDup(dupResultsWriter, testNetwork)
+				if qlResultsWriter != nil {
+					dumpResultsQL(qlResultsWriter, testNetwork)
+				}
+				if csResultsWriter != nil {
+					dumpResultsCS(csResultsWriter, testNetwork)
+				}
+				if orResultsWriter != nil {
+					dumpResultsOR(orResultsWriter, testNetwork)
+				}
+				if pcResultsWriter != nil {
+					dumpResultsPC(pcResultsWriter, testNetwork)
+				}
+				if abResultsWriter != nil {
+					dumpResultsAB(abResultsWriter)
+				}
+				if dtResultsWriter != nil {
+					dumpResultsDT(dtResultsWriter, testNetwork)
+				}
+				if scResultsWriter != nil {
+					dumpResultsSC(scResultsWriter, testNetwork)
+				}
+				if rtResultsWriter != nil {
+					dumpResultsRT(rtResultsWriter, testNetwork)
+				}
+				if epResultsWriter != nil {
+					dumpResultsEP(epResultsWriter, testNetwork)
+				}
+				if ecResultsWriter != nil {
+					dumpResultsEC(ecResultsWriter, testNetwork)
+				}
+				scheduleDump()
+			})
+		}
+		scheduleDump()
+	} else {
+		go func() {
+			for {
+				select {
+				case <-simulationCtx.Done():
+					return
+				case <-dumpingTicker.C:
+					dumpRecords(dsResultsWriter, tpResultsWriter, ccResultsWriter, adResultsWriter, tpAllResultsWriter, mmResultsWriter, honestNodesCount, adversaryNodesCount)
+					updateAttackSummary()
+					dumpResultsGS(gsResultsWriter)
+					dumpResultsDup(dupResultsWriter, testNetwork)
+					if qlResultsWriter != nil {
+						dumpResultsQL(qlResultsWriter, testNetwork)
+					}
+					if csResultsWriter != nil {
+						dumpResultsCS(csResultsWriter, testNetwork)
+					}
+					if orResultsWriter != nil {
+						dumpResultsOR(orResultsWriter, testNetwork)
+					}
+					if pcResultsWriter != nil {
+						dumpResultsPC(pcResultsWriter, testNetwork)
+					}
+					if abResultsWriter != nil {
+						dumpResultsAB(abResultsWriter)
+					}
+					if dtResultsWriter != nil {
+						dumpResultsDT(dtResultsWriter, testNetwork)
+					}
+					if scResultsWriter != nil {
+						dumpResultsSC(scResultsWriter, testNetwork)
+					}
+					if rtResultsWriter != nil {
+						dumpResultsRT(rtResultsWriter, testNetwork)
+					}
+					if epResultsWriter != nil {
+						dumpResultsEP(epResultsWriter, testNetwork)
+					}
+					if ecResultsWriter != nil {
+						dumpResultsEC(ecResultsWriter, testNetwork)
+					}
+				}
+			}
+		}()
+	}
+
+	return
+}
+
+func dumpRecords(dsResultsWriter *csv.Writer, tpResultsWriter *csv.Writer, ccResultsWriter *csv.Writer, adResultsWriter *csv.Writer, tpAllResultsWriter *csv.Writer, mmResultsWriter *csv.Writer, honestNodesCount int, adversaryNodesCount int) {
+	simulationWg.Add(1)
+	simulationWg.Done()
+
+	waitWhilePaused()
+
+	// During the warm-up the counters keep accumulating but nothing is dumped, so the result files only
+	// cover the steady state
+	if !metricsWarmupElapsed() {
+		atomicCounters.Set("tps", 0)
+		return
+	}
+
+	log.Infof("New opinions counter[ %s ]", colorStatus("opinions"))
+	log.Infof("Network Status: %3d TPS :: Consensus[ %s ] :: %d  Honest Nodes :: %d Adversary Nodes :: %d Validators",
+		atomicCounters.Get("tps")*1000/int64(config.ConsensusMonitorTick),
+		colorStatus("confirmedNodes"),
+		honestNodesCount,
+		adversaryNodesCount,
+		atomicCounters.Get("relevantValidators"),
+	)
+	if config.LedgerEnabled {
+		log.Infof("UTXO ledger: %d double spend detections / %d transaction confirmations across all nodes",
+			atomicCounters.Get("ledgerDoubleSpends"), atomicCounters.Get("ledgerConfirmedTransactions"))
+	}
+
+	sinceIssuance := "0"
+	if !dsIssuanceTime.IsZero() {
+		sinceIssuance = strconv.FormatInt(sinceStart(dsIssuanceTime).Nanoseconds(), 10)
+
+	}
+
+	reportProgress(honestNodesCount)
+
+	dumpResultDS(dsResultsWriter, sinceIssuance)
+	dumpResultsTP(tpResultsWriter)
+	dumpResultsTPAll(tpAllResultsWriter)
+	dumpResultsCC(ccResultsWriter, sinceIssuance)
+	dumpResultsMM(mmResultsWriter)
+
+	// determines whether the simulation is over; see stopcondition.go for the registry
+	if name, met := stopConditionMet(honestNodesCount); met {
+		log.Infof("Stop condition %s met", name)
+		if name == "consensus" || name == "adversarySuccess" {
+			consensusReached = true
+		}
+		if config.DiscreteEvent {
+			clock.Virtual.Stop()
+		} else {
+			shutdownSignal <- types.Void
+		}
+	}
+	atomicCounters.Set("tps", 0)
+}
+
+// appendColorValues appends the counter value of every conflicting color to the record, in header order.
+func appendColorValues(record []string, counter *ColorCounters, flag string) []string {
+	for _, color := range multiverse.ConflictingColors() {
+		record = append(record, strconv.FormatInt(counter.Get(flag, color), 10))
+	}
+	return record
+}
+
+func dumpResultDS(dsResultsWriter *csv.Writer, sinceIssuance string) {
+	// Dump the double spending results
+	record := []string{
+		strconv.FormatInt(colorCounters.Get("opinionsWeights", multiverse.UndefinedColor), 10),
+	}
+	record = appendColorValues(record, colorCounters, "opinionsWeights")
+	record = append(record,
+		strconv.FormatInt(sinceStart(simulationStartTime).Nanoseconds(), 10),
+		sinceIssuance,
+	)
+
+	writeLine(dsResultsWriter, record)
+
+	// Flush the writers, or the data will be truncated sometimes if the buffer is full
+	dsResultsWriter.Flush()
+}
+
+func dumpResultsTP(tpResultsWriter *csv.Writer) {
+	// Dump the tip pool sizes
+	record := []string{
+		strconv.FormatInt(colorCounters.Get("tipPoolSizes", multiverse.UndefinedColor), 10),
+	}
+	record = appendColorValues(record, colorCounters, "tipPoolSizes")
+	record = append(record, strconv.FormatInt(colorCounters.Get("processedMessages", multiverse.UndefinedColor), 10))
+	record = appendColorValues(record, colorCounters, "processedMessages")
+	record = append(record,
+		strconv.FormatInt(atomicCounters.Get("issuedMessages"), 10),
+		strconv.FormatInt(multiverse.WeakReferenceStatistics(), 10),
+		strconv.FormatInt(sinceStart(simulationStartTime).Nanoseconds(), 10),
+	)
+
+	writeLine(tpResultsWriter, record)
+
+	// Flush the writers, or the data will be truncated sometimes if the buffer is full
+	tpResultsWriter.Flush()
+}
+
+func dumpResultsTPAll(tpAllResultsWriter *csv.Writer) {
+	record := make([]string, config.NodesCount+1)
+	i := 0
+	for peerID := 0; peerID < config.NodesCount; peerID++ {
+		tipCounterName := fmt.Sprint("tipPoolSizes-", peerID)
+		// processedCounterName := fmt.Sprint("processedMessages-", peerID)
+		// issuedCounterName := fmt.Sprint("issuedMessages-", peerID)
+		record[i+0] = strconv.FormatInt(colorCounters.Get(tipCounterName, multiverse.UndefinedColor), 10)
+		// record[i+1] = strconv.FormatInt(colorCounters.Get(tipCounterName, multiverse.Blue), 10)
+		// record[i+2] = strconv.FormatInt(colorCounters.Get(tipCounterName, multiverse.Red), 10)
+		// record[i+3] = strconv.FormatInt(colorCounters.Get(tipCounterName, multiverse.Green), 10)
+		// record[i+4] = strconv.FormatInt(colorCounters.Get(processedCounterName, multiverse.UndefinedColor), 10)
+		// record[i+5] = strconv.FormatInt(colorCounters.Get(processedCounterName, multiverse.Blue), 10)
+		// record[i+6] = strconv.FormatInt(colorCounters.Get(processedCounterName, multiverse.Red), 10)
+		// record[i+7] = strconv.FormatInt(colorCounters.Get(processedCounterName, multiverse.Green), 10)
+		// record[i+8] = strconv.FormatInt(atomicCounters.Get(issuedCounterName), 10)
+		// record[i+9] = strconv.FormatInt(sinceStart(simulationStartTime).Nanoseconds(), 10)
+		i = i + 1
+	}
+	record[i] = strconv.FormatInt(sinceStart(simulationStartTime).Nanoseconds(), 10)
+
+	writeLine(tpAllResultsWriter, record)
+
+	// Flush the writers, or the data will be truncated sometimes if the buffer is full
+	tpAllResultsWriter.Flush()
+}
+
+// dumpResultsQL dumps the total and maximum outbound queue length across the peers, see
+// Peer.OutboundQueueLength.
+func dumpResultsQL(qlResultsWriter *csv.Writer, testNetwork *network.Network) {
+	if !metricsWarmupElapsed() {
+		return
+	}
+
+	totalQueueLength, maxQueueLength := 0, 0
+	for _, peer := range testNetwork.Peers {
+		queueLength := peer.OutboundQueueLength()
+		totalQueueLength += queueLength
+		if queueLength > maxQueueLength {
+			maxQueueLength = queueLength
+		}
+	}
+
+	record := []string{
+		strconv.Itoa(totalQueueLength),
+		strconv.Itoa(maxQueueLength),
+		strconv.FormatInt(sinceStart(simulationStartTime).Nanoseconds(), 10),
+	}
+
+	writeLine(qlResultsWriter, record)
+	qlResultsWriter.Flush()
+}
+
+// dumpResultsDup dumps the cumulative number of duplicate message copies every peer received, see
+// network.Peer.DuplicateDeliveries.
+func dumpResultsDup(dupResultsWriter *csv.Writer, testNetwork *network.Network) {
+	if !metricsWarmupElapsed() {
+		return
+	}
+
+	record := make([]string, 0, config.NodesCount+1)
+	for _, peer := range testNetwork.Peers {
+		record = append(record, strconv.FormatInt(peer.DuplicateDeliveries(), 10))
+	}
+	record = append(record, strconv.FormatInt(sinceStart(simulationStartTime).Nanoseconds(), 10))
+
+	writeLine(dupResultsWriter, record)
+	dupResultsWriter.Flush()
+}
+
+// censorshipMaturityAge is how old a message has to be before the censorship metric counts it as
+// orphaned when it is still unreferenced; younger messages may simply still be tips.
+const censorshipMaturityAge = 10 * time.Second
+
+// dumpResultsCS dumps the per-issuer orphanage as seen by the first monitored peer, see
+// multiverse.Storage.IssuerOrphanage. Censored issuers whose messages stay unreferenced show up with
+// an orphaned count approaching their issued count.
+func dumpResultsCS(csResultsWriter *csv.Writer, testNetwork *network.Network) {
+	if !metricsWarmupElapsed() {
+		return
+	}
+
+	monitoredPeer := testNetwork.Peer(config.MonitoredAWPeers[0])
+	issued, orphaned := monitoredPeer.Node.(multiverse.NodeInterface).Tangle().Storage.IssuerOrphanage(censorshipMaturityAge)
+	for _, issuerID := range config.AdversaryCensoredIssuers {
+		record := []string{
+			strconv.Itoa(issuerID),
+			strconv.Itoa(issued[network.PeerID(issuerID)]),
+			strconv.Itoa(orphaned[network.PeerID(issuerID)]),
+			strconv.FormatInt(sinceStart(simulationStartTime).Nanoseconds(), 10),
+		}
+		writeLine(csResultsWriter, record)
+	}
+	csResultsWriter.Flush()
+}
+
+// dumpResultsPC dumps how much honest approval weight the parasitic chains have captured in the
+// first monitored peer's tangle, see multiverse.Storage.CapturedApprovalWeight.
+func dumpResultsPC(pcResultsWriter *csv.Writer, testNetwork *network.Network) {
+	if !metricsWarmupElapsed() {
+		return
+	}
+
+	parasiteIssuers := make(map[network.PeerID]types.Empty)
+	for _, group := range testNetwork.AdversaryGroups {
+		if group.AdversaryType != network.Parasite {
+			continue
+		}
+		for _, nodeID := range group.NodeIDs {
+			parasiteIssuers[network.PeerID(nodeID)] = types.Void
+		}
+	}
+
+	monitoredPeer := testNetwork.Peer(config.MonitoredAWPeers[0])
+	parasiticMessages, capturingApprovers, capturedWeight := monitoredPeer.Node.(multiverse.NodeInterface).Tangle().Storage.CapturedApprovalWeight(parasiteIssuers)
+	record := []string{
+		strconv.Itoa(parasiticMessages),
+		strconv.Itoa(len(capturingApprovers)),
+		strconv.FormatUint(capturedWeight, 10),
+		strconv.FormatInt(sinceStart(simulationStartTime).Nanoseconds(), 10),
+	}
+
+	writeLine(pcResultsWriter, record)
+	pcResultsWriter.Flush()
+}
+
+// dumpResultsAB dumps the budget the adversary actions have consumed so far, split by action kind,
+// see adversary.BudgetSpent.
+func dumpResultsAB(abResultsWriter *csv.Writer) {
+	if !metricsWarmupElapsed() {
+		return
+	}
+
+	messages, releases, connections, total := adversary.BudgetSpent()
+	record := []string{
+		strconv.FormatFloat(messages, 'f', 2, 64),
+		strconv.FormatFloat(releases, 'f', 2, 64),
+		strconv.FormatFloat(connections, 'f', 2, 64),
+		strconv.FormatFloat(total, 'f', 2, 64),
+		strconv.FormatFloat(config.AdversaryBudget-total, 'f', 2, 64),
+		strconv.FormatInt(sinceStart(simulationStartTime).Nanoseconds(), 10),
+	}
+
+	writeLine(abResultsWriter, record)
+	abResultsWriter.Flush()
+}
+
+// dumpResultsDT dumps the detection counters every node has accumulated so far, one row per node, see
+// multiverse.Detector.
+func dumpResultsDT(dtResultsWriter *csv.Writer, testNetwork *network.Network) {
+	if !metricsWarmupElapsed() {
+		return
+	}
+
+	for _, peer := range testNetwork.Peers {
+		equivocations, overRateMessages, conflictingTimestamps := peer.Node.(multiverse.NodeInterface).Tangle().Detector.Counters()
+		record := []string{
+			strconv.FormatInt(int64(peer.ID), 10),
+			strconv.FormatInt(equivocations, 10),
+			strconv.FormatInt(overRateMessages, 10),
+			strconv.FormatInt(conflictingTimestamps, 10),
+			strconv.FormatInt(sinceStart(simulationStartTime).Nanoseconds(), 10),
+		}
+		writeLine(dtResultsWriter, record)
+	}
+	dtResultsWriter.Flush()
+}
+
+// dumpResultsSC dumps the outbox queue lengths and the mean queueing delay of every node's
+// scheduler, one row per node, see multiverse.Scheduler.
+func dumpResultsSC(scResultsWriter *csv.Writer, testNetwork *network.Network) {
+	if !metricsWarmupElapsed() {
+		return
+	}
+
+	for _, peer := range testNetwork.Peers {
+		scheduler := peer.Node.(multiverse.NodeInterface).Tangle().Scheduler
+		queuedMessages, longestQueue := scheduler.QueueStatistics()
+		scheduledMessages, cumulativeDelay := scheduler.DelayStatistics()
+
+		meanDelay := 0.0
+		if scheduledMessages > 0 {
+			meanDelay = float64(cumulativeDelay.Milliseconds()) / float64(scheduledMessages)
+		}
+
+		record := []string{
+			strconv.FormatInt(int64(peer.ID), 10),
+			strconv.Itoa(queuedMessages),
+			strconv.Itoa(longestQueue),
+			strconv.FormatInt(scheduledMessages, 10),
+			strconv.FormatFloat(meanDelay, 'f', 4, 64),
+			strconv.FormatInt(sinceStart(simulationStartTime).Nanoseconds(), 10),
+		}
+		writeLine(scResultsWriter, record)
+	}
+	scResultsWriter.Flush()
+}
+
+// dumpResultsRT dumps the current AIMD rate factor, the resulting issuance rate and the tip pool
+// size of every honest node, one row per node, see startRateSetterWorker.
+func dumpResultsRT(rtResultsWriter *csv.Writer, testNetwork *network.Network) {
+	if !metricsWarmupElapsed() {
+		return
+	}
+
+	for _, peer := range testNetwork.Peers {
+		if network.IsAdversary(int(peer.ID)) {
+			continue
+		}
+
+		factor := rateSetterFactor(peer.ID)
+		record := []string{
+			strconv.FormatInt(int64(peer.ID), 10),
+			strconv.FormatFloat(factor, 'f', 4, 64),
+			strconv.FormatFloat(factor*issuanceBand(peer.ID), 'f', 4, 64),
+			strconv.Itoa(peer.Node.(multiverse.NodeInterface).Tangle().TipManager.TipPoolSize()),
+			strconv.FormatInt(sinceStart(simulationStartTime).Nanoseconds(), 10),
+		}
+		writeLine(rtResultsWriter, record)
+	}
+	rtResultsWriter.Flush()
+}
+
+// dumpResultsEP dumps, for every sealed epoch, how far the nodes' epoch commitments diverge: the
+// number of distinct commitments and the share of nodes behind the most common one, see
+// multiverse.EpochManager. Re-dumping the older epochs every tick shows how the divergence heals once
+// the nodes have reconciled their tangles.
+func dumpResultsEP(epResultsWriter *csv.Writer, testNetwork *network.Network) {
+	if !metricsWarmupElapsed() {
+		return
+	}
+
+	for epoch := multiverse.EpochIndex(0); epoch < multiverse.CurrentEpoch(); epoch++ {
+		commitmentCounts := make(map[uint64]int)
+		for _, peer := range testNetwork.Peers {
+			commitmentCounts[peer.Node.(multiverse.NodeInterface).Tangle().EpochManager.Commitment(epoch)]++
+		}
+
+		majority := 0
+		for _, count := range commitmentCounts {
+			if count > majority {
+				majority = count
+			}
+		}
+
+		record := []string{
+			strconv.FormatInt(int64(epoch), 10),
+			strconv.Itoa(len(commitmentCounts)),
+			strconv.FormatFloat(float64(majority)/float64(len(testNetwork.Peers)), 'f', 4, 64),
+			strconv.FormatInt(sinceStart(simulationStartTime).Nanoseconds(), 10),
+		}
+		writeLine(epResultsWriter, record)
+	}
+	epResultsWriter.Flush()
+}
+
+// adversaryTypeConfigured reports whether any configured adversary group uses the given strategy,
+// either as its type or as a layer of its composition.
+func adversaryTypeConfigured(adversaryType network.AdversaryType) bool {
+	for _, configuredType := range config.AdversaryTypes {
+		if network.ToAdversaryType(configuredType) == adversaryType {
+			return true
+		}
+	}
+	for _, composition := range config.AdversaryCompositions {
+		for _, code := range strings.Split(composition, "+") {
+			if value, err := strconv.Atoi(code); err == nil && network.ToAdversaryType(value) == adversaryType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// dumpResultsOR dumps the orphan rates as seen by the first monitored peer: the fraction of the
+// messages older than OrphanageHorizon that no other message references, see
+// multiverse.Storage.IssuerOrphanage. Every tick writes one row per issuer plus the honest,
+// adversary and overall aggregates (Issuer -1); the aged-out tip counter of the RURTS age
+// restriction rides on the overall row.
+func dumpResultsOR(orResultsWriter *csv.Writer, testNetwork *network.Network) {
+	if !metricsWarmupElapsed() {
+		return
+	}
+
+	horizon := time.Duration(config.OrphanageHorizon) * time.Second
+	if !config.DiscreteEvent {
+		horizon *= time.Duration(config.SlowdownFactor)
+	}
+
+	monitoredPeer := testNetwork.Peer(config.MonitoredAWPeers[0])
+	issued, orphaned := monitoredPeer.Node.(multiverse.NodeInterface).Tangle().Storage.IssuerOrphanage(horizon)
+	ns := strconv.FormatInt(sinceStart(simulationStartTime).Nanoseconds(), 10)
+
+	matureByClass := map[string]int{}
+	orphanedByClass := map[string]int{}
+	for issuerID, matureCount := range issued {
+		class := "honest"
+		if network.IsAdversary(int(issuerID)) {
+			class = "adversary"
+		}
+		matureByClass[class] += matureCount
+		orphanedByClass[class] += orphaned[issuerID]
+		matureByClass["all"] += matureCount
+		orphanedByClass["all"] += orphaned[issuerID]
+
+		writeLine(orResultsWriter, []string{
+			strconv.FormatInt(int64(issuerID), 10),
+			class,
+			strconv.Itoa(matureCount),
+			strconv.Itoa(orphaned[issuerID]),
+			strconv.FormatFloat(orphanRate(matureCount, orphaned[issuerID]), 'f', 6, 64),
+			"0",
+			ns,
+		})
+	}
+
+	for _, class := range []string{"honest", "adversary", "all"} {
+		agedOutTips := int64(0)
+		if class == "all" {
+			agedOutTips = multiverse.AgedOutTipStatistics()
+		}
+		writeLine(orResultsWriter, []string{
+			"-1",
+			class,
+			strconv.Itoa(matureByClass[class]),
+			strconv.Itoa(orphanedByClass[class]),
+			strconv.FormatFloat(orphanRate(matureByClass[class], orphanedByClass[class]), 'f', 6, 64),
+			strconv.FormatInt(agedOutTips, 10),
+			ns,
+		})
+	}
+	orResultsWriter.Flush()
+}
+
+// orphanRate returns the orphaned fraction of the mature messages, 0 while none matured yet.
+func orphanRate(matureMessages int, orphanedMessages int) float64 {
+	if matureMessages == 0 {
+		return 0
+	}
+	return float64(orphanedMessages) / float64(matureMessages)
+}
+
+// dumpResultsNT dumps the NAT assignment and the resulting per-node connectivity, see network.ApplyNat.
+func dumpResultsNT(ntResultsWriter *csv.Writer, testNetwork *network.Network) {
+	inboundDegrees := testNetwork.InboundDegrees()
+	for _, peer := range testNetwork.Peers {
+		record := []string{
+			strconv.FormatInt(int64(peer.ID), 10),
+			strconv.FormatBool(testNetwork.IsNatted(peer.ID)),
+			strconv.Itoa(inboundDegrees[peer.ID]),
+			strconv.Itoa(len(peer.Neighbors)),
+		}
+		writeLine(ntResultsWriter, record)
+	}
+	ntResultsWriter.Flush()
+}
+
+// dumpResultsGS dumps how many messages the nodes received over gossip, how many of them were
+// duplicates of already known messages, how many were dropped by the timestamp validation and the
+// issued message requests split by cause, see multiverse.GossipStatistics and
+// multiverse.MessageRequestStatistics.
+func dumpResultsGS(gsResultsWriter *csv.Writer) {
+	if !metricsWarmupElapsed() {
+		return
+	}
+
+	received, duplicates := multiverse.GossipStatistics()
+	missing, retried, replayed, failed := multiverse.MessageRequestStatistics()
+	record := []string{
+		strconv.FormatInt(received, 10),
+		strconv.FormatInt(duplicates, 10),
+		strconv.FormatInt(multiverse.RejectedTimestampCount(), 10),
+		strconv.FormatInt(missing, 10),
+		strconv.FormatInt(retried, 10),
+		strconv.FormatInt(replayed, 10),
+		strconv.FormatInt(failed, 10),
+		strconv.FormatInt(multiverse.ReattachmentStatistics(), 10),
+		strconv.FormatInt(sinceStart(simulationStartTime).Nanoseconds(), 10),
+	}
+
+	writeLine(gsResultsWriter, record)
+	gsResultsWriter.Flush()
+}
+
+// dumpResultsEC dumps how far the eclipse victim's opinion and confirmations have diverged from the
+// honest part of the network.
+func dumpResultsEC(ecResultsWriter *csv.Writer, testNetwork *network.Network) {
+	if !metricsWarmupElapsed() {
+		return
+	}
+
+	victimPeer := testNetwork.Peers[config.EclipseVictim]
+	victimOpinion := victimPeer.Node.(multiverse.NodeInterface).Tangle().OpinionManager.Opinion()
+
+	confirmedMessageMutex.RLock()
+	monitoredConfirmed := confirmedMessageCounter[network.PeerID(config.MonitoredAWPeers[0])]
+	confirmedMessageMutex.RUnlock()
+
+	record := []string{
+		victimOpinion.String(),
+		honestOnlyMostLikedColor.String(),
+		strconv.FormatInt(atomicCounters.Get("eclipseVictimConfirmed"), 10),
+		strconv.FormatInt(monitoredConfirmed, 10),
+		strconv.FormatInt(sinceStart(simulationStartTime).Nanoseconds(), 10),
+	}
+
+	writeLine(ecResultsWriter, record)
+	ecResultsWriter.Flush()
+}
+
+func dumpResultsMM(mmResultsWriter *csv.Writer) {
+	// Dump the opinion and confirmation counters
+	record := []string{
+		strconv.FormatInt(colorCounters.Get("requestedMissingMessages", multiverse.UndefinedColor), 10),
+		strconv.FormatInt(sinceStart(simulationStartTime).Nanoseconds(), 10),
+	}
+
+	writeLine(mmResultsWriter, record)
+
+	// Flush the mm writer, or the data will be truncated sometimes if the buffer is full
+	mmResultsWriter.Flush()
+}
+
+func dumpResultsCC(ccResultsWriter *csv.Writer, sinceIssuance string) {
+	// Dump the opinion and confirmation counters, one column group per color in ccHeader order
+	var record []string
+	record = appendColorValues(record, colorCounters, "confirmedNodes")
+	record = appendColorValues(record, adversaryCounters, "confirmedNodes")
+	record = appendColorValues(record, colorCounters, "confirmedAccumulatedWeight")
+	record = appendColorValues(record, adversaryCounters, "confirmedAccumulatedWeight")
+	record = appendColorValues(record, colorCounters, "opinions")
+	record = appendColorValues(record, colorCounters, "likeAccumulatedWeight")
+	record = appendColorValues(record, adversaryCounters, "likeAccumulatedWeight")
+	record = appendColorValues(record, colorCounters, "colorUnconfirmed")
+	record = appendColorValues(record, colorCounters, "unconfirmedAccumulatedWeight")
+	record = append(record,
+		strconv.FormatInt(atomicCounters.Get("flips"), 10),
+		strconv.FormatInt(atomicCounters.Get("honestFlips"), 10),
+		strconv.FormatInt(sinceStart(simulationStartTime).Nanoseconds(), 10),
+		sinceIssuance,
+	)
+
+	writeLine(ccResultsWriter, record)
+
+	// Flush the cc writer, or the data will be truncated sometimes if the buffer is full
+	ccResultsWriter.Flush()
+}
+
+func dumpResultsAD(adResultsWriter *csv.Writer, net *network.Network) {
+	adHeader = []string{"AdversaryGroupID", "Strategy", "AdversaryCount", "q"}
+	for groupID, group := range net.AdversaryGroups {
+		record := []string{
+			strconv.FormatInt(int64(groupID), 10),
+			network.AdversaryTypeToString(group.AdversaryType),
+			strconv.FormatInt(int64(len(group.NodeIDs)), 10),
+			strconv.FormatFloat(float64(group.GroupMana)/float64(config.NodesTotalWeight), 'f', 6, 64),
+			strconv.FormatInt(sinceStart(simulationStartTime).Nanoseconds(), 10),
+		}
+		writeLine(adResultsWriter, record)
+	}
+	// Flush the cc writer, or the data will be truncated sometimes if the buffer is full
+	adResultsWriter.Flush()
+}
+
+func writeLine(writer *csv.Writer, record []string) {
+	if err := writer.Write(record); err != nil {
+		log.Fatal("error writing record to csv:", err)
+	}
+
+	if err := writer.Error(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func createWriter(fileName string, header []string, resultsWriters *[]*csv.Writer) *csv.Writer {
+	file, err := os.Create(path.Join(config.ResultDir, fileName))
+	if err != nil {
+		panic(err)
+	}
+	resultsWriter := csv.NewWriter(file)
+
+	// Check the result writers
+	if resultsWriters != nil {
+		*resultsWriters = append(*resultsWriters, resultsWriter)
+	}
+	// Write the headers
+	if err := resultsWriter.Write(header); err != nil {
+		panic(err)
+	}
+	return resultsWriter
+}
+
+// issuanceBands holds the current mana-derived base band of every peer; the issuance loops read it
+// on every iteration, so weight changes during the run retarget the rates live.
+var (
+	issuanceBands      = make(map[network.PeerID]float64)
+	issuanceBandsMutex sync.RWMutex
+)
+
+func setIssuanceBand(peerID network.PeerID, band float64) {
+	issuanceBandsMutex.Lock()
+	defer issuanceBandsMutex.Unlock()
+	issuanceBands[peerID] = band
+}
+
+func issuanceBand(peerID network.PeerID) float64 {
+	issuanceBandsMutex.RLock()
+	defer issuanceBandsMutex.RUnlock()
+	return issuanceBands[peerID]
+}
+
+// refreshIssuanceBands rederives the base band of every peer from the current weight distribution,
+// keeping the total throughput at the configured TPS while the weights move.
+func refreshIssuanceBands(testNetwork *network.Network) {
+	// Nodes Total Weighted Weight, which is used to simulate the congested honest nodes with speeded up adversary.
+	// The total throughput remains the same.
+	nodeTotalWeightedWeight := 0.0
+	for _, peer := range testNetwork.Peers {
+		nodeTotalWeightedWeight += float64(testNetwork.WeightDistribution.Weight(peer.ID)) * peer.AdversarySpeedup()
+	}
+
+	for _, peer := range testNetwork.Peers {
+		setIssuanceBand(peer.ID, float64(testNetwork.WeightDistribution.Weight(peer.ID))*float64(config.TPS)/nodeTotalWeightedWeight)
+	}
+}
+
+func secureNetwork(ctx context.Context, testNetwork *network.Network) {
+	// In the simulation we let all nodes can send messages.
+	refreshIssuanceBands(testNetwork)
+
+	for _, peer := range testNetwork.Peers {
+		// if float64(config.RelevantValidatorWeight)*weightOfPeer <= largestWeight {
+		// 	continue
+		// }
+
+		atomicCounters.Add("relevantValidators", 1)
+
+		// Each peer should send messages according to their mana: Fix TPS for example 1000;
+		// A node with a x% of mana will issue 1000*x% messages per second
+
+		// Weight: 100, 20, 1
+		// TPS: 1000
+		// Band widths summed up: 100000/121 + 20000/121 + 1000/121 = 1000
+
+		// peer.AdversarySpeedup()=1 for honest nodes and can have different values from adversary nodes;
+		// the issuance loops multiply it in on every iteration so adaptive strategies can retarget it
+		fmt.Printf("speedup %f band %f\n", peer.AdversarySpeedup(), peer.AdversarySpeedup()*issuanceBand(peer.ID))
+
+		if config.DiscreteEvent {
+			scheduleIssuance(peer)
+		} else {
+			go startSecurityWorker(ctx, peer)
+		}
+	}
+}
+
+// scheduleIssuance queues the next message issuance of the peer on the virtual clock and keeps
+// re-scheduling itself, replacing the wall-clock ticker of startSecurityWorker.
+func scheduleIssuance(peer *network.Peer) {
+	band := peer.AdversarySpeedup() * issuanceBand(peer.ID) * rateSetterFactor(peer.ID) * adaptivePowFactor(peer.ID)
+	if band <= 0 {
+		log.Warn("Peer ID: ", peer.ID, " has no bandwidth, not issuing!")
+		return
+	}
+	pace := time.Duration(float64(time.Second) / band)
+	if config.IMIF == "poisson" {
+		pace = time.Duration(float64(time.Second) * peer.Rand.ExpFloat64() / band)
+	}
+	if pace == time.Duration(0) {
+		log.Warn("Peer ID: ", peer.ID, " has 0 pace!")
+		return
+	}
+
+	clock.Virtual.ExecuteAfter(pace, func() {
+		if !peer.IsOffline() {
+			sendMessage(peer)
+		}
+		scheduleIssuance(peer)
+	})
+}
+
+// schedulePartition splits the network into PartitionCount components at PartitionStart and heals it
+// PartitionDuration later, dumping the component membership to pt-*.csv. Reconciliation of the
+// diverged tangles afterwards happens through the regular gossip and solidification machinery.
+func schedulePartition(testNetwork *network.Network, ptResultsWriter *csv.Writer) {
+	start := time.Duration(config.PartitionStart) * time.Second
+	duration := time.Duration(config.PartitionDuration) * time.Second
+
+	partition := func() {
+		membership := testNetwork.Partition(config.PartitionCount)
+		for nodeID, component := range membership {
+			record := []string{strconv.Itoa(nodeID), strconv.Itoa(component)}
+			if err := ptResultsWriter.Write(record); err != nil {
+				log.Fatal(err)
+			}
+		}
+		log.Infof("Partitioned the network into %d components", config.PartitionCount)
+	}
+	heal := func() {
+		healed := testNetwork.Heal()
+		log.Infof("Healed the network partition, restored %d connections", healed)
+	}
+
+	if config.DiscreteEvent {
+		clock.Virtual.ExecuteAfter(start, partition)
+		clock.Virtual.ExecuteAfter(start+duration, heal)
+		return
+	}
+
+	time.AfterFunc(time.Duration(config.SlowdownFactor)*start, partition)
+	time.AfterFunc(time.Duration(config.SlowdownFactor)*(start+duration), heal)
+}
+
+// startChurnWorker takes a random fraction of the nodes offline every ChurnInterval of simulated time
+// and brings them back after ChurnDowntime, leaving the solidifier to catch them up on the tangle
+// portion they missed. Adversary nodes and the monitored AW peers are never churned.
+func startChurnWorker(ctx context.Context, testNetwork *network.Network) {
+	interval := time.Duration(config.ChurnInterval) * time.Second
+	downtime := time.Duration(config.ChurnDowntime) * time.Second
+
+	protected := make(map[int]bool)
+	for _, id := range config.MonitoredAWPeers {
+		protected[id] = true
+	}
+
+	churnRound := func() {
+		candidates := make([]*network.Peer, 0)
+		for _, peer := range testNetwork.Peers {
+			if peer.IsOffline() || protected[int(peer.ID)] || network.IsAdversary(int(peer.ID)) {
+				continue
+			}
+			candidates = append(candidates, peer)
+		}
+
+		churnCount := int(config.ChurnFraction * float64(config.NodesCount))
+		for i := 0; i < churnCount && len(candidates) > 0; i++ {
+			peerIndex := crypto.Randomness.Intn(len(candidates))
+			churnedPeer := candidates[peerIndex]
+			candidates[peerIndex] = candidates[len(candidates)-1]
+			candidates = candidates[:len(candidates)-1]
+
+			churnedPeer.SetOffline(true)
+			log.Infof("Churn: peer %d went offline", churnedPeer.ID)
+
+			comeBack := func() {
+				churnedPeer.SetOffline(false)
+				log.Infof("Churn: peer %d came back online", churnedPeer.ID)
+			}
+			if config.DiscreteEvent {
+				clock.Virtual.ExecuteAfter(downtime, comeBack)
+			} else {
+				time.AfterFunc(time.Duration(config.SlowdownFactor)*downtime, comeBack)
+			}
+		}
+	}
+
+	if config.DiscreteEvent {
+		var scheduleChurn func()
+		scheduleChurn = func() {
+			clock.Virtual.ExecuteAfter(interval, func() {
+				churnRound()
+				scheduleChurn()
+			})
+		}
+		scheduleChurn()
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(config.SlowdownFactor) * interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				churnRound()
+			}
+		}
+	}()
+}
+
+// startCoordinatorWorker makes the largest-weight peer issue a milestone every MilestoneInterval of
+// simulated time. With CoordinatorEnabled the milestones are the only source of confirmations, so
+// coordinator-based and approval-weight-based finality can be compared under the same traffic.
+func startCoordinatorWorker(ctx context.Context, testNetwork *network.Network) {
+	interval := time.Duration(config.MilestoneInterval) * time.Second
+
+	coordinator := testNetwork.Peers[0]
+	for _, peer := range testNetwork.Peers {
+		if testNetwork.WeightDistribution.Weight(peer.ID) > testNetwork.WeightDistribution.Weight(coordinator.ID) {
+			coordinator = peer
+		}
+	}
+	log.Infof("Coordinator: peer %d issues a milestone every %d seconds", coordinator.ID, config.MilestoneInterval)
+
+	issueMilestone := func() {
+		if !coordinator.IsOffline() {
+			coordinator.Node.(multiverse.NodeInterface).IssueMilestone()
+		}
+	}
+
+	if config.DiscreteEvent {
+		var scheduleMilestone func()
+		scheduleMilestone = func() {
+			clock.Virtual.ExecuteAfter(interval, func() {
+				issueMilestone()
+				scheduleMilestone()
+			})
+		}
+		scheduleMilestone()
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(config.SlowdownFactor) * interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				issueMilestone()
+			}
+		}
+	}()
+}
+
+// startSchedulerWorker drains the outbox queues of every node's scheduler at SchedulerRate, releasing
+// the next batch every SchedulerInterval, see multiverse.Scheduler.
+func startSchedulerWorker(ctx context.Context, testNetwork *network.Network) {
+	interval := time.Duration(config.SchedulerInterval) * time.Millisecond
+	batch := config.SchedulerRate * config.SchedulerInterval / 1000
+	if batch < 1 {
+		batch = 1
+	}
+
+	drain := func() {
+		for _, peer := range testNetwork.Peers {
+			peer.Node.(multiverse.NodeInterface).Tangle().Scheduler.ScheduleBatch(batch)
+		}
+	}
+
+	if config.DiscreteEvent {
+		var scheduleDrain func()
+		scheduleDrain = func() {
+			clock.Virtual.ExecuteAfter(interval, func() {
+				drain()
+				scheduleDrain()
+			})
+		}
+		scheduleDrain()
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(config.SlowdownFactor) * interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				drain()
+			}
+		}
+	}()
+}
+
+// startGossipPullWorker triggers the periodic IHAVE announcements of the pull gossip mode, see
+// multiverse.Node.FlushInventory.
+func startGossipPullWorker(ctx context.Context, testNetwork *network.Network) {
+	interval := time.Duration(config.GossipPullInterval) * time.Millisecond
+
+	announce := func() {
+		for _, peer := range testNetwork.Peers {
+			if node, ok := peer.Node.(interface{ FlushInventory() }); ok {
+				node.FlushInventory()
+			}
+		}
+	}
+
+	if config.DiscreteEvent {
+		var scheduleAnnouncement func()
+		scheduleAnnouncement = func() {
+			clock.Virtual.ExecuteAfter(interval, func() {
+				announce()
+				scheduleAnnouncement()
+			})
+		}
+		scheduleAnnouncement()
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(config.SlowdownFactor) * interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				announce()
+			}
+		}
+	}()
+}
+
+// startPruningWorker evicts the messages confirmed more than PruningAge of simulated time ago from
+// every node's tangle once per PruningInterval, see multiverse.Storage.PruneConfirmed.
+func startPruningWorker(ctx context.Context, testNetwork *network.Network) {
+	interval := time.Duration(config.PruningInterval) * time.Second
+	maturityAge := time.Duration(config.PruningAge) * time.Second
+	if !config.DiscreteEvent {
+		maturityAge *= time.Duration(config.SlowdownFactor)
+	}
+
+	prune := func() {
+		prunedMessages := 0
+		for _, peer := range testNetwork.Peers {
+			prunedMessages += peer.Node.(multiverse.NodeInterface).Tangle().Storage.PruneConfirmed(maturityAge)
+		}
+		if prunedMessages > 0 {
+			log.Debugf("Pruning: evicted %d messages across all nodes", prunedMessages)
+		}
+	}
+
+	if config.DiscreteEvent {
+		var schedulePruning func()
+		schedulePruning = func() {
+			clock.Virtual.ExecuteAfter(interval, func() {
+				prune()
+				schedulePruning()
+			})
+		}
+		schedulePruning()
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(config.SlowdownFactor) * interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				prune()
+			}
+		}
+	}()
+}
+
+// startReattachmentWorker makes every honest node reissue the payloads of its own messages that
+// stayed unreferenced for longer than ReattachmentDeadline, once per ReattachmentInterval, see
+// multiverse.Node.ReattachOrphanedMessages.
+func startReattachmentWorker(ctx context.Context, testNetwork *network.Network) {
+	interval := time.Duration(config.ReattachmentInterval) * time.Second
+	deadline := time.Duration(config.ReattachmentDeadline) * time.Second
+	if !config.DiscreteEvent {
+		deadline *= time.Duration(config.SlowdownFactor)
+	}
+
+	reattach := func() {
+		reattachedMessages := 0
+		for _, peer := range testNetwork.Peers {
+			if network.IsAdversary(int(peer.ID)) {
+				continue
+			}
+			if node, ok := peer.Node.(interface {
+				ReattachOrphanedMessages(deadline time.Duration) int
+			}); ok {
+				reattachedMessages += node.ReattachOrphanedMessages(deadline)
+			}
+		}
+		if reattachedMessages > 0 {
+			log.Debugf("Reattachment: reissued %d orphaned messages across all nodes", reattachedMessages)
+		}
+	}
+
+	if config.DiscreteEvent {
+		var scheduleReattachment func()
+		scheduleReattachment = func() {
+			clock.Virtual.ExecuteAfter(interval, func() {
+				reattach()
+				scheduleReattachment()
+			})
+		}
+		scheduleReattachment()
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(config.SlowdownFactor) * interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				reattach()
+			}
+		}
+	}()
+}
+
+// startPeerScoringWorker evaluates the neighbor scores every PeerScoringInterval of simulated time and
+// drops the neighbors delivering excessive duplicates or conflicting messages, see
+// network.DropMisbehavingNeighbors.
+func startPeerScoringWorker(ctx context.Context, testNetwork *network.Network) {
+	interval := time.Duration(config.PeerScoringInterval) * time.Second
+
+	evaluate := func() {
+		if dropped := testNetwork.DropMisbehavingNeighbors(
+			config.PeerScoringMaxInvalidRatio, int64(config.PeerScoringMinDeliveries)); dropped > 0 {
+			log.Infof("Peer scoring dropped %d connections", dropped)
+		}
+	}
+
+	if config.DiscreteEvent {
+		var scheduleEvaluation func()
+		scheduleEvaluation = func() {
+			clock.Virtual.ExecuteAfter(interval, func() {
+				evaluate()
+				scheduleEvaluation()
+			})
+		}
+		scheduleEvaluation()
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(config.SlowdownFactor) * interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				evaluate()
+			}
+		}
+	}()
+}
+
+// startAdversaryAdaptationWorker periodically hands every adversary node implementing
+// adversary.AdaptiveNode an observation of its own tangle and applies the issuance speedup the
+// strategy asks for, relative to its configured one, closing the loop for adaptive attacks. With
+// AdversaryOmniscient set the observation carries the freshest approval weights seen anywhere in the
+// network instead of only the node's own, the unrealistic baseline partial-view attackers are
+// compared against.
+func startAdversaryAdaptationWorker(ctx context.Context, testNetwork *network.Network) {
+	interval := time.Duration(config.AdversaryAdaptInterval) * time.Millisecond
+
+	baseSpeedups := make(map[network.PeerID]float64)
+	for _, peer := range testNetwork.Peers {
+		if _, isAdaptive := peer.Node.(adversary.AdaptiveNode); isAdaptive {
+			baseSpeedups[peer.ID] = peer.AdversarySpeedup()
+		}
+	}
+
+	adaptRound := func() {
+		var omniscientWeights map[multiverse.Color]uint64
+		if config.AdversaryOmniscient {
+			omniscientWeights = adversary.OmniscientApprovalWeights(testNetwork)
+		}
+
+		for _, peer := range testNetwork.Peers {
+			adaptiveNode, isAdaptive := peer.Node.(adversary.AdaptiveNode)
+			if !isAdaptive {
+				continue
+			}
+
+			opinionManager := peer.Node.(multiverse.NodeInterface).Tangle().OpinionManager
+			approvalWeights := omniscientWeights
+			if approvalWeights == nil {
+				approvalWeights = make(map[multiverse.Color]uint64)
+				for color, approvalWeight := range opinionManager.ApprovalWeights() {
+					approvalWeights[color] = approvalWeight
+				}
+			}
+			observation := adversary.TangleObservation{
+				ApprovalWeights: approvalWeights,
+				Opinion:         opinionManager.Opinion(),
+				Elapsed:         sinceStart(simulationStartTime),
+			}
+
+			if speedupFactor := adaptiveNode.Adapt(observation); speedupFactor > 0 {
+				peer.SetAdversarySpeedup(baseSpeedups[peer.ID] * speedupFactor)
+			}
+		}
+	}
+
+	if config.DiscreteEvent {
+		var scheduleAdaptation func()
+		scheduleAdaptation = func() {
+			clock.Virtual.ExecuteAfter(interval, func() {
+				adaptRound()
+				scheduleAdaptation()
+			})
+		}
+		scheduleAdaptation()
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(config.SlowdownFactor) * interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				adaptRound()
+			}
+		}
+	}()
+}
+
+// startSpamBurstWorker makes every adversary node issue AdversarySpamBurst messages in one go every
+// AdversarySpamInterval of simulated time, far above its mana-allowed rate. Between the bursts the
+// nodes fall back to their regular issuance, modeling a spammer that stays silent and then floods the
+// tip pool.
+func startSpamBurstWorker(ctx context.Context, testNetwork *network.Network) {
+	interval := time.Duration(config.AdversarySpamInterval) * time.Second
+
+	burst := func() {
+		issued := 0
+		for _, peer := range testNetwork.Peers {
+			if !network.IsAdversary(int(peer.ID)) || peer.IsOffline() {
+				continue
+			}
+			// stealth nodes never join the bursts, their issuance timing must stay honest
+			if _, isStealth := peer.Node.(*adversary.StealthNode); isStealth {
+				continue
+			}
+			for i := 0; i < config.AdversarySpamBurst; i++ {
+				sendMessage(peer)
+			}
+			issued += config.AdversarySpamBurst
+		}
+		adversary.ChargeMessages(issued)
+		if issued > 0 {
+			log.Infof("Adversary spam burst issued %d messages", issued)
+		}
+	}
+
+	if config.DiscreteEvent {
+		var scheduleBurst func()
+		scheduleBurst = func() {
+			clock.Virtual.ExecuteAfter(interval, func() {
+				burst()
+				scheduleBurst()
+			})
+		}
+		scheduleBurst()
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(config.SlowdownFactor) * interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				burst()
+			}
+		}
+	}()
+}
+
+// scheduleSybilInjection activates the sybil group SybilStart seconds into the run: the identities
+// come online with their granted mana share, join the ongoing conflict on their configured color and
+// start issuing at the rate their new mana allows, see network.ActivateSybils.
+func scheduleSybilInjection(testNetwork *network.Network) {
+	start := time.Duration(config.SybilStart) * time.Second
+
+	inject := func() {
+		activated := testNetwork.AdversaryGroups.ActivateSybils(testNetwork, config.SybilMana)
+		if len(activated) == 0 {
+			return
+		}
+		log.Infof("Injected %d sybil nodes with %.1f%% of the total mana", len(activated), config.SybilMana)
+		refreshIssuanceBands(testNetwork)
+
+		for _, group := range testNetwork.AdversaryGroups {
+			if !group.Sybil {
+				continue
+			}
+			color := multiverse.ColorFromStr(group.InitColor)
+			for _, nodeID := range group.NodeIDs {
+				peer := testNetwork.Peer(nodeID)
+				if group.AdversaryType != network.HonestNode {
+					adversary.CastAdversary(peer.Node).AssignColor(color)
+				}
+				sendMessage(peer, color)
+
+				if config.DiscreteEvent {
+					scheduleIssuance(peer)
+				} else {
+					go startSecurityWorker(simulationCtx, peer)
+				}
+			}
+		}
+	}
+
+	if config.DiscreteEvent {
+		clock.Virtual.ExecuteAfter(start, inject)
+		return
+	}
+
+	time.AfterFunc(time.Duration(config.SlowdownFactor)*start, inject)
+}
+
+// scheduleAdversaryActivations silences every adversary group with a configured activation time and
+// brings it back online once that time is reached. While silent the members do not issue anything,
+// not even honest-looking messages; their issuance loops keep running and resume on activation. A
+// group activated after the double spend joins the ongoing conflict on its configured color.
+func scheduleAdversaryActivations(testNetwork *network.Network) {
+	for _, group := range testNetwork.AdversaryGroups {
+		if group.Sybil || group.ActivationTime <= 0 {
+			continue
+		}
+		for _, nodeID := range group.NodeIDs {
+			testNetwork.Peer(nodeID).SetOffline(true)
+		}
+
+		group := group
+		activate := func() {
+			color := multiverse.ColorFromStr(group.InitColor)
+			for _, nodeID := range group.NodeIDs {
+				peer := testNetwork.Peer(nodeID)
+				peer.SetOffline(false)
+				if !dsIssuanceTime.IsZero() {
+					if group.AdversaryType != network.HonestNode {
+						adversary.CastAdversary(peer.Node).AssignColor(color)
+					}
+					sendMessage(peer, color)
+				}
+			}
+			log.Infof("Activated adversary group of %d nodes %.0f seconds into the run", len(group.NodeIDs), group.ActivationTime.Seconds())
+		}
+
+		if config.DiscreteEvent {
+			clock.Virtual.ExecuteAfter(group.ActivationTime, activate)
+		} else {
+			time.AfterFunc(time.Duration(config.SlowdownFactor)*group.ActivationTime, activate)
+		}
+	}
+}
+
+// scheduleWithholdingRelease floods the private side tangles of the withholding adversary nodes to
+// the network AdversaryWithholdDelay seconds into the run, see adversary.WithholdingNode.Release.
+func scheduleWithholdingRelease(testNetwork *network.Network) {
+	delay := time.Duration(config.AdversaryWithholdDelay) * time.Second
+
+	release := func() {
+		released := 0
+		for _, peer := range testNetwork.Peers {
+			if withholder, isWithholding := peer.Node.(adversary.Withholder); isWithholding {
+				released += withholder.Release()
+			}
+		}
+		adversary.ChargeReleases(released)
+		log.Infof("Withholding adversary released %d messages", released)
+	}
+
+	if config.DiscreteEvent {
+		clock.Virtual.ExecuteAfter(delay, release)
+		return
+	}
+
+	time.AfterFunc(time.Duration(config.SlowdownFactor)*delay, release)
+}
+
+// startReplayWorker lets the replay adversary nodes re-gossip and re-request a batch of stale
+// messages every AdversaryReplayInterval of simulated time, see adversary.ReplayNode.Replay.
+func startReplayWorker(ctx context.Context, testNetwork *network.Network) {
+	interval := time.Duration(config.AdversaryReplayInterval) * time.Second
+
+	replay := func() {
+		replayed := 0
+		for _, peer := range testNetwork.Peers {
+			if replayer, isReplayer := peer.Node.(adversary.Replayer); isReplayer {
+				replayed += replayer.Replay()
+			}
+		}
+		adversary.ChargeMessages(replayed)
+		if replayed > 0 {
+			log.Infof("Replay adversary re-injected %d stale messages", replayed)
+		}
+	}
+
+	if config.DiscreteEvent {
+		var scheduleReplay func()
+		scheduleReplay = func() {
+			clock.Virtual.ExecuteAfter(interval, func() {
+				replay()
+				scheduleReplay()
+			})
+		}
+		scheduleReplay()
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(config.SlowdownFactor) * interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				replay()
+			}
+		}
+	}()
+}
+
+// startParasiteAttachWorker floods the privately grown parasitic chain segments to the network every
+// AdversaryParasiteInterval of simulated time, see adversary.ParasiteNode.Attach.
+func startParasiteAttachWorker(ctx context.Context, testNetwork *network.Network) {
+	interval := time.Duration(config.AdversaryParasiteInterval) * time.Second
+
+	attach := func() {
+		attached := 0
+		for _, peer := range testNetwork.Peers {
+			if parasite, isParasite := peer.Node.(adversary.Parasite); isParasite {
+				attached += parasite.Attach()
+			}
+		}
+		adversary.ChargeReleases(attached)
+		if attached > 0 {
+			log.Infof("Parasitic chains attached %d messages to the main tangle", attached)
+		}
+	}
+
+	if config.DiscreteEvent {
+		var scheduleAttach func()
+		scheduleAttach = func() {
+			clock.Virtual.ExecuteAfter(interval, func() {
+				attach()
+				scheduleAttach()
+			})
+		}
+		scheduleAttach()
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(config.SlowdownFactor) * interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				attach()
+			}
+		}
+	}()
+}
+
+// startRewiringWorker drops and re-establishes a fraction of the connections every RewireInterval of
+// simulated time, see network.Rewire.
+func startRewiringWorker(ctx context.Context, testNetwork *network.Network) {
+	interval := time.Duration(config.RewireInterval) * time.Second
+
+	if config.DiscreteEvent {
+		var scheduleRewiring func()
+		scheduleRewiring = func() {
+			clock.Virtual.ExecuteAfter(interval, func() {
+				testNetwork.Rewire(config.RewireFraction)
+				scheduleRewiring()
+			})
+		}
+		scheduleRewiring()
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(config.SlowdownFactor) * interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				testNetwork.Rewire(config.RewireFraction)
+			}
+		}
+	}()
+}
+
+func startManaGrowthWorker(ctx context.Context, testNetwork *network.Network) {
+	tick := time.Duration(config.ConsensusMonitorTick) * time.Millisecond
+	grow := func() {
+		testNetwork.AdversaryGroups.GrowMana(testNetwork, tick)
+		refreshIssuanceBands(testNetwork)
+	}
+
+	if config.DiscreteEvent {
+		var scheduleGrowth func()
+		scheduleGrowth = func() {
+			clock.Virtual.ExecuteAfter(tick, func() {
+				grow()
+				scheduleGrowth()
+			})
+		}
+		scheduleGrowth()
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(config.SlowdownFactor) * tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			grow()
+		}
+	}
+}
+
+// manaSchedulePoint is one checkpoint of the adversary mana schedule, see parseManaSchedule.
+type manaSchedulePoint struct {
+	at         time.Duration
+	percentage float64
+}
+
+// parseManaSchedule turns the 'second:percent' entries of AdversaryManaSchedule into checkpoints
+// sorted by time, dropping entries that do not parse.
+func parseManaSchedule() (points []manaSchedulePoint) {
+	for _, entry := range config.AdversaryManaSchedule {
+		parts := strings.Split(entry, ":")
+		if len(parts) != 2 {
+			log.Warnf("Ignoring invalid mana schedule entry %q, expected 'second:percent'", entry)
+			continue
+		}
+		seconds, secondsErr := strconv.Atoi(parts[0])
+		percentage, percentageErr := strconv.ParseFloat(parts[1], 64)
+		if secondsErr != nil || percentageErr != nil {
+			log.Warnf("Ignoring invalid mana schedule entry %q, expected 'second:percent'", entry)
+			continue
+		}
+		points = append(points, manaSchedulePoint{
+			at:         time.Duration(seconds) * time.Second,
+			percentage: percentage,
+		})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].at < points[j].at })
+	return
+}
+
+// scheduledManaPercentage interpolates the mana share the schedule assigns to the given point in
+// time, holding the first and last checkpoints beyond the ends of the schedule.
+func scheduledManaPercentage(points []manaSchedulePoint, elapsed time.Duration) float64 {
+	if elapsed <= points[0].at {
+		return points[0].percentage
+	}
+	for i := 1; i < len(points); i++ {
+		if elapsed < points[i].at {
+			progress := float64(elapsed-points[i-1].at) / float64(points[i].at-points[i-1].at)
+			return points[i-1].percentage + progress*(points[i].percentage-points[i-1].percentage)
+		}
+	}
+	return points[len(points)-1].percentage
+}
+
+// startManaScheduleWorker ramps every adversary group's mana share along the configured schedule,
+// moving weight between the honest nodes and the groups and retargeting the issuance bands live, to
+// find the mana threshold at which the attack starts succeeding.
+func startManaScheduleWorker(ctx context.Context, testNetwork *network.Network) {
+	points := parseManaSchedule()
+	if len(points) == 0 {
+		return
+	}
+
+	tick := time.Duration(config.ConsensusMonitorTick) * time.Millisecond
+	elapsed := time.Duration(0)
+	retarget := func() {
+		elapsed += tick
+		testNetwork.AdversaryGroups.RetargetMana(testNetwork, scheduledManaPercentage(points, elapsed))
+		refreshIssuanceBands(testNetwork)
+	}
+
+	if config.DiscreteEvent {
+		var scheduleRetarget func()
+		scheduleRetarget = func() {
+			clock.Virtual.ExecuteAfter(tick, func() {
+				retarget()
+				scheduleRetarget()
+			})
+		}
+		scheduleRetarget()
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(config.SlowdownFactor) * tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			retarget()
+		}
+	}
+}
+
+func startSecurityWorker(ctx context.Context, peer *network.Peer) {
+	band := peer.AdversarySpeedup() * issuanceBand(peer.ID) * rateSetterFactor(peer.ID) * adaptivePowFactor(peer.ID)
+	if band <= 0 {
+		log.Warn("Peer ID: ", peer.ID, " has no bandwidth, not issuing!")
+		return
+	}
+	pace := time.Duration(float64(time.Second) * float64(config.SlowdownFactor) / band)
+
+	log.Debug("Peer ID: ", peer.ID, " Pace: ", pace)
+	if pace == time.Duration(0) {
+		log.Warn("Peer ID: ", peer.ID, " has 0 pace!")
+		return
+	}
+	ticker := time.NewTicker(pace)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			band = peer.AdversarySpeedup() * issuanceBand(peer.ID) * rateSetterFactor(peer.ID) * adaptivePowFactor(peer.ID)
+			if band <= 0 {
+				continue
+			}
+			if config.IMIF == "poisson" {
+				pace = time.Duration(float64(time.Second) * float64(config.SlowdownFactor) * peer.Rand.ExpFloat64() / band)
+				if pace > 0 {
+					ticker.Reset(pace)
+				}
+			} else if newPace := time.Duration(float64(time.Second) * float64(config.SlowdownFactor) / band); newPace != pace && newPace > 0 {
+				// an adaptive adversary strategy may have retargeted the issuance speedup
+				pace = newPace
+				ticker.Reset(pace)
+			}
+			// diff := peer.Rand.Float64()
+
+			// fmt.Println("difficulty:", diff)
+			// fmt.Println("pace:", pace)
+			// if pace >= time.Duration(diff) {
+			// 	fmt.Println("POW satisfied")
+			// 	sendMessage(peer)
+
+			// }
+
+			if !peer.IsOffline() {
+				sendMessage(peer)
+			}
+
+		}
+	}
+}
+
+func sendMessage(peer *network.Peer, optionalColor ...multiverse.Color) {
+	waitWhilePaused()
+	atomicCounters.Add("tps", 1)
+	if network.IsAdversary(int(peer.ID)) {
+		atomicCounters.Add("adversaryIssuedMessages", 1)
+	}
+	if config.AdaptivePowEnabled {
+		countAdaptivePowIssuance(peer.ID)
+	}
+
+	if len(optionalColor) >= 1 {
+		peer.Node.(multiverse.NodeInterface).IssuePayload(optionalColor[0])
+	}
+
+	// in ledger mode every regular message carries a fresh, non-conflicting transaction
+	if config.LedgerEnabled {
+		peer.Node.(multiverse.NodeInterface).IssueTransaction(multiverse.NewTransaction(multiverse.NewOutput()))
+		return
+	}
+
+	peer.Node.(multiverse.NodeInterface).IssuePayload(multiverse.UndefinedColor)
+}
+
+// sendTransaction issues a message carrying the transaction; the message's color is the color of the
+// conflict the transaction is part of, see multiverse.NewTransaction.
+func sendTransaction(peer *network.Peer, tx *multiverse.Transaction) {
+	waitWhilePaused()
+	atomicCounters.Add("tps", 1)
+	if network.IsAdversary(int(peer.ID)) {
+		atomicCounters.Add("adversaryIssuedMessages", 1)
+	}
+	if config.AdaptivePowEnabled {
+		countAdaptivePowIssuance(peer.ID)
+	}
+
+	peer.Node.(multiverse.NodeInterface).IssueTransaction(tx)
+}
+
+// topologyStrategy maps config.Topology to the peering strategy building the network graph.
+// clusterSizes resolves the region sizes of the MR topology: the explicit ClusterSizes list, or
+// NodesCount split as evenly as possible into ClusterCount regions.
+func clusterSizes() []int {
+	if len(config.ClusterSizes) > 0 {
+		return config.ClusterSizes
+	}
+
+	sizes := make([]int, config.ClusterCount)
+	for i := range sizes {
+		sizes[i] = config.NodesCount / config.ClusterCount
+		if i < config.NodesCount%config.ClusterCount {
+			sizes[i]++
+		}
+	}
+
+	return sizes
+}
+
+// adversaryPeeringTargets resolves the nodes every adversary is additionally peered with: the explicit
+// AdversaryPeeringNodes list, or the top-k mana holders (the k lowest node IDs under the Zipf weight
+// distribution) when only AdversaryPeeringTopK is set.
+func adversaryPeeringTargets() []int {
+	if len(config.AdversaryPeeringNodes) > 0 {
+		return config.AdversaryPeeringNodes
+	}
+
+	targets := make([]int, 0, config.AdversaryPeeringTopK)
+	for i := 0; i < config.AdversaryPeeringTopK && i < config.NodesCount; i++ {
+		targets = append(targets, i)
+	}
+
+	return targets
+}
+
+// scaledAdversaryInternalDelay scales the adversary-internal link delay with the slowdown factor like
+// the regular link delays, keeping -1 as the disabled marker.
+func scaledAdversaryInternalDelay(slowdown int) int {
+	if config.AdversaryInternalDelay < 0 {
+		return config.AdversaryInternalDelay
+	}
+
+	return slowdown * config.AdversaryInternalDelay
+}
+
+func topologyStrategy() network.PeeringStrategy {
+	if config.TopologyFile != "" {
+		return network.TopologyFromFile(config.TopologyFile)
+	}
+	switch config.Topology {
+	case "ER":
+		return network.ErdosRenyi(config.ErdosRenyiP)
+	case "BA":
+		return network.BarabasiAlbert(config.BarabasiAlbertM)
+	case "CM":
+		return network.ConfigurationModel(config.DegreeSequence)
+	case "MR":
+		slowdown := config.SlowdownFactor
+		if config.DiscreteEvent {
+			slowdown = 1
+		}
+		return network.Clusters(clusterSizes(),
+			config.ClusterIntraNeighbors, config.ClusterInterLinks,
+			time.Duration(slowdown)*time.Duration(config.ClusterIntraDelayMs)*time.Millisecond,
+			time.Duration(slowdown)*time.Duration(config.ClusterInterDelayMs)*time.Millisecond,
+			config.ClusterInterPacketLoss)
+	default:
+		return network.WattsStrogatz(config.NeighbourCountWS, config.RandomnessWS)
+	}
+}
+
+// now returns the current simulation time: the virtual clock in discrete-event mode, wall time otherwise.
+func now() time.Time {
+	if config.DiscreteEvent {
+		return clock.Virtual.Now()
+	}
+	return time.Now()
+}
+
+// sinceStart returns the simulated time elapsed since the given instant.
+func sinceStart(t time.Time) time.Duration {
+	return now().Sub(t)
+}
+
+// metricsWarmupElapsed reports whether the configured warm-up period is over and metrics may be recorded.
+// In realtime mode the warm-up spans SlowdownFactor times its simulated duration, like all other delays.
+func metricsWarmupElapsed() bool {
+	if config.MetricsWarmup == 0 {
+		return true
+	}
+
+	warmup := time.Duration(config.MetricsWarmup) * time.Second
+	if !config.DiscreteEvent {
+		warmup *= time.Duration(config.SlowdownFactor)
+	}
+	return sinceStart(simulationStartTime) >= warmup
+}
+
+// Max returns the larger of x or y.
+func Max(x, y int64) int64 {
+	if x < y {
+		return y
+	}
+	return x
+}
+
+// ArgMax returns the max value of the array.
+func ArgMax(x []int64) int {
+	maxLocation := 0
+	currentMax := int64(x[0])
+	for i, v := range x[1:] {
+		if v > currentMax {
+			currentMax = v
+			maxLocation = i + 1
+		}
+	}
+	return maxLocation
+}
+
+// colorStatus renders one of the colorCounters as a "%3d Undefined / %3d Blue / ..." status segment.
+func colorStatus(flag string) string {
+	segments := []string{fmt.Sprintf("%3d Undefined", colorCounters.Get(flag, multiverse.UndefinedColor))}
+	for _, color := range multiverse.ConflictingColors() {
+		segments = append(segments, fmt.Sprintf("%3d %s", colorCounters.Get(flag, color), color.Name()))
+	}
+	return strings.Join(segments, " / ")
+}
+
+// getLikesPerColor returns the counter value of every conflicting color, indexed like
+// multiverse.ConflictingColors().
+func getLikesPerColor(counter *ColorCounters, flag string) (likes []int64) {
+	likes = make([]int64, 0, config.ConflictColorCount)
+	for _, color := range multiverse.ConflictingColors() {
+		likes = append(likes, counter.Get(flag, color))
+	}
+	return
+}
+
+func mostLikedColorChanged(likes []int64, mostLikedColorVar *multiverse.Color) bool {
+
+	// ties go to the larger color value, matching the opinion manager's tie breaker
+	currentMostLikedColor := multiverse.UndefinedColor
+	maxLikes := int64(0)
+	for i, color := range multiverse.ConflictingColors() {
+		if likes[i] > 0 && likes[i] >= maxLikes {
+			maxLikes = likes[i]
+			currentMostLikedColor = color
+		}
+	}
+	// color selected
+	if *mostLikedColorVar != currentMostLikedColor {
+		// color selected for the first time, it not counts
+		if *mostLikedColorVar == multiverse.UndefinedColor {
+			*mostLikedColorVar = currentMostLikedColor
+			return false
+		}
+		*mostLikedColorVar = currentMostLikedColor
+		return true
+	}
+	return false
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
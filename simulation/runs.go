@@ -0,0 +1,248 @@
+package simulation
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/iotaledger/multivers-simulation/config"
+)
+
+// region Runs /////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// RunRepetitions executes config.Runs independent repetitions of the current configuration with different
+// seeds in a pool of parallel child processes, then aggregates confirmation time, flip count and consensus
+// time across all runs into runs-aggregate.csv (mean, stddev and percentiles per metric).
+func RunRepetitions() {
+	if _, err := os.Stat(config.ResultDir); os.IsNotExist(err) {
+		if err = os.MkdirAll(config.ResultDir, 0700); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	workerCount := runtime.NumCPU()
+	if workerCount > config.Runs {
+		workerCount = config.Runs
+	}
+	log.Infof("Executing %d repetitions on %d workers", config.Runs, workerCount)
+
+	runDirs := make([]string, config.Runs)
+	runIndexes := make(chan int, config.Runs)
+	for runIndex := 0; runIndex < config.Runs; runIndex++ {
+		runIndexes <- runIndex
+	}
+	close(runIndexes)
+
+	var workersWg sync.WaitGroup
+	for worker := 0; worker < workerCount; worker++ {
+		workersWg.Add(1)
+		go func() {
+			defer workersWg.Done()
+			for runIndex := range runIndexes {
+				runDir := path.Join(config.ResultDir, fmt.Sprintf("run-%04d", runIndex))
+				if err := os.MkdirAll(runDir, 0700); err != nil {
+					log.Fatal(err)
+				}
+				runDirs[runIndex] = runDir
+
+				childArgs := stripFlags(os.Args[1:], "runs", "seed", "resultDir")
+				childArgs = append(childArgs,
+					fmt.Sprintf("-seed=%d", config.Seed+int64(runIndex)+1),
+					"-resultDir="+runDir)
+
+				log.Infof("Repetition %d/%d started", runIndex+1, config.Runs)
+				if err := runChild(childArgs, runDir); err != nil {
+					log.Warnf("Repetition %d failed: %s", runIndex, err)
+					runDirs[runIndex] = ""
+				}
+			}
+		}()
+	}
+	workersWg.Wait()
+
+	aggregateRuns(runDirs)
+	writeRunsSummary(runDirs)
+	log.Info("Repetitions finished ... [DONE]")
+}
+
+func aggregateRuns(runDirs []string) {
+	confirmationTimes := make([]float64, 0)
+	flipCounts := make([]float64, 0)
+	consensusTimes := make([]float64, 0)
+
+	for _, runDir := range runDirs {
+		if runDir == "" {
+			continue
+		}
+		confirmationTimes = append(confirmationTimes, readColumnValues(runDir, "aw0-", "Confirmation Time (ns)")...)
+		if flips, ok := lastColumnValue(runDir, "cc-", "Flips (Winning color changed)"); ok {
+			flipCounts = append(flipCounts, flips)
+		}
+		if consensusTime, ok := lastColumnValue(runDir, "cc-", "ns since start"); ok {
+			consensusTimes = append(consensusTimes, consensusTime)
+		}
+	}
+
+	aggregateFile, err := os.Create(path.Join(config.ResultDir, "runs-aggregate.csv"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer aggregateFile.Close()
+
+	aggregateWriter := csv.NewWriter(aggregateFile)
+	if err := aggregateWriter.Write([]string{"Metric", "Samples", "Mean", "Stddev", "Min", "P50", "P90", "P99", "Max"}); err != nil {
+		log.Fatal(err)
+	}
+	writeStatisticsRecord(aggregateWriter, "Confirmation Time (ns)", confirmationTimes)
+	writeStatisticsRecord(aggregateWriter, "Flips", flipCounts)
+	writeStatisticsRecord(aggregateWriter, "Consensus Time (ns)", consensusTimes)
+	aggregateWriter.Flush()
+	if err := aggregateWriter.Error(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writeStatisticsRecord(writer *csv.Writer, metric string, samples []float64) {
+	record := []string{
+		metric,
+		strconv.Itoa(len(samples)),
+		formatStatistic(Mean(samples)),
+		formatStatistic(Stddev(samples)),
+		formatStatistic(Percentile(samples, 0)),
+		formatStatistic(Percentile(samples, 50)),
+		formatStatistic(Percentile(samples, 90)),
+		formatStatistic(Percentile(samples, 99)),
+		formatStatistic(Percentile(samples, 100)),
+	}
+	if err := writer.Write(record); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func formatStatistic(value float64) string {
+	return strconv.FormatFloat(value, 'f', 3, 64)
+}
+
+// readColumnValues parses the given column of the first result file with the given prefix in the run directory.
+func readColumnValues(runDir, filePrefix, column string) (values []float64) {
+	reader, file := openResultFile(runDir, filePrefix)
+	if reader == nil {
+		return
+	}
+	defer file.Close()
+
+	columnIndex := -1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			log.Warnf("Failed to parse %s*.csv in %s: %s", filePrefix, runDir, err)
+			return
+		}
+
+		if columnIndex == -1 {
+			for i, header := range record {
+				if header == column {
+					columnIndex = i
+				}
+			}
+			if columnIndex == -1 {
+				log.Warnf("Column %s not found in %s*.csv in %s", column, filePrefix, runDir)
+				return
+			}
+			continue
+		}
+
+		if value, err := strconv.ParseFloat(record[columnIndex], 64); err == nil {
+			values = append(values, value)
+		}
+	}
+}
+
+// lastColumnValue returns the value of the given column in the last row of the matching result file.
+func lastColumnValue(runDir, filePrefix, column string) (value float64, ok bool) {
+	values := readColumnValues(runDir, filePrefix, column)
+	if len(values) == 0 {
+		return
+	}
+	return values[len(values)-1], true
+}
+
+func openResultFile(runDir, filePrefix string) (*csv.Reader, *os.File) {
+	entries, err := os.ReadDir(runDir)
+	if err != nil {
+		log.Warnf("Failed to read run directory %s: %s", runDir, err)
+		return nil, nil
+	}
+
+	for _, entry := range entries {
+		if len(entry.Name()) > len(filePrefix) && entry.Name()[:len(filePrefix)] == filePrefix && path.Ext(entry.Name()) == ".csv" {
+			file, err := os.Open(path.Join(runDir, entry.Name()))
+			if err != nil {
+				log.Warnf("Failed to open %s: %s", entry.Name(), err)
+				return nil, nil
+			}
+			return csv.NewReader(file), file
+		}
+	}
+	return nil, nil
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// region Statistics ///////////////////////////////////////////////////////////////////////////////////////////////////
+
+// Mean returns the arithmetic mean of the samples.
+func Mean(samples []float64) float64 {
+	if len(samples) == 0 {
+		return math.NaN()
+	}
+	sum := 0.0
+	for _, sample := range samples {
+		sum += sample
+	}
+	return sum / float64(len(samples))
+}
+
+// Stddev returns the sample standard deviation.
+func Stddev(samples []float64) float64 {
+	if len(samples) < 2 {
+		return math.NaN()
+	}
+	mean := Mean(samples)
+	sum := 0.0
+	for _, sample := range samples {
+		sum += (sample - mean) * (sample - mean)
+	}
+	return math.Sqrt(sum / float64(len(samples)-1))
+}
+
+// Percentile returns the p-th percentile (0-100) of the samples using linear interpolation.
+func Percentile(samples []float64, p float64) float64 {
+	if len(samples) == 0 {
+		return math.NaN()
+	}
+
+	sorted := make([]float64, len(samples))
+	copy(sorted, samples)
+	sort.Float64s(sorted)
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[upper]-sorted[lower])
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,234 @@
+package simulation
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/iotaledger/multivers-simulation/adversary"
+	"github.com/iotaledger/multivers-simulation/clock"
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region Scenario /////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// A Scenario scripts a timeline of events against the running network, going beyond the single
+// DoubleSpendDelay for multi-stage experiments. The YAML format lists events with an offset from the
+// simulation start:
+//
+//	events:
+//	  - at: 30s
+//	    action: doubleSpend
+//	    node: 5
+//	    color: R
+//	  - at: 60s
+//	    action: silence
+//	    group: 0
+//	  - at: 90s
+//	    action: resume
+//	    group: 0
+//
+// Supported actions are doubleSpend (issue a colored message), assign (set an adversary group's opinion),
+// silence (drop all outgoing traffic of the targeted nodes), resume (restore their packet loss), spam
+// (issue a one-off burst of AdversarySpamBurst messages per targeted node) and signal (send a named
+// coordination signal over the side channel).
+//
+// Cooperating adversary groups coordinate through a simulated low-latency side channel: a signal event
+// names a signal, and every handler listening on that name applies its own event once the signal
+// arrives sideChannelLatency later, e.g. group 0 announces its spam burst and group 1 shifts its
+// opinion in response:
+//
+//	sideChannelLatency: 5ms
+//	handlers:
+//	  - on: burstStarted
+//	    action: assign
+//	    group: 1
+//	    color: R
+type Scenario struct {
+	Events             []*ScenarioEvent `yaml:"events"`
+	SideChannelLatency string           `yaml:"sideChannelLatency"`
+	Handlers           []*SignalHandler `yaml:"handlers"`
+
+	sideChannelLatency time.Duration
+}
+
+// ScenarioEvent is one timed entry of the scenario. Either a single node or a whole adversary group can be
+// targeted.
+type ScenarioEvent struct {
+	At     string `yaml:"at"`
+	Action string `yaml:"action"`
+	Node   *int   `yaml:"node"`
+	Group  *int   `yaml:"group"`
+	Color  string `yaml:"color"`
+	Signal string `yaml:"signal"`
+
+	offset time.Duration
+}
+
+// SignalHandler reacts to a named coordination signal: once the signal reaches it over the side
+// channel, the embedded event is applied. Handlers cannot emit signals themselves, keeping the
+// coordination free of loops.
+type SignalHandler struct {
+	On            string `yaml:"on"`
+	ScenarioEvent `yaml:",inline"`
+}
+
+// LoadScenario parses and validates the scenario file.
+func LoadScenario(fileName string) (scenario *Scenario, err error) {
+	bytes, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario file: %w", err)
+	}
+
+	scenario = &Scenario{}
+	if err := yaml.Unmarshal(bytes, scenario); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario file: %w", err)
+	}
+
+	scenario.sideChannelLatency = time.Millisecond
+	if scenario.SideChannelLatency != "" {
+		if scenario.sideChannelLatency, err = time.ParseDuration(scenario.SideChannelLatency); err != nil {
+			return nil, fmt.Errorf("scenario has an invalid side channel latency %q: %w", scenario.SideChannelLatency, err)
+		}
+	}
+
+	for i, event := range scenario.Events {
+		if event.offset, err = time.ParseDuration(event.At); err != nil {
+			return nil, fmt.Errorf("scenario event %d has an invalid offset %q: %w", i, event.At, err)
+		}
+		switch event.Action {
+		case "doubleSpend", "assign", "silence", "resume", "spam":
+		case "signal":
+			if event.Signal == "" {
+				return nil, fmt.Errorf("scenario event %d emits a signal without a name", i)
+			}
+		default:
+			return nil, fmt.Errorf("scenario event %d has an unknown action %q", i, event.Action)
+		}
+		if event.Node == nil && event.Group == nil {
+			return nil, fmt.Errorf("scenario event %d targets neither a node nor a group", i)
+		}
+	}
+
+	for i, handler := range scenario.Handlers {
+		if handler.On == "" {
+			return nil, fmt.Errorf("scenario handler %d listens on no signal", i)
+		}
+		switch handler.Action {
+		case "doubleSpend", "assign", "silence", "resume", "spam":
+		default:
+			return nil, fmt.Errorf("scenario handler %d has an unknown action %q", i, handler.Action)
+		}
+		if handler.Node == nil && handler.Group == nil {
+			return nil, fmt.Errorf("scenario handler %d targets neither a node nor a group", i)
+		}
+	}
+
+	return scenario, nil
+}
+
+// Schedule queues all events of the scenario relative to the simulation start.
+func (s *Scenario) Schedule(testNetwork *network.Network) {
+	for _, event := range s.Events {
+		event := event
+		if config.DiscreteEvent {
+			clock.Virtual.ExecuteAfter(event.offset, func() { event.apply(testNetwork, s) })
+		} else {
+			time.AfterFunc(time.Duration(config.SlowdownFactor)*event.offset, func() { event.apply(testNetwork, s) })
+		}
+	}
+	log.Infof("Scheduled %d scenario events", len(s.Events))
+}
+
+// deliverSignal carries the named coordination signal over the simulated side channel: every handler
+// listening on the signal applies its event once the channel's latency has passed, letting one
+// adversary group react to another group's move without modeling attacker-to-attacker messages in the
+// regular network.
+func (s *Scenario) deliverSignal(signal string, testNetwork *network.Network) {
+	handled := 0
+	for _, handler := range s.Handlers {
+		if handler.On != signal {
+			continue
+		}
+		handled++
+		handler := handler
+		if config.DiscreteEvent {
+			clock.Virtual.ExecuteAfter(s.sideChannelLatency, func() { handler.ScenarioEvent.apply(testNetwork, s) })
+		} else {
+			time.AfterFunc(time.Duration(config.SlowdownFactor)*s.sideChannelLatency, func() { handler.ScenarioEvent.apply(testNetwork, s) })
+		}
+	}
+	if handled == 0 {
+		log.Warnf("Coordination signal %q has no handler listening", signal)
+		return
+	}
+	log.Infof("Coordination signal %q reaches %d handlers in %s", signal, handled, s.sideChannelLatency)
+}
+
+func (e *ScenarioEvent) apply(testNetwork *network.Network, scenario *Scenario) {
+	log.Infof("Scenario event at %s: %s", e.At, e.Action)
+
+	switch e.Action {
+	case "doubleSpend":
+		color := multiverse.ColorFromStr(e.Color)
+		if dsIssuanceTime.IsZero() {
+			dsIssuanceTime = now()
+		}
+		for _, peer := range e.targetPeers(testNetwork) {
+			sendMessage(peer, color)
+			log.Infof("Peer %d sent double spend msg: %v", peer.ID, color)
+		}
+	case "assign":
+		color := multiverse.ColorFromStr(e.Color)
+		for _, peer := range e.targetPeers(testNetwork) {
+			adversary.CastAdversary(peer.Node).AssignColor(color)
+		}
+	case "silence":
+		for _, peer := range e.targetPeers(testNetwork) {
+			for _, connection := range peer.Neighbors {
+				connection.SetPacketLoss(1)
+			}
+		}
+	case "resume":
+		for _, peer := range e.targetPeers(testNetwork) {
+			for _, connection := range peer.Neighbors {
+				connection.SetPacketLoss(config.PacketLoss)
+			}
+		}
+	case "spam":
+		issued := 0
+		for _, peer := range e.targetPeers(testNetwork) {
+			for i := 0; i < config.AdversarySpamBurst; i++ {
+				sendMessage(peer)
+			}
+			issued += config.AdversarySpamBurst
+		}
+		adversary.ChargeMessages(issued)
+		log.Infof("Scenario spam burst issued %d messages", issued)
+	case "signal":
+		scenario.deliverSignal(e.Signal, testNetwork)
+	}
+}
+
+// targetPeers resolves the node or adversary group reference of the event.
+func (e *ScenarioEvent) targetPeers(testNetwork *network.Network) (peers []*network.Peer) {
+	if e.Node != nil {
+		return []*network.Peer{testNetwork.Peer(*e.Node)}
+	}
+
+	if *e.Group >= len(testNetwork.AdversaryGroups) {
+		log.Warnf("Scenario event targets unknown adversary group %d", *e.Group)
+		return
+	}
+	for _, nodeID := range testNetwork.AdversaryGroups[*e.Group].NodeIDs {
+		peers = append(peers, testNetwork.Peer(nodeID))
+	}
+
+	return
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
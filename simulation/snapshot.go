@@ -0,0 +1,63 @@
+package simulation
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region Snapshot export/import ///////////////////////////////////////////////////////////////////////////////////////
+
+// The snapshot file captures the confirmed state of the observed peer's tangle as JSON, see
+// multiverse.Snapshot. A later run importing it starts every node from that state, so the network is
+// warm before the first live message is issued.
+
+// exportSnapshot writes the confirmed state of the first monitored AW peer to SnapshotExportFile.
+func exportSnapshot(testNetwork *network.Network) {
+	monitoredPeer := testNetwork.Peer(config.MonitoredAWPeers[0])
+	snapshot := monitoredPeer.Node.(multiverse.NodeInterface).Tangle().ExportSnapshot()
+
+	snapshotJSON, err := json.MarshalIndent(snapshot, "", " ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(config.SnapshotExportFile, snapshotJSON, 0o644); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Infof("Exported snapshot with %d confirmed messages to %s ... [DONE]", len(snapshot.Messages), config.SnapshotExportFile)
+}
+
+// importSnapshot warm-starts the tangle of every node from SnapshotImportFile.
+func importSnapshot(testNetwork *network.Network) {
+	snapshotJSON, err := os.ReadFile(config.SnapshotImportFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	snapshot := &multiverse.Snapshot{}
+	if err := json.Unmarshal(snapshotJSON, snapshot); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, peer := range testNetwork.Peers {
+		peer.Node.(multiverse.NodeInterface).Tangle().ImportSnapshot(snapshot)
+	}
+
+	log.Infof("Imported snapshot with %d confirmed messages into %d nodes ... [DONE]", len(snapshot.Messages), len(testNetwork.Peers))
+}
+
+// applyGenesisSet gives every node the same preassigned colored genesis messages, so the run starts
+// mid-conflict instead of from the single uncolored genesis, see multiverse.BuildGenesisSet.
+func applyGenesisSet(testNetwork *network.Network) {
+	genesisSet := multiverse.BuildGenesisSet()
+	for _, peer := range testNetwork.Peers {
+		peer.Node.(multiverse.NodeInterface).Tangle().ApplyGenesisSet(genesisSet)
+	}
+
+	log.Infof("Applied %d genesis messages to %d nodes ... [DONE]", len(genesisSet), len(testNetwork.Peers))
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,107 @@
+package simulation
+
+import (
+	"strings"
+	"time"
+
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+)
+
+// region Stop conditions //////////////////////////////////////////////////////////////////////////////////////////////
+
+// A StopCondition is evaluated once per ConsensusMonitorTick after the records have been dumped; the run
+// ends as soon as any of the conditions enabled via config.StopConditions is met. Custom conditions can be
+// added to the registry with RegisterStopCondition before Run is called.
+type StopCondition func(honestNodesCount int) bool
+
+var stopConditionRegistry = map[string]StopCondition{
+	"consensus":        consensusCondition,
+	"adversarySuccess": adversarySuccessCondition,
+	"wallClock":        wallClockCondition,
+	"metastability":    metastabilityCondition,
+	"maxMessages":      maxMessagesCondition,
+}
+
+// RegisterStopCondition makes a custom condition selectable via config.StopConditions.
+func RegisterStopCondition(name string, condition StopCondition) {
+	stopConditionRegistry[name] = condition
+}
+
+// stopConditionMet evaluates the enabled conditions and returns the name of the first one that is met.
+func stopConditionMet(honestNodesCount int) (name string, met bool) {
+	for _, name := range strings.Split(config.StopConditions, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		condition, exists := stopConditionRegistry[name]
+		if !exists {
+			log.Warnf("Unknown stop condition %s", name)
+			continue
+		}
+		if condition(honestNodesCount) {
+			return name, true
+		}
+	}
+
+	return
+}
+
+// consensusCondition is the classic threshold check: more than SimulationStopThreshold of the honest nodes
+// have confirmed the same color.
+func consensusCondition(honestNodesCount int) bool {
+	confirmed := getLikesPerColor(colorCounters, "confirmedNodes")
+	adversaryConfirmed := getLikesPerColor(adversaryCounters, "confirmedNodes")
+
+	maxHonestConfirmed := int64(0)
+	for i := range confirmed {
+		maxHonestConfirmed = Max(maxHonestConfirmed, confirmed[i]-adversaryConfirmed[i])
+	}
+
+	return maxHonestConfirmed >= int64(config.SimulationStopThreshold*float64(honestNodesCount))
+}
+
+// adversarySuccessCondition is met when the honest confirmations of the first adversary group's initial
+// color alone cross the stop threshold, i.e. the attacked color won.
+func adversarySuccessCondition(honestNodesCount int) bool {
+	if len(config.AdversaryInitColors) == 0 {
+		return false
+	}
+
+	adversaryColor := multiverse.ColorFromStr(config.AdversaryInitColors[0])
+	honestConfirmed := colorCounters.Get("confirmedNodes", adversaryColor) - adversaryCounters.Get("confirmedNodes", adversaryColor)
+
+	return honestConfirmed >= int64(config.SimulationStopThreshold*float64(honestNodesCount))
+}
+
+// wallClockCondition limits the real time a run may take, independently of the simulated time.
+func wallClockCondition(honestNodesCount int) bool {
+	if config.WallClockLimit == 0 {
+		return false
+	}
+
+	return time.Since(wallClockStart) >= time.Duration(config.WallClockLimit)*time.Second
+}
+
+// metastabilityCondition aborts runs in which the winning color keeps flipping between the honest nodes
+// instead of converging.
+func metastabilityCondition(honestNodesCount int) bool {
+	if config.MetastabilityFlips == 0 {
+		return false
+	}
+
+	return atomicCounters.Get("honestFlips") >= int64(config.MetastabilityFlips)
+}
+
+// maxMessagesCondition stops the run after a fixed number of issued messages, so TSA variants can be
+// compared on identical workloads instead of identical durations.
+func maxMessagesCondition(honestNodesCount int) bool {
+	if config.MaxIssuedMessages == 0 {
+		return false
+	}
+
+	return atomicCounters.Get("issuedMessages") >= int64(config.MaxIssuedMessages)
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,177 @@
+package simulation
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/iotaledger/multivers-simulation/config"
+)
+
+// region Sweep ////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// RunSweep executes the full cartesian product of the swept parameter values by re-running the simulator
+// binary once per combination. Each run writes into its own subdirectory of ResultDir and an index manifest
+// (sweep-index.csv) maps run directories to parameter values. The child process approach keeps runs fully
+// isolated - package level counters (peer IDs, adversary group maps) are not reusable within one process.
+//
+// The sweep specification assigns each flag a list or range of values, e.g.:
+//
+//	-sweep "zipfParameter=0.5,0.9,1.2;packetLoss=0:0.2:0.05"
+//
+// where a range is given as start:stop:step (stop inclusive).
+func RunSweep() {
+	parameters, valueLists := parseSweep(config.Sweep)
+	combinations := buildCombinations(valueLists)
+	log.Infof("Sweeping %d parameters, %d combinations", len(parameters), len(combinations))
+
+	if _, err := os.Stat(config.ResultDir); os.IsNotExist(err) {
+		if err = os.MkdirAll(config.ResultDir, 0700); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	indexFile, err := os.Create(path.Join(config.ResultDir, "sweep-index.csv"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer indexFile.Close()
+	indexWriter := csv.NewWriter(indexFile)
+	if err := indexWriter.Write(append(append([]string{"Run", "Directory"}, parameters...), "Status")); err != nil {
+		log.Fatal(err)
+	}
+
+	for runIndex, combination := range combinations {
+		runDir := path.Join(config.ResultDir, fmt.Sprintf("sweep-%04d", runIndex))
+		if err := os.MkdirAll(runDir, 0700); err != nil {
+			log.Fatal(err)
+		}
+
+		childArgs := stripFlags(os.Args[1:], "sweep", "resultDir")
+		for i, parameter := range parameters {
+			childArgs = append(childArgs, fmt.Sprintf("-%s=%s", parameter, combination[i]))
+		}
+		childArgs = append(childArgs, "-resultDir="+runDir)
+
+		log.Infof("Sweep run %d/%d: %v", runIndex+1, len(combinations), combination)
+		status := "OK"
+		if err := runChild(childArgs, runDir); err != nil {
+			log.Warnf("Sweep run %d failed: %s", runIndex, err)
+			status = err.Error()
+		}
+
+		record := append(append([]string{strconv.Itoa(runIndex), runDir}, combination...), status)
+		if err := indexWriter.Write(record); err != nil {
+			log.Fatal(err)
+		}
+		indexWriter.Flush()
+	}
+
+	if err := indexWriter.Error(); err != nil {
+		log.Fatal(err)
+	}
+	log.Info("Sweep finished ... [DONE]")
+}
+
+func runChild(args []string, runDir string) error {
+	logFile, err := os.Create(path.Join(runDir, "run.log"))
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	child := exec.Command(os.Args[0], args...)
+	child.Stdout = logFile
+	child.Stderr = logFile
+	return child.Run()
+}
+
+// parseSweep splits "a=1,2;b=0:1:0.5" into parameter names and their expanded value lists.
+func parseSweep(sweep string) (parameters []string, valueLists [][]string) {
+	for _, assignment := range strings.Split(sweep, ";") {
+		parts := strings.SplitN(assignment, "=", 2)
+		if len(parts) != 2 {
+			log.Fatalf("Invalid sweep assignment: %s", assignment)
+		}
+		parameters = append(parameters, strings.TrimSpace(parts[0]))
+		valueLists = append(valueLists, expandValues(strings.TrimSpace(parts[1])))
+	}
+	return
+}
+
+// expandValues turns either a comma separated list or a start:stop:step range into explicit values.
+func expandValues(values string) []string {
+	if strings.Contains(values, ":") {
+		parts := strings.Split(values, ":")
+		if len(parts) != 3 {
+			log.Fatalf("Invalid sweep range (want start:stop:step): %s", values)
+		}
+		start, err1 := strconv.ParseFloat(parts[0], 64)
+		stop, err2 := strconv.ParseFloat(parts[1], 64)
+		step, err3 := strconv.ParseFloat(parts[2], 64)
+		if err1 != nil || err2 != nil || err3 != nil || step <= 0 {
+			log.Fatalf("Invalid sweep range: %s", values)
+		}
+
+		expanded := make([]string, 0)
+		// the small epsilon keeps the inclusive stop value despite float rounding
+		for value := start; value <= stop+step/1e6; value += step {
+			expanded = append(expanded, strconv.FormatFloat(value, 'f', -1, 64))
+		}
+		return expanded
+	}
+
+	return strings.Split(values, ",")
+}
+
+// buildCombinations returns the cartesian product of the value lists.
+func buildCombinations(valueLists [][]string) (combinations [][]string) {
+	combinations = [][]string{{}}
+	for _, values := range valueLists {
+		next := make([][]string, 0, len(combinations)*len(values))
+		for _, combination := range combinations {
+			for _, value := range values {
+				extended := make([]string, len(combination), len(combination)+1)
+				copy(extended, combination)
+				next = append(next, append(extended, value))
+			}
+		}
+		combinations = next
+	}
+	return
+}
+
+// stripFlags removes the given flags (in both "-name value" and "-name=value" form) from the argument list.
+func stripFlags(args []string, names ...string) (stripped []string) {
+	skipNext := false
+	for _, arg := range args {
+		if skipNext {
+			skipNext = false
+			continue
+		}
+
+		matched := false
+		for _, name := range names {
+			trimmed := strings.TrimLeft(arg, "-")
+			if trimmed == name {
+				matched = true
+				skipNext = true
+				break
+			}
+			if strings.HasPrefix(trimmed, name+"=") {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			stripped = append(stripped, arg)
+		}
+	}
+	return
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
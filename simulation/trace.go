@@ -0,0 +1,183 @@
+package simulation
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region Trace recording //////////////////////////////////////////////////////////////////////////////////////////////
+
+// The trace file records every message entering a node's tangle (own issuances as well as deliveries from
+// neighbors) with its timestamp and full message content. A recorded trace can be replayed through the
+// tangle logic with different analysis parameters (e.g. another confirmation threshold) without re-running
+// the network layer, reproducing the exact same run.
+
+var (
+	traceFile   *os.File
+	traceWriter *csv.Writer
+	traceStart  time.Time
+	traceMutex  sync.Mutex
+)
+
+var traceHeader = []string{"Time (ns)", "Peer ID", "Message ID", "Issuer", "Sequence Number", "Payload",
+	"Issuance Time (unix ns)", "Strong Parents", "Weak Parents"}
+
+// StartTraceRecording installs the tangle hook that streams all issuance and delivery events to the file.
+func StartTraceRecording(fileName string) {
+	file, err := os.Create(fileName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	traceFile = file
+	traceWriter = csv.NewWriter(file)
+	traceStart = time.Now()
+	if err := traceWriter.Write(traceHeader); err != nil {
+		log.Fatal(err)
+	}
+
+	multiverse.MessageProcessedTraceHook = recordTraceEvent
+	log.Infof("Recording event trace to %s", fileName)
+}
+
+// StopTraceRecording flushes and closes the trace file.
+func StopTraceRecording() {
+	traceMutex.Lock()
+	defer traceMutex.Unlock()
+
+	if traceWriter == nil {
+		return
+	}
+	multiverse.MessageProcessedTraceHook = nil
+	traceWriter.Flush()
+	if err := traceWriter.Error(); err != nil {
+		log.Error(err)
+	}
+	if err := traceFile.Close(); err != nil {
+		log.Error(err)
+	}
+	traceWriter = nil
+}
+
+func recordTraceEvent(peerID network.PeerID, message *multiverse.Message) {
+	traceMutex.Lock()
+	defer traceMutex.Unlock()
+
+	if traceWriter == nil {
+		return
+	}
+
+	record := []string{
+		strconv.FormatInt(time.Since(traceStart).Nanoseconds(), 10),
+		strconv.FormatInt(int64(peerID), 10),
+		strconv.FormatInt(int64(message.ID), 10),
+		strconv.FormatInt(int64(message.Issuer), 10),
+		strconv.FormatUint(message.SequenceNumber, 10),
+		strconv.FormatInt(int64(message.Payload), 10),
+		strconv.FormatInt(message.IssuanceTime.UnixNano(), 10),
+		formatMessageIDs(message.StrongParents),
+		formatMessageIDs(message.WeakParents),
+	}
+	if err := traceWriter.Write(record); err != nil {
+		log.Fatal("error writing trace record:", err)
+	}
+}
+
+func formatMessageIDs(messageIDs multiverse.MessageIDs) string {
+	ids := make([]string, 0, len(messageIDs))
+	for messageID := range messageIDs {
+		ids = append(ids, strconv.FormatInt(int64(messageID), 10))
+	}
+	return strings.Join(ids, " ")
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// region Trace replay /////////////////////////////////////////////////////////////////////////////////////////////////
+
+// ReplayTraceFile feeds a recorded trace back through the tangles of the network's peers in the recorded
+// order. Gossip is suppressed during replay (see multiverse.Node.Setup), so only the recorded events drive
+// the run.
+func ReplayTraceFile(fileName string, testNetwork *network.Network) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	if _, err := reader.Read(); err != nil { // skip the header
+		log.Fatal(err)
+	}
+
+	messageCache := make(map[multiverse.MessageID]*multiverse.Message)
+	replayedEvents := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		peerID, _ := strconv.ParseInt(record[1], 10, 64)
+		message := parseTraceMessage(record, messageCache)
+
+		if int(peerID) >= config.NodesCount {
+			log.Warnf("Trace event for unknown peer %d skipped (trace recorded with more nodes?)", peerID)
+			continue
+		}
+		testNetwork.Peer(int(peerID)).Node.(multiverse.NodeInterface).Tangle().ProcessMessage(message)
+		replayedEvents++
+	}
+
+	log.Infof("Replayed %d trace events ... [DONE]", replayedEvents)
+}
+
+func parseTraceMessage(record []string, messageCache map[multiverse.MessageID]*multiverse.Message) *multiverse.Message {
+	messageID, _ := strconv.ParseInt(record[2], 10, 64)
+	if message, exists := messageCache[multiverse.MessageID(messageID)]; exists {
+		return message
+	}
+
+	issuer, _ := strconv.ParseInt(record[3], 10, 64)
+	sequenceNumber, _ := strconv.ParseUint(record[4], 10, 64)
+	payload, _ := strconv.ParseInt(record[5], 10, 64)
+	issuanceTime, _ := strconv.ParseInt(record[6], 10, 64)
+
+	message := &multiverse.Message{
+		ID:             multiverse.MessageID(messageID),
+		StrongParents:  parseMessageIDs(record[7]),
+		WeakParents:    parseMessageIDs(record[8]),
+		SequenceNumber: sequenceNumber,
+		Issuer:         network.PeerID(issuer),
+		Payload:        multiverse.Color(payload),
+		IssuanceTime:   time.Unix(0, issuanceTime),
+	}
+	messageCache[message.ID] = message
+
+	return message
+}
+
+func parseMessageIDs(ids string) multiverse.MessageIDs {
+	messageIDs := multiverse.NewMessageIDs()
+	if ids == "" {
+		return messageIDs
+	}
+	for _, id := range strings.Split(ids, " ") {
+		parsedID, _ := strconv.ParseInt(id, 10, 64)
+		messageIDs.Add(multiverse.MessageID(parsedID))
+	}
+	return messageIDs
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,396 @@
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/iotaledger/multivers-simulation/adversary"
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region Config validation ////////////////////////////////////////////////////////////////////////////////////////////
+
+// ValidateConfig checks the invariants of the effective configuration, builds the weight distribution and
+// the topology once to surface construction errors, and prints the effective configuration without running
+// the simulation. It is the implementation of the -validate dry-run flag.
+func ValidateConfig() {
+	errors := configErrors()
+
+	if len(errors) == 0 {
+		// building the network exercises the weight distribution and the topology generation, which can
+		// fail in ways the static checks above cannot see
+		testNetwork := network.New(
+			network.Nodes(config.NodesCount, adversary.NodeFactories(), network.ZIPFDistribution(config.ZipfParameter)),
+			network.Topology(topologyStrategy()),
+			network.AdversaryPeeringAll(config.AdversaryPeeringAll),
+			network.AdversarySpeedup(config.AdversarySpeedup),
+			network.CompositeNodes(adversary.NewCompositeNode),
+		)
+		log.Infof("Weight distribution: total weight %d, largest weight %d",
+			config.NodesTotalWeight, testNetwork.WeightDistribution.LargestWeight())
+	}
+
+	effectiveConfig, err := json.MarshalIndent(CurrentConfig(), "", " ")
+	if err != nil {
+		log.Error(err)
+	} else {
+		fmt.Println(string(effectiveConfig))
+	}
+
+	for _, validationError := range errors {
+		log.Errorf("Invalid configuration: %s", validationError)
+	}
+	if len(errors) > 0 {
+		log.Fatalf("Configuration is invalid (%d errors)", len(errors))
+	}
+	log.Info("Configuration is valid ... [DONE]")
+}
+
+// ListStrategies prints the registered adversary strategies with their type codes, the values
+// AdversaryTypes selects from. It is the implementation of the -listStrategies flag.
+func ListStrategies() {
+	for _, strategy := range adversary.Strategies() {
+		fmt.Println(strategy)
+	}
+}
+
+// configErrors returns all violated invariants of the current configuration.
+func configErrors() (errors []string) {
+	if config.NodesCount <= 0 {
+		errors = append(errors, fmt.Sprintf("nodesCount must be positive, got %d", config.NodesCount))
+	}
+	if config.ParentsCount < 1 {
+		errors = append(errors, fmt.Sprintf("parentsCount must be at least 1, got %d", config.ParentsCount))
+	}
+	switch config.ParentsCountPolicy {
+	case "upto", "exact", "adaptive":
+	default:
+		errors = append(errors, fmt.Sprintf("unknown parents count policy %q", config.ParentsCountPolicy))
+	}
+	if config.ParentsCountPolicy == "adaptive" && config.AdaptiveParentsTipsPerParent < 1 {
+		errors = append(errors, fmt.Sprintf("adaptiveParentsTipsPerParent must be at least 1, got %d", config.AdaptiveParentsTipsPerParent))
+	}
+	for _, id := range config.MonitoredAWPeers {
+		if id < 0 || id >= config.NodesCount {
+			errors = append(errors, fmt.Sprintf("monitored AW peer %d is outside the node range [0, %d)", id, config.NodesCount))
+		}
+	}
+	if config.MonitoredWitnessWeightPeer < 0 || config.MonitoredWitnessWeightPeer >= config.NodesCount {
+		errors = append(errors, fmt.Sprintf("monitored witness weight peer %d is outside the node range [0, %d)", config.MonitoredWitnessWeightPeer, config.NodesCount))
+	}
+	if config.MinDelay > config.MaxDelay {
+		errors = append(errors, fmt.Sprintf("minDelay %d exceeds maxDelay %d", config.MinDelay, config.MaxDelay))
+	}
+	if config.PacketLoss < 0 || config.PacketLoss > 1 {
+		errors = append(errors, fmt.Sprintf("packetLoss must be within [0, 1], got %f", config.PacketLoss))
+	}
+	if config.NatFraction < 0 || config.NatFraction > 1 {
+		errors = append(errors, fmt.Sprintf("natFraction must be within [0, 1], got %f", config.NatFraction))
+	}
+	if config.SimulationStopThreshold <= 0 || config.SimulationStopThreshold > 1 {
+		errors = append(errors, fmt.Sprintf("simulationStopThreshold must be within (0, 1], got %f", config.SimulationStopThreshold))
+	}
+	if config.ConflictColorCount < 2 {
+		errors = append(errors, fmt.Sprintf("conflictColorCount must be at least 2, got %d", config.ConflictColorCount))
+	}
+	if config.ConflictSetCount < 1 {
+		errors = append(errors, fmt.Sprintf("conflictSetCount must be at least 1, got %d", config.ConflictSetCount))
+	}
+	for i, fraction := range config.ConfirmationGrades {
+		if fraction <= 0 || fraction > 1 {
+			errors = append(errors, fmt.Sprintf("confirmation grade fractions must be within (0, 1], got %f", fraction))
+		}
+		if i > 0 && fraction <= config.ConfirmationGrades[i-1] {
+			errors = append(errors, fmt.Sprintf("confirmation grade fractions must be ascending, got %f after %f", fraction, config.ConfirmationGrades[i-1]))
+		}
+	}
+	if config.CoordinatorEnabled && config.MilestoneInterval < 1 {
+		errors = append(errors, fmt.Sprintf("milestoneInterval must be at least 1, got %d", config.MilestoneInterval))
+	}
+	switch config.ConfirmationRule {
+	case "WW", "AW", "Milestone", "Hybrid":
+	default:
+		errors = append(errors, fmt.Sprintf("unknown confirmation rule %s, valid rules are WW, AW, Milestone and Hybrid", config.ConfirmationRule))
+	}
+	if config.ConfirmationRule == "Milestone" && !config.CoordinatorEnabled {
+		errors = append(errors, "the Milestone confirmation rule requires coordinatorEnabled, nothing would ever confirm without it")
+	}
+	if config.ConfirmationWWFraction <= 0 || config.ConfirmationWWFraction > 1 {
+		errors = append(errors, fmt.Sprintf("confirmationWWFraction must be within (0, 1], got %f", config.ConfirmationWWFraction))
+	}
+	if config.ConfirmationRule == "AW" && config.ConfirmationAbsoluteWeight < 1 {
+		errors = append(errors, fmt.Sprintf("confirmationAbsoluteWeight must be at least 1, got %d", config.ConfirmationAbsoluteWeight))
+	}
+	if config.ConfirmationRule == "Hybrid" && config.ConfirmationMinAge < 1 {
+		errors = append(errors, fmt.Sprintf("confirmationMinAge must be at least 1, got %d", config.ConfirmationMinAge))
+	}
+	if config.MarkerBasedAW && config.MarkerSpacing < 1 {
+		errors = append(errors, fmt.Sprintf("markerSpacing must be at least 1, got %d", config.MarkerSpacing))
+	}
+	if config.FPCVotingEnabled {
+		if config.FPCRoundInterval < 1 {
+			errors = append(errors, fmt.Sprintf("fpcRoundInterval must be at least 1, got %d", config.FPCRoundInterval))
+		}
+		if config.FPCQuerySampleSize < 1 {
+			errors = append(errors, fmt.Sprintf("fpcQuerySampleSize must be at least 1, got %d", config.FPCQuerySampleSize))
+		}
+		if config.FPCThreshold < 0.5 || config.FPCThreshold >= 1 {
+			errors = append(errors, fmt.Sprintf("fpcThreshold must be within [0.5, 1), got %f", config.FPCThreshold))
+		}
+		if config.FPCThresholdUpper < config.FPCThreshold || config.FPCThresholdUpper >= 1 {
+			errors = append(errors, fmt.Sprintf("fpcThresholdUpper must be within [fpcThreshold, 1), got %f", config.FPCThresholdUpper))
+		}
+		if config.FPCFinalizationRounds < 1 {
+			errors = append(errors, fmt.Sprintf("fpcFinalizationRounds must be at least 1, got %d", config.FPCFinalizationRounds))
+		}
+	}
+	if config.RequesterBaseInterval < 1 {
+		errors = append(errors, fmt.Sprintf("requesterBaseInterval must be at least 1, got %d", config.RequesterBaseInterval))
+	}
+	if config.RequesterTimeout < config.RequesterBaseInterval {
+		errors = append(errors, fmt.Sprintf("requesterTimeout %d is shorter than requesterBaseInterval %d", config.RequesterTimeout, config.RequesterBaseInterval))
+	}
+	if config.SchedulerEnabled {
+		if config.SchedulerRate < 1 {
+			errors = append(errors, fmt.Sprintf("schedulerRate must be at least 1, got %d", config.SchedulerRate))
+		}
+		if config.SchedulerInterval < 1 {
+			errors = append(errors, fmt.Sprintf("schedulerInterval must be at least 1, got %d", config.SchedulerInterval))
+		}
+	}
+	if config.PruningEnabled {
+		if config.PruningAge < 1 {
+			errors = append(errors, fmt.Sprintf("pruningAge must be at least 1, got %d", config.PruningAge))
+		}
+		if config.PruningInterval < 1 {
+			errors = append(errors, fmt.Sprintf("pruningInterval must be at least 1, got %d", config.PruningInterval))
+		}
+	}
+	if config.EpochCommitments && config.EpochDuration < 1 {
+		errors = append(errors, fmt.Sprintf("epochDuration must be at least 1, got %d", config.EpochDuration))
+	}
+	if config.DynamicWeightsEnabled {
+		if config.EpochDuration < 1 {
+			errors = append(errors, fmt.Sprintf("epochDuration must be at least 1, got %d", config.EpochDuration))
+		}
+		if config.ManaDecayPerEpoch < 0 || config.ManaDecayPerEpoch >= 1 {
+			errors = append(errors, fmt.Sprintf("manaDecayPerEpoch must be within [0, 1), got %f", config.ManaDecayPerEpoch))
+		}
+	}
+	if config.RateSetterEnabled {
+		if config.RateSetterInterval < 1 {
+			errors = append(errors, fmt.Sprintf("rateSetterInterval must be at least 1, got %d", config.RateSetterInterval))
+		}
+		if config.RateSetterIncrease <= 0 {
+			errors = append(errors, fmt.Sprintf("rateSetterIncrease must be positive, got %f", config.RateSetterIncrease))
+		}
+		if config.RateSetterDecrease <= 0 || config.RateSetterDecrease >= 1 {
+			errors = append(errors, fmt.Sprintf("rateSetterDecrease must be within (0, 1), got %f", config.RateSetterDecrease))
+		}
+		if config.RateSetterCongestionThreshold < 1 {
+			errors = append(errors, fmt.Sprintf("rateSetterCongestionThreshold must be at least 1, got %d", config.RateSetterCongestionThreshold))
+		}
+		if config.RateSetterMin <= 0 || config.RateSetterMin > 1 {
+			errors = append(errors, fmt.Sprintf("rateSetterMin must be within (0, 1], got %f", config.RateSetterMin))
+		}
+	}
+	if config.AdaptivePowEnabled {
+		if config.RateSetterEnabled {
+			errors = append(errors, "adaptivePowEnabled and rateSetterEnabled are alternative rate-control mechanisms, enable at most one")
+		}
+		if config.AdaptivePowInterval < 1 {
+			errors = append(errors, fmt.Sprintf("adaptivePowInterval must be at least 1, got %d", config.AdaptivePowInterval))
+		}
+		if config.AdaptivePowGamma <= 0 {
+			errors = append(errors, fmt.Sprintf("adaptivePowGamma must be positive, got %f", config.AdaptivePowGamma))
+		}
+		if config.AdaptivePowMin <= 0 || config.AdaptivePowMin > 1 {
+			errors = append(errors, fmt.Sprintf("adaptivePowMin must be within (0, 1], got %f", config.AdaptivePowMin))
+		}
+	}
+	switch config.TSA {
+	case "POW", "URTS", "RURTS", "MRTS":
+	default:
+		errors = append(errors, fmt.Sprintf("unknown tip selection algorithm %q", config.TSA))
+	}
+	if config.TSA == "RURTS" && config.DeltaURTS <= 0 {
+		errors = append(errors, fmt.Sprintf("deltaURTS must be positive with the RURTS tip selection, got %f", config.DeltaURTS))
+	}
+	if config.OrphanageHorizon < 1 {
+		errors = append(errors, fmt.Sprintf("orphanageHorizon must be at least 1, got %d", config.OrphanageHorizon))
+	}
+	if config.ReattachmentEnabled {
+		if config.ReattachmentDeadline < 1 {
+			errors = append(errors, fmt.Sprintf("reattachmentDeadline must be at least 1, got %d", config.ReattachmentDeadline))
+		}
+		if config.ReattachmentInterval < 1 {
+			errors = append(errors, fmt.Sprintf("reattachmentInterval must be at least 1, got %d", config.ReattachmentInterval))
+		}
+	}
+	switch config.Topology {
+	case "WS", "ER", "BA", "CM", "MR":
+	default:
+		errors = append(errors, fmt.Sprintf("unknown topology %q", config.Topology))
+	}
+	if config.Topology == "CM" && len(config.DegreeSequence) != config.NodesCount {
+		errors = append(errors, fmt.Sprintf("the degree sequence has %d entries for %d nodes", len(config.DegreeSequence), config.NodesCount))
+	}
+	if config.ErdosRenyiP <= 0 || config.ErdosRenyiP > 1 {
+		errors = append(errors, fmt.Sprintf("erdosRenyiP must be within (0, 1], got %f", config.ErdosRenyiP))
+	}
+	if config.BarabasiAlbertM < 1 || config.BarabasiAlbertM >= config.NodesCount {
+		errors = append(errors, fmt.Sprintf("barabasiAlbertM must be within [1, nodesCount), got %d", config.BarabasiAlbertM))
+	}
+	switch strings.Split(config.DelayDistribution, ":")[0] {
+	case "uniform", "lognormal", "pareto", "bimodal":
+	default:
+		errors = append(errors, fmt.Sprintf("unknown delay distribution %q", config.DelayDistribution))
+	}
+	switch strings.Split(config.LossDistribution, ":")[0] {
+	case "uniform", "exponential", "bimodal":
+	default:
+		errors = append(errors, fmt.Sprintf("unknown loss distribution %q", config.LossDistribution))
+	}
+	for name, probability := range map[string]float64{
+		"burstLossPGoodToBad": config.BurstLossPGoodToBad,
+		"burstLossPBadToGood": config.BurstLossPBadToGood,
+		"burstLossBad":        config.BurstLossBad,
+	} {
+		if probability < 0 || probability > 1 {
+			errors = append(errors, fmt.Sprintf("%s must be within [0, 1], got %f", name, probability))
+		}
+	}
+	switch config.GossipMode {
+	case "flood", "fanout", "pull":
+	default:
+		errors = append(errors, fmt.Sprintf("unknown gossip mode %q", config.GossipMode))
+	}
+	if config.GossipMode != "flood" && config.GossipFanout < 1 {
+		errors = append(errors, fmt.Sprintf("gossipFanout must be at least 1, got %d", config.GossipFanout))
+	}
+	if config.AdversarySpamInterval > 0 && config.AdversarySpamBurst < 1 {
+		errors = append(errors, fmt.Sprintf("adversarySpamBurst must be at least 1 when bursting is enabled, got %d", config.AdversarySpamBurst))
+	}
+	if config.AdversaryReplayInterval > 0 && config.AdversaryReplayBatch < 1 {
+		errors = append(errors, fmt.Sprintf("adversaryReplayBatch must be at least 1 when replaying is enabled, got %d", config.AdversaryReplayBatch))
+	}
+	if config.AdversaryBudget > 0 {
+		for name, cost := range map[string]float64{
+			"adversaryCostMessage":    config.AdversaryCostMessage,
+			"adversaryCostRelease":    config.AdversaryCostRelease,
+			"adversaryCostConnection": config.AdversaryCostConnection,
+		} {
+			if cost < 0 {
+				errors = append(errors, fmt.Sprintf("%s must not be negative, got %f", name, cost))
+			}
+		}
+	}
+	if config.DetectionMetrics && config.DetectionOverRateTolerance <= 0 {
+		errors = append(errors, fmt.Sprintf("detectionOverRateTolerance must be positive, got %f", config.DetectionOverRateTolerance))
+	}
+	if config.PeerScoringMaxInvalidRatio < 0 || config.PeerScoringMaxInvalidRatio > 1 {
+		errors = append(errors, fmt.Sprintf("peerScoringMaxInvalidRatio must be within [0, 1], got %f", config.PeerScoringMaxInvalidRatio))
+	}
+
+	adversaryManaSum := 0.0
+	for _, mana := range config.AdversaryMana {
+		if mana > 0 {
+			adversaryManaSum += mana
+		}
+	}
+	if config.AdversaryDropRate < 0 || config.AdversaryDropRate > 1 {
+		errors = append(errors, fmt.Sprintf("adversaryDropRate must be within [0, 1], got %f", config.AdversaryDropRate))
+	}
+	if config.AdversaryVictimBias < 0 || config.AdversaryVictimBias > 1 {
+		errors = append(errors, fmt.Sprintf("adversaryVictimBias must be within [0, 1], got %f", config.AdversaryVictimBias))
+	}
+	for _, id := range config.AdversaryVictimNodes {
+		if id < 0 || id >= config.NodesCount {
+			errors = append(errors, fmt.Sprintf("adversary victim node %d is outside the node range [0, %d)", id, config.NodesCount))
+		}
+	}
+	for _, composition := range config.AdversaryCompositions {
+		for _, code := range strings.Split(composition, "+") {
+			if _, err := strconv.Atoi(code); err != nil {
+				errors = append(errors, fmt.Sprintf("adversary composition %q contains the invalid strategy code %q", composition, code))
+			}
+		}
+	}
+	for _, entry := range config.GenesisMessages {
+		parts := strings.Split(entry, ":")
+		valid := len(parts) == 2
+		if valid {
+			_, weightErr := strconv.ParseUint(parts[1], 10, 64)
+			valid = weightErr == nil && multiverse.ColorFromStr(parts[0]) != multiverse.UndefinedColor
+		}
+		if !valid {
+			errors = append(errors, fmt.Sprintf("genesis message entry %q is not a valid 'color:weight' pair", entry))
+		}
+	}
+	for _, entry := range config.AdversaryManaSchedule {
+		parts := strings.Split(entry, ":")
+		valid := len(parts) == 2
+		if valid {
+			seconds, secondsErr := strconv.Atoi(parts[0])
+			percentage, percentageErr := strconv.ParseFloat(parts[1], 64)
+			valid = secondsErr == nil && percentageErr == nil && seconds >= 0 && percentage >= 0 && percentage < 100
+		}
+		if !valid {
+			errors = append(errors, fmt.Sprintf("mana schedule entry %q is not a valid 'second:percent' checkpoint", entry))
+		}
+	}
+	for _, entry := range config.AdversaryAbortConditions {
+		if entry == "-" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		valid := len(parts) == 2
+		if valid {
+			switch parts[0] {
+			case "weight":
+				percentage, err := strconv.ParseFloat(parts[1], 64)
+				valid = err == nil && percentage > 0 && percentage <= 100
+			case "time":
+				seconds, err := strconv.Atoi(parts[1])
+				valid = err == nil && seconds > 0
+			default:
+				valid = false
+			}
+		}
+		if !valid {
+			errors = append(errors, fmt.Sprintf("adversary abort condition %q is not 'weight:percent', 'time:seconds' or '-'", entry))
+		}
+	}
+	for _, activationTime := range config.AdversaryActivationTimes {
+		if activationTime < 0 {
+			errors = append(errors, fmt.Sprintf("adversary activation times must not be negative, got %d", activationTime))
+		}
+	}
+	if adversaryManaSum > 100 {
+		errors = append(errors, fmt.Sprintf("adversary mana percentages sum to %.1f%%, exceeding 100%%", adversaryManaSum))
+	}
+	if config.SybilCount > 0 {
+		if config.SybilMana < 0 || config.SybilMana >= 100 {
+			errors = append(errors, fmt.Sprintf("sybilMana must be within [0, 100), got %f", config.SybilMana))
+		}
+		if config.SybilStart <= 0 {
+			errors = append(errors, fmt.Sprintf("sybilStart must be positive, got %d", config.SybilStart))
+		}
+	}
+
+	adversaryNodesSum := config.SybilCount
+	for _, nodeCount := range config.AdversaryNodeCounts {
+		adversaryNodesSum += nodeCount
+	}
+	if adversaryNodesSum >= config.NodesCount {
+		errors = append(errors, fmt.Sprintf("adversary groups claim %d nodes, leaving no honest nodes out of %d", adversaryNodesSum, config.NodesCount))
+	}
+
+	return
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,109 @@
+package simulation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/multivers-simulation/clock"
+	"github.com/iotaledger/multivers-simulation/config"
+	"github.com/iotaledger/multivers-simulation/multiverse"
+	"github.com/iotaledger/multivers-simulation/network"
+)
+
+// region Dynamic weights //////////////////////////////////////////////////////////////////////////////
+
+// epochConfirmedCounts holds how many messages of every issuer were confirmed in the running epoch,
+// in the view of the first monitored AW peer; the decayed mana is redistributed along these counts at
+// the epoch boundary.
+var (
+	epochConfirmedCounts = make(map[network.PeerID]int64)
+	epochConfirmedMutex  sync.Mutex
+)
+
+// resetWeightEvolution clears the confirmation counts of a previous run in the same process.
+func resetWeightEvolution() {
+	epochConfirmedMutex.Lock()
+	defer epochConfirmedMutex.Unlock()
+	epochConfirmedCounts = make(map[network.PeerID]int64)
+}
+
+// startWeightEvolutionWorker evolves the consensus weights once per EpochDuration of simulated time:
+// every node's weight decays by ManaDecayPerEpoch and the decayed amount is redistributed to the
+// issuers of the messages confirmed during the epoch, so active issuers re-earn what idle ones bleed
+// and the total weight stays constant. The new weights are then sealed as the epoch-consistent view
+// the opinion managers read, the recorded votes are re-weighed under them and the issuance bands are
+// retargeted. Scheduled weight changes of the adversary groups become visible to consensus at the
+// same boundaries.
+func startWeightEvolutionWorker(ctx context.Context, testNetwork *network.Network) {
+	interval := time.Duration(config.EpochDuration) * time.Second
+
+	monitoredPeer := testNetwork.Peer(config.MonitoredAWPeers[0])
+	monitoredPeer.Node.(multiverse.NodeInterface).Tangle().ApprovalManager.Events.MessageConfirmed.Attach(
+		events.NewClosure(func(message *multiverse.Message, messageMetadata *multiverse.MessageMetadata, weight uint64, messageIDCounter int64) {
+			epochConfirmedMutex.Lock()
+			defer epochConfirmedMutex.Unlock()
+			epochConfirmedCounts[message.Issuer]++
+		}))
+
+	evolve := func() {
+		epochConfirmedMutex.Lock()
+		confirmedCounts := epochConfirmedCounts
+		epochConfirmedCounts = make(map[network.PeerID]int64)
+		epochConfirmedMutex.Unlock()
+
+		totalConfirmed := int64(0)
+		for _, count := range confirmedCounts {
+			totalConfirmed += count
+		}
+
+		// without any confirmations there is nobody to re-earn the decayed mana, so the weights only
+		// get resealed and the scheduled changes of the epoch take effect
+		if config.ManaDecayPerEpoch > 0 && totalConfirmed > 0 {
+			decayedPool := 0.0
+			for _, peer := range testNetwork.Peers {
+				decayed := float64(testNetwork.WeightDistribution.Weight(peer.ID)) * config.ManaDecayPerEpoch
+				decayedPool += decayed
+			}
+			for _, peer := range testNetwork.Peers {
+				remaining := float64(testNetwork.WeightDistribution.Weight(peer.ID)) * (1 - config.ManaDecayPerEpoch)
+				earned := decayedPool * float64(confirmedCounts[peer.ID]) / float64(totalConfirmed)
+				testNetwork.WeightDistribution.SetWeight(peer.ID, uint64(remaining+earned))
+			}
+		}
+
+		testNetwork.WeightDistribution.SealEpoch()
+		refreshIssuanceBands(testNetwork)
+		for _, peer := range testNetwork.Peers {
+			peer.Node.(multiverse.NodeInterface).Tangle().OpinionManager.ApplyEpochWeights()
+		}
+	}
+
+	if config.DiscreteEvent {
+		var scheduleEvolution func()
+		scheduleEvolution = func() {
+			clock.Virtual.ExecuteAfter(interval, func() {
+				evolve()
+				scheduleEvolution()
+			})
+		}
+		scheduleEvolution()
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(config.SlowdownFactor) * interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				evolve()
+			}
+		}
+	}()
+}
+
+// endregion ///////////////////////////////////////////////////////////////////////////////////////////